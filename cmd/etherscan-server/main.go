@@ -0,0 +1,58 @@
+// Command etherscan-server 提供兼容 Etherscan API 的 HTTP 服务，
+// 将 ?module=...&action=... 形式的请求转发到 config.yaml 中配置的以太坊节点。
+//
+// 用法：
+//
+//	etherscan-server -config config.yaml -addr :8080
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/justinwongcn/etherscan/internal/api"
+	"github.com/justinwongcn/etherscan/internal/config"
+)
+
+func main() {
+	configPath := flag.String("config", config.DefaultPath, "配置文件路径")
+	addr := flag.String("addr", ":8080", "HTTP 监听地址")
+	timeout := flag.Duration("timeout", 30*time.Second, "单个请求的超时时间")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, _, err := config.NewClient(ctx, *configPath)
+	if err != nil {
+		log.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           api.NewServer(client, *timeout),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[WARN] shutdown: %v", err)
+		}
+	}()
+
+	log.Printf("[INFO] listening on %s", *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("server error: %v", err)
+	}
+}
@@ -0,0 +1,566 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// commands 所有子命令，按名称索引
+var commands = map[string]*command{
+	"balance":      {name: "balance", args: "<地址>...", summary: "查询一个或多个地址的余额", setup: balanceCmd},
+	"block":        {name: "block", args: "[区块号|标签|区块哈希]", summary: "查询区块信息", setup: blockCmd},
+	"tx":           {name: "tx", args: "<交易哈希>", summary: "查询交易信息", setup: txCmd},
+	"receipt":      {name: "receipt", args: "<交易哈希>", summary: "查询交易收据", setup: receiptCmd},
+	"logs":         {name: "logs", args: "", summary: "按区块范围、合约地址和主题查询事件日志", setup: logsCmd},
+	"code":         {name: "code", args: "<地址>", summary: "查询合约字节码", setup: codeCmd},
+	"call":         {name: "call", args: "", summary: "执行只读合约调用（eth_call）", setup: callCmd},
+	"estimate-gas": {name: "estimate-gas", args: "", summary: "估算交易所需的 gas", setup: estimateGasCmd},
+	"gas-price":    {name: "gas-price", args: "", summary: "查询当前 gas 价格", setup: gasPriceCmd},
+	"send-raw":     {name: "send-raw", args: "<已签名交易>", summary: "广播已签名的交易", setup: sendRawCmd},
+}
+
+// blockFlag 注册 --block 选项
+func blockFlag(fs *flag.FlagSet) *string {
+	return fs.String("block", "latest", "区块号（十进制或0x十六进制）或标签：latest、earliest、pending、safe、finalized")
+}
+
+// parseBlock 将命令行中的区块号或标签转换为节点接受的格式，十进制区块号会转换为十六进制
+func parseBlock(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch eth.Tag(value) {
+	case eth.TagLatest, eth.TagEarliest, eth.TagPending, eth.TagSafe, eth.TagFinalized:
+		return value, nil
+	}
+	if strings.HasPrefix(value, "0x") {
+		if _, err := eth.NewQuantity(value); err != nil {
+			return "", usagef("invalid block %q", value)
+		}
+		return value, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return "", usagef("invalid block %q", value)
+	}
+	return eth.QuantityFromUInt64(n).String(), nil
+}
+
+// parseAddress 校验地址参数
+func parseAddress(name, value string) (string, error) {
+	if _, err := eth.NewAddress(value); err != nil {
+		return "", usagef("invalid %s %q", name, value)
+	}
+	return value, nil
+}
+
+// parseHash 校验32字节哈希参数
+func parseHash(name, value string) (string, error) {
+	if _, err := eth.NewHash(value); err != nil {
+		return "", usagef("invalid %s %q", name, value)
+	}
+	return value, nil
+}
+
+// parseBig 解析十进制或0x十六进制的整数参数，空字符串返回 nil
+func parseBig(name, value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, ok := new(big.Int).SetString(value, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, usagef("invalid %s %q", name, value)
+	}
+	return n, nil
+}
+
+// exactArgs 检查位置参数数量
+func exactArgs(args []string, n int) error {
+	if len(args) != n {
+		return usagef("expected %d argument(s), got %d", n, len(args))
+	}
+	return nil
+}
+
+func balanceCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	block := blockFlag(fs)
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if len(args) == 0 {
+			return nil, usagef("at least one address is required")
+		}
+		for _, addr := range args {
+			if _, err := parseAddress("address", addr); err != nil {
+				return nil, err
+			}
+		}
+		tag, err := parseBlock(*block)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		balances, err := client.GetBalancesBig(ctx, args, tag, len(args))
+		if err != nil {
+			return nil, err
+		}
+
+		type balance struct {
+			Address string `json:"address"`
+			Wei     string `json:"wei"`
+			Ether   string `json:"ether"`
+		}
+		list := make([]balance, 0, len(args))
+		res := &result{columns: []string{"ADDRESS", "WEI", "ETHER"}}
+		for _, addr := range args {
+			wei := balances[addr]
+			list = append(list, balance{Address: addr, Wei: wei.String(), Ether: ethereum.FormatEther(wei)})
+			res.rows = append(res.rows, []string{addr, wei.String(), ethereum.FormatEther(wei)})
+		}
+		res.value = list
+		return res, nil
+	}
+}
+
+func blockCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	block := blockFlag(fs)
+	fullTx := fs.Bool("full-tx", false, "列出区块中的完整交易")
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if len(args) > 1 {
+			return nil, usagef("expected at most 1 argument, got %d", len(args))
+		}
+		target := *block
+		if len(args) == 1 {
+			target = args[0]
+		}
+
+		var (
+			tag    string
+			byHash bool
+			err    error
+		)
+		if len(target) == 66 && strings.HasPrefix(target, "0x") {
+			tag, err = parseHash("block hash", target)
+			byHash = true
+		} else {
+			tag, err = parseBlock(target)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var b *eth.Block
+		if byHash {
+			b, err = client.GetBlockByHash(ctx, tag, *fullTx)
+		} else {
+			b, err = client.GetBlockByNumber(ctx, tag, *fullTx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			return nil, fmt.Errorf("block %s not found", target)
+		}
+		return blockResult(b, *fullTx), nil
+	}
+}
+
+// blockResult 将区块转换为输出结果，fullTx 为 true 时以列表形式输出交易
+func blockResult(b *eth.Block, fullTx bool) *result {
+	res := &result{value: b}
+	res.field("Number", formatQuantity(b.Number))
+	if b.Hash != nil {
+		res.field("Hash", b.Hash.String())
+	}
+	res.field("Parent Hash", b.ParentHash.String())
+	res.field("Timestamp", formatTimestamp(b.Timestamp.UInt64()))
+	res.field("Miner", b.Miner.String())
+	res.field("Gas Used", fmt.Sprintf("%d / %d", b.GasUsed.UInt64(), b.GasLimit.UInt64()))
+	res.field("Base Fee", formatGasPrice(b.BaseFeePerGas))
+	res.field("Transactions", strconv.Itoa(len(b.Transactions)))
+
+	if fullTx {
+		res.columns = []string{"INDEX", "HASH", "FROM", "TO", "VALUE (ETH)"}
+		for i, tx := range b.Transactions {
+			res.rows = append(res.rows, []string{
+				strconv.Itoa(i),
+				tx.Hash.String(),
+				tx.From.String(),
+				formatAddress(tx.To),
+				ethereum.FormatEther(tx.Value.Big()),
+			})
+		}
+	}
+	return res
+}
+
+func txCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if err := exactArgs(args, 1); err != nil {
+			return nil, err
+		}
+		hash, err := parseHash("transaction hash", args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := client.GetTransactionByHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if tx == nil {
+			return nil, fmt.Errorf("transaction %s not found", hash)
+		}
+
+		res := &result{value: tx}
+		res.field("Hash", tx.Hash.String())
+		if tx.BlockNumber == nil {
+			res.field("Block", "pending")
+		} else {
+			res.field("Block", formatQuantity(tx.BlockNumber))
+			res.field("Index", formatQuantity(tx.Index))
+		}
+		res.field("Type", formatQuantity(tx.Type))
+		res.field("From", tx.From.String())
+		res.field("To", formatAddress(tx.To))
+		res.field("Value", formatWei(tx.Value.Big()))
+		res.field("Nonce", formatQuantity(&tx.Nonce))
+		res.field("Gas Limit", formatQuantity(&tx.Gas))
+		if tx.MaxFeePerGas != nil {
+			res.field("Max Fee", formatGasPrice(tx.MaxFeePerGas))
+			res.field("Max Priority Fee", formatGasPrice(tx.MaxPriorityFeePerGas))
+		} else {
+			res.field("Gas Price", formatGasPrice(tx.GasPrice))
+		}
+		res.field("Input", tx.Input.String())
+		return res, nil
+	}
+}
+
+func receiptCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if err := exactArgs(args, 1); err != nil {
+			return nil, err
+		}
+		hash, err := parseHash("transaction hash", args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		receipt, err := client.GetTransactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if receipt == nil {
+			return nil, fmt.Errorf("receipt for %s not found (transaction pending or unknown)", hash)
+		}
+
+		status := "-"
+		if receipt.Status != nil {
+			status = "failed"
+			if receipt.Status.UInt64() == 1 {
+				status = "success"
+			}
+		}
+
+		res := &result{value: receipt}
+		res.field("Transaction Hash", receipt.TransactionHash.String())
+		res.field("Status", status)
+		res.field("Block", formatQuantity(&receipt.BlockNumber))
+		res.field("From", receipt.From.String())
+		res.field("To", formatAddress(receipt.To))
+		res.field("Contract Address", formatAddress(receipt.ContractAddress))
+		res.field("Gas Used", formatQuantity(&receipt.GasUsed))
+		res.field("Effective Gas Price", formatGasPrice(receipt.EffectiveGasPrice))
+		res.field("Logs", strconv.Itoa(len(receipt.Logs)))
+
+		if len(receipt.Logs) > 0 {
+			res.columns = []string{"LOG INDEX", "ADDRESS", "TOPIC0"}
+			for _, l := range receipt.Logs {
+				res.rows = append(res.rows, []string{formatQuantity(l.LogIndex), l.Address.String(), firstTopic(l.Topics)})
+			}
+		}
+		return res, nil
+	}
+}
+
+// firstTopic 返回日志的第一个主题（通常是事件签名），匿名事件返回 "-"
+func firstTopic(topics []eth.Topic) string {
+	if len(topics) == 0 {
+		return "-"
+	}
+	return topics[0].String()
+}
+
+func logsCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	from := fs.String("from", "latest", "起始区块号或标签")
+	to := fs.String("to", "latest", "结束区块号或标签")
+	address := fs.String("address", "", "合约地址，多个地址用逗号分隔")
+	var topics [4]*string
+	for i := range topics {
+		topics[i] = fs.String(fmt.Sprintf("topic%d", i), "", fmt.Sprintf("第%d个主题，多个候选值用逗号分隔", i))
+	}
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if err := exactArgs(args, 0); err != nil {
+			return nil, err
+		}
+
+		filter := &eth.LogFilter{}
+		fromTag, err := parseBlock(*from)
+		if err != nil {
+			return nil, err
+		}
+		toTag, err := parseBlock(*to)
+		if err != nil {
+			return nil, err
+		}
+		filter.FromBlock = eth.MustBlockNumberOrTag(fromTag)
+		filter.ToBlock = eth.MustBlockNumberOrTag(toTag)
+
+		if *address != "" {
+			for _, addr := range strings.Split(*address, ",") {
+				if _, err := parseAddress("address", addr); err != nil {
+					return nil, err
+				}
+				filter.Address = append(filter.Address, *eth.MustAddress(addr))
+			}
+		}
+
+		// 位置 i 之前未指定的主题匹配任意值
+		last := -1
+		filterTopics := make([][]eth.Topic, len(topics))
+		for i, value := range topics {
+			if *value == "" {
+				continue
+			}
+			for _, v := range strings.Split(*value, ",") {
+				topic, err := eth.NewTopic(v)
+				if err != nil {
+					return nil, usagef("invalid topic%d %q", i, v)
+				}
+				filterTopics[i] = append(filterTopics[i], *topic)
+			}
+			last = i
+		}
+		if last >= 0 {
+			filter.Topics = filterTopics[:last+1]
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// 分段查询，大区块范围不受节点单次查询的结果数限制
+		logs, err := client.GetLogsRange(ctx, *filter, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &result{value: logs, columns: []string{"BLOCK", "TX HASH", "LOG INDEX", "ADDRESS", "TOPIC0"}}
+		for _, l := range logs {
+			txHash := "-"
+			if l.TxHash != nil {
+				txHash = l.TxHash.String()
+			}
+			res.rows = append(res.rows, []string{
+				formatQuantity(l.BlockNumber),
+				txHash,
+				formatQuantity(l.LogIndex),
+				l.Address.String(),
+				firstTopic(l.Topics),
+			})
+		}
+		return res, nil
+	}
+}
+
+func codeCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	block := blockFlag(fs)
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if err := exactArgs(args, 1); err != nil {
+			return nil, err
+		}
+		address, err := parseAddress("address", args[0])
+		if err != nil {
+			return nil, err
+		}
+		tag, err := parseBlock(*block)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		code, err := client.GetCode(ctx, address, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		size := len(strings.TrimPrefix(code, "0x")) / 2
+		res := &result{value: map[string]any{"address": address, "size": size, "code": code}}
+		res.field("Address", address)
+		res.field("Size", fmt.Sprintf("%d bytes", size))
+		res.field("Code", code)
+		return res, nil
+	}
+}
+
+// callMsgFlags 注册 call 和 estimate-gas 共用的选项，返回构造 CallMsg 的函数
+func callMsgFlags(fs *flag.FlagSet) func() (ethereum.CallMsg, error) {
+	from := fs.String("from", "", "调用者地址")
+	to := fs.String("to", "", "合约地址")
+	data := fs.String("data", "", "调用数据（0x十六进制）")
+	value := fs.String("value", "", "转账金额（wei，十进制或0x十六进制）")
+	gas := fs.Uint64("gas", 0, "gas 上限")
+	gasPrice := fs.String("gas-price", "", "gas 价格（wei，十进制或0x十六进制）")
+	return func() (ethereum.CallMsg, error) {
+		msg := ethereum.CallMsg{From: *from, To: *to, Data: *data, Gas: *gas}
+		if msg.From != "" {
+			if _, err := parseAddress("--from", msg.From); err != nil {
+				return msg, err
+			}
+		}
+		if msg.To != "" {
+			if _, err := parseAddress("--to", msg.To); err != nil {
+				return msg, err
+			}
+		}
+		var err error
+		if msg.Value, err = parseBig("--value", *value); err != nil {
+			return msg, err
+		}
+		if msg.GasPrice, err = parseBig("--gas-price", *gasPrice); err != nil {
+			return msg, err
+		}
+		return msg, nil
+	}
+}
+
+func callCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	block := blockFlag(fs)
+	callMsg := callMsgFlags(fs)
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if err := exactArgs(args, 0); err != nil {
+			return nil, err
+		}
+		msg, err := callMsg()
+		if err != nil {
+			return nil, err
+		}
+		if msg.To == "" {
+			return nil, usagef("--to is required")
+		}
+		tag, err := parseBlock(*block)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		output, err := client.CallWithMsg(ctx, msg, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &result{value: map[string]string{"result": output}}
+		res.field("Result", output)
+		return res, nil
+	}
+}
+
+func estimateGasCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	callMsg := callMsgFlags(fs)
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if err := exactArgs(args, 0); err != nil {
+			return nil, err
+		}
+		msg, err := callMsg()
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		gas, err := client.EstimateGasWithMsg(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &result{value: map[string]uint64{"gas": gas}}
+		res.field("Gas", strconv.FormatUint(gas, 10))
+		return res, nil
+	}
+}
+
+func gasPriceCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if err := exactArgs(args, 0); err != nil {
+			return nil, err
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		price, err := client.GasPriceBig(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		gwei := ethereum.FormatGwei(price)
+		res := &result{value: map[string]string{"wei": price.String(), "gwei": gwei}}
+		res.field("Wei", price.String())
+		res.field("Gwei", gwei)
+		return res, nil
+	}
+}
+
+func sendRawCmd(fs *flag.FlagSet) func(context.Context, *app, []string) (*result, error) {
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		if err := exactArgs(args, 1); err != nil {
+			return nil, err
+		}
+		if _, err := eth.NewData(args[0]); err != nil {
+			return nil, usagef("invalid signed transaction: must be 0x-prefixed hex")
+		}
+
+		client, err := a.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := client.SendRawTransaction(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		res := &result{value: map[string]string{"hash": hash}}
+		res.field("Transaction Hash", hash)
+		return res, nil
+	}
+}
@@ -0,0 +1,203 @@
+// Command etherscan 是查询链上状态的命令行工具，通过 config.yaml 中配置的节点发送请求。
+//
+// 用法：
+//
+//	etherscan [-config config.yaml] [-output table|json] <命令> [参数]
+//
+// 示例：
+//
+//	etherscan balance 0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae
+//	etherscan block --block 17000000 --full-tx
+//	etherscan -output json receipt 0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/justinwongcn/etherscan/internal/config"
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// usageError 命令行参数错误，此时会输出命令的用法
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// options 全局选项，既可以写在命令之前，也可以写在命令之后
+type options struct {
+	configPath string
+	output     string
+	timeout    time.Duration
+}
+
+// register 将全局选项注册到 FlagSet，默认值取当前值，避免覆盖已解析的选项
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "config", o.configPath, "配置文件路径")
+	fs.StringVar(&o.output, "output", o.output, "输出格式：table 或 json")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "请求超时时间")
+}
+
+// app 一次命令执行的上下文，节点连接在命令需要时才建立
+type app struct {
+	opts   options
+	client *ethereum.Client
+}
+
+// connect 根据配置文件创建客户端，参数校验通过后才调用，避免参数错误时也要连接节点
+func (a *app) connect(ctx context.Context) (*ethereum.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+	client, _, err := config.NewClient(ctx, a.opts.configPath)
+	if err != nil {
+		return nil, err
+	}
+	a.client = client
+	return client, nil
+}
+
+// command 一个子命令
+type command struct {
+	name    string
+	args    string // 位置参数说明
+	summary string
+	// setup 在 FlagSet 上注册命令的选项，返回执行函数
+	setup func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) (*result, error)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run 解析参数并执行命令，返回进程退出码：0 成功，1 执行失败，2 参数错误
+func run(ctx context.Context, argv []string, stdout, stderr io.Writer) int {
+	a := &app{opts: options{
+		configPath: config.DefaultPath,
+		output:     "table",
+		timeout:    30 * time.Second,
+	}}
+
+	fs := flag.NewFlagSet("etherscan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { usage(stderr) }
+	a.opts.register(fs)
+	if err := fs.Parse(argv); err != nil {
+		return exitCode(err)
+	}
+	if fs.NArg() == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "etherscan: unknown command %q\n\n", name)
+		usage(stderr)
+		return 2
+	}
+
+	cmdFs := flag.NewFlagSet(name, flag.ContinueOnError)
+	cmdFs.SetOutput(stderr)
+	cmdFs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: etherscan %s [选项] %s\n\n%s\n\n选项：\n", cmd.name, cmd.args, cmd.summary)
+		cmdFs.PrintDefaults()
+	}
+	a.opts.register(cmdFs)
+	exec := cmd.setup(cmdFs)
+	args, err := parseInterspersed(cmdFs, fs.Args()[1:])
+	if err != nil {
+		return exitCode(err)
+	}
+	if a.opts.output != "table" && a.opts.output != "json" {
+		fmt.Fprintf(stderr, "etherscan: invalid output format %q, must be table or json\n", a.opts.output)
+		return 2
+	}
+
+	if a.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.opts.timeout)
+		defer cancel()
+	}
+	defer func() {
+		if a.client != nil {
+			a.client.Close()
+		}
+	}()
+
+	res, err := exec(ctx, a, args)
+	var ue *usageError
+	if errors.As(err, &ue) {
+		fmt.Fprintf(stderr, "etherscan %s: %v\n\n", name, err)
+		cmdFs.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "etherscan %s: %v\n", name, err)
+		return 1
+	}
+
+	if err := res.write(stdout, a.opts.output); err != nil {
+		fmt.Fprintf(stderr, "etherscan %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// exitCode 返回选项解析失败时的退出码，-h 查看帮助不视为错误
+func exitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return 2
+}
+
+// parseInterspersed 解析选项，允许选项出现在位置参数之后（如 balance 0x... --block 100）
+func parseInterspersed(fs *flag.FlagSet, argv []string) ([]string, error) {
+	var args []string
+	for {
+		if err := fs.Parse(argv); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return args, nil
+		}
+		args = append(args, fs.Arg(0))
+		argv = fs.Args()[1:]
+	}
+}
+
+// usage 输出全局用法和命令列表
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: etherscan [-config %s] [-output table|json] [-timeout 30s] <命令> [参数]\n\n命令：\n", config.DefaultPath)
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(w, "  %-13s %s\n", name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\n使用 \"etherscan <命令> -h\" 查看命令的选项\n")
+}
+
+// usagef 创建参数错误
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
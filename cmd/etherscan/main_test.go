@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBlock(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "latest", want: "latest"},
+		{input: "finalized", want: "finalized"},
+		{input: "17000000", want: "0x1036640"},
+		{input: "0x10", want: "0x10"},
+		{input: "0xzz", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "newest", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseBlock(tt.input)
+		if tt.wantErr {
+			assert.Error(t, err, tt.input)
+			continue
+		}
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("balance", flag.ContinueOnError)
+	block := fs.String("block", "latest", "")
+	fullTx := fs.Bool("full-tx", false, "")
+
+	args, err := parseInterspersed(fs, []string{"0xa", "--block", "100", "0xb", "--full-tx"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0xa", "0xb"}, args)
+	assert.Equal(t, "100", *block)
+	assert.True(t, *fullTx)
+}
+
+func TestResultWrite(t *testing.T) {
+	res := &result{value: map[string]string{"wei": "1000000000"}}
+	res.field("Wei", "1000000000")
+	res.field("Gwei", "1")
+	res.columns = []string{"ADDRESS", "BALANCE"}
+	res.rows = [][]string{{"0xabc", "1"}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, res.write(&buf, "table"))
+	assert.Equal(t, "Wei:   1000000000\nGwei:  1\n\nADDRESS  BALANCE\n0xabc    1\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, res.write(&buf, "json"))
+	assert.JSONEq(t, `{"wei":"1000000000"}`, buf.String())
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		argv []string
+		code int
+		want string
+	}{
+		{name: "no command", argv: nil, code: 2, want: "Usage: etherscan"},
+		{name: "unknown command", argv: []string{"nope"}, code: 2, want: `unknown command "nope"`},
+		{name: "invalid address", argv: []string{"balance", "0x12"}, code: 2, want: `invalid address "0x12"`},
+		{name: "invalid block", argv: []string{"code", "0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae", "--block", "x"}, code: 2, want: `invalid block "x"`},
+		{name: "call without to", argv: []string{"call", "--data", "0x"}, code: 2, want: "--to is required"},
+		{name: "invalid output", argv: []string{"-output", "xml", "gas-price"}, code: 2, want: `invalid output format "xml"`},
+		{name: "help", argv: []string{"tx", "-h"}, code: 0, want: "Usage: etherscan tx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tt.argv, &stdout, &stderr)
+			assert.Equal(t, tt.code, code)
+			assert.Contains(t, stderr.String(), tt.want)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
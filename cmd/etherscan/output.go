@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// result 命令的输出
+//
+// JSON 格式输出 value；表格格式先输出 fields（键值对），再输出 columns 和 rows 组成的列表
+type result struct {
+	value   any
+	fields  [][2]string
+	columns []string
+	rows    [][]string
+}
+
+// field 添加一个键值对
+func (r *result) field(key, value string) {
+	r.fields = append(r.fields, [2]string{key, value})
+}
+
+// write 按指定格式输出结果
+//
+// Parameters:
+//   - w: io.Writer 输出目标
+//   - format: string "table" 或 "json"
+//
+// Returns:
+//   - error: 写入失败或 JSON 编码失败时返回错误
+func (r *result) write(w io.Writer, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r.value)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range r.fields {
+		fmt.Fprintf(tw, "%s:\t%s\n", f[0], f[1])
+	}
+	if len(r.columns) > 0 {
+		if len(r.fields) > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintln(tw, strings.Join(r.columns, "\t"))
+		for _, row := range r.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+	}
+	return tw.Flush()
+}
+
+// formatQuantity 将可能为空的 QUANTITY 格式化为十进制字符串
+func formatQuantity(q *eth.Quantity) string {
+	if q == nil {
+		return "-"
+	}
+	return q.Big().String()
+}
+
+// formatWei 将 wei 格式化为带 ether 换算的字符串，如 "1500000000000000000 (1.5 ETH)"
+func formatWei(wei *big.Int) string {
+	return fmt.Sprintf("%s (%s ETH)", wei.String(), ethereum.FormatEther(wei))
+}
+
+// formatGasPrice 将可能为空的 gas 价格格式化为 gwei
+func formatGasPrice(q *eth.Quantity) string {
+	if q == nil {
+		return "-"
+	}
+	return ethereum.FormatGwei(q.Big()) + " gwei"
+}
+
+// formatTimestamp 将 Unix 时间戳格式化为 UTC 时间
+func formatTimestamp(ts uint64) string {
+	return fmt.Sprintf("%d (%s)", ts, time.Unix(int64(ts), 0).UTC().Format(time.RFC3339))
+}
+
+// formatAddress 将可能为空的地址格式化为字符串
+func formatAddress(addr *eth.Address) string {
+	if addr == nil {
+		return "-"
+	}
+	return addr.String()
+}
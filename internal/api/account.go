@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// maxBalanceMulti balancemulti 单次最多查询的地址数量，与 Etherscan 一致
+const maxBalanceMulti = 20
+
+// accountHandlers 返回 account 模块的处理函数
+func (s *Server) accountHandlers() map[string]handlerFunc {
+	return map[string]handlerFunc{
+		"balance":      s.accountBalance,
+		"balancemulti": s.accountBalanceMulti,
+	}
+}
+
+// accountBalance 查询单个地址的余额（单位：wei）
+//
+// 参数：address、tag（默认 latest）
+func (s *Server) accountBalance(ctx context.Context, params url.Values) (any, error) {
+	address, err := addressParam(params, "address")
+	if err != nil {
+		return nil, err
+	}
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.backend.GetBalanceBig(ctx, address, tag)
+	if err != nil {
+		return nil, err
+	}
+	return okResponse(balance.String()), nil
+}
+
+// balanceEntry balancemulti 返回的单个地址余额
+type balanceEntry struct {
+	Account string `json:"account"`
+	Balance string `json:"balance"`
+}
+
+// accountBalanceMulti 查询多个地址的余额（单位：wei）
+//
+// 参数：address（逗号分隔，最多20个）、tag（默认 latest）
+func (s *Server) accountBalanceMulti(ctx context.Context, params url.Values) (any, error) {
+	var addresses []string
+	for _, addr := range strings.Split(params.Get("address"), ",") {
+		addr = strings.TrimSpace(addr)
+		if _, err := addressParam(url.Values{"address": {addr}}, "address"); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, addr)
+	}
+	if len(addresses) > maxBalanceMulti {
+		return nil, paramError("Error! Maximum of 20 addresses allowed")
+	}
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.backend.GetBalancesBig(ctx, addresses, tag, maxBalanceMulti)
+	if err != nil {
+		return nil, err
+	}
+
+	// 保持请求中的地址顺序
+	result := make([]balanceEntry, 0, len(addresses))
+	for _, addr := range addresses {
+		result = append(result, balanceEntry{Account: addr, Balance: balances[addr].String()})
+	}
+	return okResponse(result), nil
+}
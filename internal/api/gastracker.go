@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// gastrackerHandlers 返回 gastracker 模块的处理函数
+func (s *Server) gastrackerHandlers() map[string]handlerFunc {
+	return map[string]handlerFunc{
+		"gasoracle": s.gastrackerGasOracle,
+	}
+}
+
+// gasOracleResult gasoracle 的返回值，字段名与 Etherscan 一致，价格单位为 gwei
+type gasOracleResult struct {
+	LastBlock       string `json:"LastBlock"`
+	SafeGasPrice    string `json:"SafeGasPrice"`
+	ProposeGasPrice string `json:"ProposeGasPrice"`
+	FastGasPrice    string `json:"FastGasPrice"`
+	SuggestBaseFee  string `json:"suggestBaseFee"`
+	GasUsedRatio    string `json:"gasUsedRatio"`
+}
+
+// gastrackerGasOracle 查询慢速、标准、快速三档 gas 价格
+//
+// 参数：无。三档价格为下一个区块的基础费用加上对应档位的优先费，gasUsedRatio 为最近区块的 gas 使用率，以逗号分隔
+func (s *Server) gastrackerGasOracle(ctx context.Context, _ url.Values) (any, error) {
+	fees, err := s.backend.SuggestFees(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	gwei := func(tip *big.Int) string {
+		return ethereum.FormatUnits(new(big.Int).Add(fees.BaseFee, tip), ethereum.GweiDecimals)
+	}
+	ratios := make([]string, len(fees.GasUsedRatio))
+	for i, r := range fees.GasUsedRatio {
+		ratios[i] = strconv.FormatFloat(r, 'f', -1, 64)
+	}
+	return okResponse(gasOracleResult{
+		LastBlock:       strconv.FormatUint(fees.BlockNumber, 10),
+		SafeGasPrice:    gwei(fees.Slow.MaxPriorityFeePerGas),
+		ProposeGasPrice: gwei(fees.Standard.MaxPriorityFeePerGas),
+		FastGasPrice:    gwei(fees.Fast.MaxPriorityFeePerGas),
+		SuggestBaseFee:  ethereum.FormatUnits(fees.BaseFee, ethereum.GweiDecimals),
+		GasUsedRatio:    strings.Join(ratios, ","),
+	}), nil
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"golang.org/x/sync/errgroup"
+)
+
+// 日志分页的限制，与 Etherscan 一致
+const (
+	maxLogsPerPage = 1000  // 单页最多返回的日志数量
+	maxLogsWindow  = 10000 // page 与 offset 的乘积上限，即最多能翻到的日志数量
+)
+
+// logsHandlers 返回 logs 模块的处理函数
+func (s *Server) logsHandlers() map[string]handlerFunc {
+	return map[string]handlerFunc{
+		"getLogs": s.logsGetLogs,
+	}
+}
+
+// logEntry Etherscan 格式的日志，数值字段均为十六进制字符串
+type logEntry struct {
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+	BlockNumber      string   `json:"blockNumber"`
+	BlockHash        string   `json:"blockHash"`
+	TimeStamp        string   `json:"timeStamp"`
+	LogIndex         string   `json:"logIndex"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex string   `json:"transactionIndex"`
+}
+
+// logsGetLogs 查询事件日志
+//
+// 参数：fromBlock、toBlock（十进制区块号或 latest）、address、topic0~topic3、
+// topicX_Y_opr（主题之间的关系，仅支持 and）、page、offset（分页，offset 最大1000）
+//
+// 区块范围通过 IterLogs 分段查询，不受节点单次查询的结果数和区块范围限制，读到请求的页为止；
+// fromBlock 和 toBlock 都未指定时查询最新区块，只指定 toBlock 时返回参数错误。
+func (s *Server) logsGetLogs(ctx context.Context, params url.Values) (any, error) {
+	filter, err := logFilterParams(params)
+	if err != nil {
+		return nil, err
+	}
+	page, err := intParam(params, "page", 1)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := intParam(params, "offset", maxLogsPerPage)
+	if err != nil {
+		return nil, err
+	}
+	if page == 0 {
+		page = 1
+	}
+	if offset == 0 || offset > maxLogsPerPage {
+		offset = maxLogsPerPage
+	}
+	if page > maxLogsWindow/offset {
+		return nil, paramError(fmt.Sprintf("Error! Result window is too large, PageNo x Offset size must be less than or equal to %d", maxLogsWindow))
+	}
+
+	if filter.FromBlock == nil {
+		if filter.ToBlock != nil {
+			return nil, paramError("Error! Missing fromBlock")
+		}
+		filter.FromBlock = eth.MustBlockNumberOrTag("latest")
+		filter.ToBlock = eth.MustBlockNumberOrTag("latest")
+	}
+
+	// 按 page 和 offset 分页，读到请求的页后停止查询
+	start, end := (page-1)*offset, page*offset
+	logs := make([]*eth.Log, 0, offset)
+	read := 0
+	for l, err := range s.backend.IterLogs(ctx, *filter, nil) {
+		if err != nil {
+			return nil, err
+		}
+		if read >= start {
+			logs = append(logs, l)
+		}
+		if read++; read == end {
+			break
+		}
+	}
+	if len(logs) == 0 {
+		return emptyResponse("No records found"), nil
+	}
+
+	timestamps, err := s.blockTimestamps(ctx, logs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]logEntry, 0, len(logs))
+	for _, l := range logs {
+		entry := logEntry{
+			Address: strings.ToLower(l.Address.String()),
+			Topics:  make([]string, 0, len(l.Topics)),
+			Data:    l.Data.String(),
+		}
+		for _, topic := range l.Topics {
+			entry.Topics = append(entry.Topics, topic.String())
+		}
+		if l.BlockNumber != nil {
+			entry.BlockNumber = l.BlockNumber.String()
+			entry.TimeStamp = timestamps[l.BlockNumber.UInt64()]
+		}
+		if l.BlockHash != nil {
+			entry.BlockHash = l.BlockHash.String()
+		}
+		if l.LogIndex != nil {
+			entry.LogIndex = l.LogIndex.String()
+		}
+		if l.TxHash != nil {
+			entry.TransactionHash = l.TxHash.String()
+		}
+		if l.TxIndex != nil {
+			entry.TransactionIndex = l.TxIndex.String()
+		}
+		result = append(result, entry)
+	}
+	return okResponse(result), nil
+}
+
+// logFilterParams 将 Etherscan 的日志查询参数转换为 eth.LogFilter
+func logFilterParams(params url.Values) (*eth.LogFilter, error) {
+	filter := &eth.LogFilter{}
+
+	var err error
+	if filter.FromBlock, err = blockParam(params, "fromBlock"); err != nil {
+		return nil, err
+	}
+	if filter.ToBlock, err = blockParam(params, "toBlock"); err != nil {
+		return nil, err
+	}
+
+	if params.Get("address") != "" {
+		address, err := addressParam(params, "address")
+		if err != nil {
+			return nil, err
+		}
+		filter.Address = []eth.Address{*eth.MustAddress(address)}
+	}
+
+	// eth_getLogs 的主题过滤在不同位置之间只支持 and 关系
+	for key, values := range params {
+		if strings.HasSuffix(key, "_opr") && len(values) > 0 && !strings.EqualFold(values[0], "and") {
+			return nil, paramError(fmt.Sprintf("Error! Topic operator %q is not supported, only 'and' is allowed", values[0]))
+		}
+	}
+
+	last := -1
+	topics := make([][]eth.Topic, 4)
+	for i := range topics {
+		value := strings.TrimSpace(params.Get(fmt.Sprintf("topic%d", i)))
+		if value == "" {
+			continue
+		}
+		topic, err := eth.NewTopic(value)
+		if err != nil {
+			return nil, paramError(fmt.Sprintf("Error! Invalid topic%d format", i))
+		}
+		topics[i] = []eth.Topic{*topic}
+		last = i
+	}
+	if last >= 0 {
+		filter.Topics = topics[:last+1]
+	}
+
+	if filter.FromBlock == nil && filter.ToBlock == nil && len(filter.Address) == 0 && last < 0 {
+		return nil, paramError("Error! Missing fromBlock, toBlock, address or topic")
+	}
+	return filter, nil
+}
+
+// blockTimestamps 查询日志所在区块的时间戳，返回区块号到十六进制时间戳的映射
+func (s *Server) blockTimestamps(ctx context.Context, logs []*eth.Log) (map[uint64]string, error) {
+	blocks := make(map[uint64]bool)
+	for _, l := range logs {
+		if l.BlockNumber != nil {
+			blocks[l.BlockNumber.UInt64()] = true
+		}
+	}
+
+	timestamps := make(map[uint64]string, len(blocks))
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(8)
+	for number := range blocks {
+		g.Go(func() error {
+			block, err := s.backend.GetBlockByNumber(ctx, hexUint(number), false)
+			if err != nil {
+				return fmt.Errorf("failed to get block %d: %v", number, err)
+			}
+			mu.Lock()
+			timestamps[number] = block.Timestamp.String()
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return timestamps, nil
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// addressParam 读取并校验地址参数
+func addressParam(params url.Values, name string) (string, error) {
+	value := strings.TrimSpace(params.Get(name))
+	if _, err := eth.NewAddress(value); err != nil {
+		return "", paramError("Error! Invalid address format")
+	}
+	return value, nil
+}
+
+// hashParam 读取并校验32字节哈希参数
+func hashParam(params url.Values, name string) (string, error) {
+	value := strings.TrimSpace(params.Get(name))
+	if _, err := eth.NewHash(value); err != nil {
+		return "", paramError(fmt.Sprintf("Error! Invalid %s format", name))
+	}
+	return value, nil
+}
+
+// tagParam 读取区块标签参数，支持 "latest"、"earliest"、"pending" 或十六进制区块号，默认为 "latest"
+func tagParam(params url.Values) (string, error) {
+	tag := strings.TrimSpace(params.Get("tag"))
+	if tag == "" {
+		return "latest", nil
+	}
+	if _, err := eth.NewBlockNumberOrTag(tag); err != nil {
+		return "", paramError("Error! Invalid tag format")
+	}
+	return tag, nil
+}
+
+// hexUintParam 读取十六进制整数参数
+//
+// Parameters:
+//   - params: url.Values 请求参数
+//   - name: string 参数名
+//   - required: bool 参数缺失时是否返回错误，非必需参数缺失时返回0
+func hexUintParam(params url.Values, name string, required bool) (uint64, error) {
+	value := strings.TrimSpace(params.Get(name))
+	if value == "" {
+		if required {
+			return 0, paramError(fmt.Sprintf("Error! Missing %s", name))
+		}
+		return 0, nil
+	}
+	q, err := eth.NewQuantity(value)
+	if err != nil || !q.Big().IsUint64() {
+		return 0, paramError(fmt.Sprintf("Error! Invalid %s format", name))
+	}
+	return q.UInt64(), nil
+}
+
+// hexBigParam 读取可选的十六进制大整数参数，参数缺失时返回 nil
+func hexBigParam(params url.Values, name string) (*big.Int, error) {
+	value := strings.TrimSpace(params.Get(name))
+	if value == "" {
+		return nil, nil
+	}
+	q, err := eth.NewQuantity(value)
+	if err != nil {
+		return nil, paramError(fmt.Sprintf("Error! Invalid %s format", name))
+	}
+	return new(big.Int).Set(q.Big()), nil
+}
+
+// blockParam 读取十进制区块号参数（logs 模块使用），支持 "latest"，转换为节点接受的区块号或标签
+func blockParam(params url.Values, name string) (*eth.BlockNumberOrTag, error) {
+	value := strings.TrimSpace(params.Get(name))
+	if value == "" {
+		return nil, nil
+	}
+	if value == "latest" || value == "earliest" || value == "pending" {
+		return eth.MustBlockNumberOrTag(value), nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, paramError(fmt.Sprintf("Error! Invalid %s", name))
+	}
+	return eth.MustBlockNumberOrTag(eth.QuantityFromUInt64(n).String()), nil
+}
+
+// intParam 读取可选的十进制整数参数
+func intParam(params url.Values, name string, defaultValue int) (int, error) {
+	value := strings.TrimSpace(params.Get(name))
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, paramError(fmt.Sprintf("Error! Invalid %s", name))
+	}
+	return n, nil
+}
+
+// isNotFound 判断错误是否表示数据不存在，proxy 模块对这类错误返回 null 结果
+func isNotFound(err error) bool {
+	if errors.Is(err, ethereum.ErrNotFound) {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "not found")
+}
+
+// hexUint 将整数格式化为十六进制字符串
+func hexUint(n uint64) string {
+	return eth.QuantityFromUInt64(n).String()
+}
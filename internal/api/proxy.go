@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// proxyHandlers 返回 proxy 模块的处理函数，action 名称与 JSON-RPC 方法名一致
+func (s *Server) proxyHandlers() map[string]handlerFunc {
+	return map[string]handlerFunc{
+		"eth_blockNumber":                         s.proxyBlockNumber,
+		"eth_getBlockByNumber":                    s.proxyGetBlockByNumber,
+		"eth_getUncleByBlockNumberAndIndex":       s.proxyGetUncleByBlockNumberAndIndex,
+		"eth_getBlockTransactionCountByNumber":    s.proxyGetBlockTransactionCountByNumber,
+		"eth_getTransactionByHash":                s.proxyGetTransactionByHash,
+		"eth_getTransactionByBlockNumberAndIndex": s.proxyGetTransactionByBlockNumberAndIndex,
+		"eth_getTransactionCount":                 s.proxyGetTransactionCount,
+		"eth_sendRawTransaction":                  s.proxySendRawTransaction,
+		"eth_getTransactionReceipt":               s.proxyGetTransactionReceipt,
+		"eth_call":                                s.proxyCall,
+		"eth_getCode":                             s.proxyGetCode,
+		"eth_gasPrice":                            s.proxyGasPrice,
+		"eth_estimateGas":                         s.proxyEstimateGas,
+	}
+}
+
+// notFoundAsNull 将数据不存在的错误转换为 null 结果，与节点的 JSON-RPC 行为一致
+func notFoundAsNull(result any, err error) (any, error) {
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// proxyBlockNumber 参数：无
+func (s *Server) proxyBlockNumber(ctx context.Context, _ url.Values) (any, error) {
+	n, err := s.backend.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return hexUint(n), nil
+}
+
+// proxyGetBlockByNumber 参数：tag、boolean（是否返回完整交易）
+func (s *Server) proxyGetBlockByNumber(ctx context.Context, params url.Values) (any, error) {
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+	fullTx := strings.EqualFold(params.Get("boolean"), "true")
+	return notFoundAsNull(s.backend.GetBlockByNumber(ctx, tag, fullTx))
+}
+
+// proxyGetUncleByBlockNumberAndIndex 参数：tag、index
+func (s *Server) proxyGetUncleByBlockNumberAndIndex(ctx context.Context, params url.Values) (any, error) {
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+	index, err := hexUintParam(params, "index", true)
+	if err != nil {
+		return nil, err
+	}
+	return notFoundAsNull(s.backend.GetUncleByBlockNumberAndIndex(ctx, tag, index))
+}
+
+// proxyGetBlockTransactionCountByNumber 参数：tag
+func (s *Server) proxyGetBlockTransactionCountByNumber(ctx context.Context, params url.Values) (any, error) {
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.backend.GetBlockTransactionCountByNumber(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	return hexUint(n), nil
+}
+
+// proxyGetTransactionByHash 参数：txhash
+func (s *Server) proxyGetTransactionByHash(ctx context.Context, params url.Values) (any, error) {
+	hash, err := hashParam(params, "txhash")
+	if err != nil {
+		return nil, err
+	}
+	return notFoundAsNull(s.backend.GetTransactionByHash(ctx, hash))
+}
+
+// proxyGetTransactionByBlockNumberAndIndex 参数：tag、index
+func (s *Server) proxyGetTransactionByBlockNumberAndIndex(ctx context.Context, params url.Values) (any, error) {
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+	index, err := hexUintParam(params, "index", true)
+	if err != nil {
+		return nil, err
+	}
+	return notFoundAsNull(s.backend.GetTransactionByBlockNumberAndIndex(ctx, tag, index))
+}
+
+// proxyGetTransactionCount 参数：address、tag
+func (s *Server) proxyGetTransactionCount(ctx context.Context, params url.Values) (any, error) {
+	address, err := addressParam(params, "address")
+	if err != nil {
+		return nil, err
+	}
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.backend.GetTransactionCount(ctx, address, tag)
+	if err != nil {
+		return nil, err
+	}
+	return hexUint(n), nil
+}
+
+// proxySendRawTransaction 参数：hex（已签名的交易数据）
+func (s *Server) proxySendRawTransaction(ctx context.Context, params url.Values) (any, error) {
+	data := strings.TrimSpace(params.Get("hex"))
+	if !strings.HasPrefix(data, "0x") {
+		return nil, paramError("Error! Invalid hex format")
+	}
+	return s.backend.SendRawTransaction(ctx, data)
+}
+
+// proxyGetTransactionReceipt 参数：txhash
+func (s *Server) proxyGetTransactionReceipt(ctx context.Context, params url.Values) (any, error) {
+	hash, err := hashParam(params, "txhash")
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.backend.GetTransactionReceipt(ctx, hash)
+	if receipt == nil && err == nil {
+		return nil, nil
+	}
+	return notFoundAsNull(receipt, err)
+}
+
+// callMsgParams 读取 eth_call 和 eth_estimateGas 共用的参数：to、data、value、gas、gasPrice
+func callMsgParams(params url.Values, requireTo bool) (ethereum.CallMsg, error) {
+	var msg ethereum.CallMsg
+	if requireTo || params.Get("to") != "" {
+		to, err := addressParam(params, "to")
+		if err != nil {
+			return msg, err
+		}
+		msg.To = to
+	}
+	if from := params.Get("from"); from != "" {
+		addr, err := addressParam(params, "from")
+		if err != nil {
+			return msg, err
+		}
+		msg.From = addr
+	}
+	msg.Data = strings.TrimSpace(params.Get("data"))
+
+	var err error
+	if msg.Gas, err = hexUintParam(params, "gas", false); err != nil {
+		return msg, err
+	}
+	if msg.GasPrice, err = hexBigParam(params, "gasPrice"); err != nil {
+		return msg, err
+	}
+	if msg.Value, err = hexBigParam(params, "value"); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// proxyCall 参数：to、data、tag
+func (s *Server) proxyCall(ctx context.Context, params url.Values) (any, error) {
+	msg, err := callMsgParams(params, true)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+	return s.backend.CallWithMsg(ctx, msg, tag)
+}
+
+// proxyGetCode 参数：address、tag
+func (s *Server) proxyGetCode(ctx context.Context, params url.Values) (any, error) {
+	address, err := addressParam(params, "address")
+	if err != nil {
+		return nil, err
+	}
+	tag, err := tagParam(params)
+	if err != nil {
+		return nil, err
+	}
+	return s.backend.GetCode(ctx, address, tag)
+}
+
+// proxyGasPrice 参数：无
+func (s *Server) proxyGasPrice(ctx context.Context, _ url.Values) (any, error) {
+	price, err := s.backend.GasPriceBig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return "0x" + price.Text(16), nil
+}
+
+// proxyEstimateGas 参数：to、data、value、gas、gasPrice
+func (s *Server) proxyEstimateGas(ctx context.Context, params url.Values) (any, error) {
+	msg, err := callMsgParams(params, false)
+	if err != nil {
+		return nil, err
+	}
+	gas, err := s.backend.EstimateGasWithMsg(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return hexUint(gas), nil
+}
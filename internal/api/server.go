@@ -0,0 +1,241 @@
+// Package api 提供兼容 Etherscan API 的 HTTP 服务，包括：
+//   - Etherscan 风格的查询参数路由（?module=account&action=balance）
+//   - Etherscan 的响应格式（status、message、result）
+//   - proxy 模块的 JSON-RPC 响应格式（jsonrpc、id、result/error）
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// Backend 服务所依赖的以太坊客户端接口，由 *ethereum.Client 实现
+type Backend interface {
+	GetBalanceBig(ctx context.Context, address string, numberOrTag string) (*big.Int, error)
+	GetBalancesBig(ctx context.Context, addresses []string, numberOrTag string, maxAddresses ...int) (map[string]*big.Int, error)
+	GetCode(ctx context.Context, address string, numberOrTag string) (string, error)
+	GetTransactionCount(ctx context.Context, address string, numberOrTag string) (uint64, error)
+	GetLatestBlockNumber(ctx context.Context) (uint64, error)
+	GetBlockByNumber(ctx context.Context, numberOrTag string, fullTx bool) (*eth.Block, error)
+	GetBlockTransactionCountByNumber(ctx context.Context, numberOrTag string) (uint64, error)
+	GetUncleByBlockNumberAndIndex(ctx context.Context, numberOrTag string, index uint64) (*eth.Block, error)
+	GetTransactionByHash(ctx context.Context, txHash string) (*eth.Transaction, error)
+	GetTransactionByBlockNumberAndIndex(ctx context.Context, numberOrTag string, index uint64) (*eth.Transaction, error)
+	GetTransactionReceipt(ctx context.Context, txHash string) (*eth.TransactionReceipt, error)
+	SendRawTransaction(ctx context.Context, signedTxData string) (string, error)
+	CallWithMsg(ctx context.Context, msg ethereum.CallMsg, numberOrTag string) (string, error)
+	EstimateGasWithMsg(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	GasPriceBig(ctx context.Context) (*big.Int, error)
+	SuggestFees(ctx context.Context, opts *ethereum.FeeOracleOptions) (*ethereum.FeeSuggestion, error)
+	IterLogs(ctx context.Context, filter eth.LogFilter, opts *ethereum.LogRangeOptions) iter.Seq2[*eth.Log, error]
+	Endpoints() []ethereum.EndpointStatus
+}
+
+// handlerFunc 处理一个 module/action 请求，返回 result 字段的内容
+type handlerFunc func(ctx context.Context, params url.Values) (any, error)
+
+// Server 兼容 Etherscan API 的 HTTP 服务
+type Server struct {
+	backend        Backend
+	requestTimeout time.Duration
+	modules        map[string]map[string]handlerFunc
+	mux            *http.ServeMux
+}
+
+// NewServer 创建 HTTP 服务
+//
+// Parameters:
+//   - backend: Backend 以太坊客户端
+//   - requestTimeout: time.Duration 单个请求的超时时间，为0时不限制
+//
+// Returns:
+//   - *Server: 实现了 http.Handler 的服务，提供 /api 和 /health 两个路径
+func NewServer(backend Backend, requestTimeout time.Duration) *Server {
+	s := &Server{
+		backend:        backend,
+		requestTimeout: requestTimeout,
+	}
+	s.modules = map[string]map[string]handlerFunc{
+		"account":     s.accountHandlers(),
+		"proxy":       s.proxyHandlers(),
+		"logs":        s.logsHandlers(),
+		"transaction": s.transactionHandlers(),
+		"gastracker":  s.gastrackerHandlers(),
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/api", s.handleAPI)
+	s.mux.HandleFunc("/health", s.handleHealth)
+	return s
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleAPI 按 module 和 action 参数分发请求，支持 GET 查询参数和 POST 表单
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, errorResponse("Error! Invalid request parameters"))
+		return
+	}
+	params := r.Form
+
+	module := strings.ToLower(params.Get("module"))
+	actions, ok := s.modules[module]
+	if !ok {
+		writeJSON(w, errorResponse("Error! Missing Or invalid Module name"))
+		return
+	}
+	action := params.Get("action")
+	handler, ok := actions[action]
+	if !ok {
+		writeJSON(w, errorResponse("Error! Missing Or invalid Action name"))
+		return
+	}
+
+	ctx := r.Context()
+	if s.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.requestTimeout)
+		defer cancel()
+	}
+
+	result, err := handler(ctx, params)
+	if module == "proxy" {
+		writeJSON(w, rpcResponse(params.Get("id"), result, err))
+		return
+	}
+	if err != nil {
+		log.Printf("[WARN] %s/%s failed: %v", module, action, err)
+		writeJSON(w, errorResponse(errorMessage(err)))
+		return
+	}
+	writeJSON(w, result)
+}
+
+// handleHealth 返回各节点端点的健康状态，所有端点都不健康时返回 503
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	endpoints := s.backend.Endpoints()
+	healthy := false
+	for _, ep := range endpoints {
+		if ep.Healthy {
+			healthy = true
+			break
+		}
+	}
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	writeJSON(w, map[string]any{"healthy": healthy, "endpoints": endpoints})
+}
+
+// response Etherscan 的标准响应格式
+type response struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  any    `json:"result"`
+}
+
+// okResponse 返回成功响应
+func okResponse(result any) response {
+	return response{Status: "1", Message: "OK", Result: result}
+}
+
+// errorResponse 返回失败响应，与 Etherscan 一致，错误信息放在 result 字段
+func errorResponse(message string) response {
+	return response{Status: "0", Message: "NOTOK", Result: message}
+}
+
+// emptyResponse 返回无记录的响应
+func emptyResponse(message string) response {
+	return response{Status: "0", Message: message, Result: []any{}}
+}
+
+// paramError 参数错误，错误信息直接返回给调用者
+type paramError string
+
+func (e paramError) Error() string {
+	return string(e)
+}
+
+// errorMessage 将处理过程中的错误转换为返回给调用者的信息
+func errorMessage(err error) string {
+	if pe, ok := err.(paramError); ok {
+		return string(pe)
+	}
+	return "Error! " + err.Error()
+}
+
+// rpcError JSON-RPC 错误对象
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// rpcEnvelope proxy 模块使用的 JSON-RPC 响应格式
+type rpcEnvelope struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcResponse 构造 proxy 模块的响应，result 为 nil 时输出 null
+func rpcResponse(id string, result any, err error) any {
+	rawID := json.RawMessage("1")
+	if id != "" {
+		if b, e := json.Marshal(id); e == nil {
+			rawID = b
+		}
+	}
+
+	if err != nil {
+		// 节点返回的 JSON-RPC 错误原样透传，保留错误码和回滚数据
+		var nodeErr *ethereum.RPCError
+		if errors.As(err, &nodeErr) {
+			return rpcEnvelope{JSONRPC: "2.0", ID: rawID, Error: &rpcError{Code: nodeErr.Code, Message: nodeErr.Message, Data: nodeErr.Data}}
+		}
+		code := -32000
+		if _, ok := err.(paramError); ok {
+			code = -32602
+		}
+		return rpcEnvelope{JSONRPC: "2.0", ID: rawID, Error: &rpcError{Code: code, Message: err.Error()}}
+	}
+	if result == nil {
+		// omitempty 会省略 nil 结果，这里显式输出 null
+		return struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Result  any             `json:"result"`
+		}{"2.0", rawID, nil}
+	}
+	return rpcEnvelope{JSONRPC: "2.0", ID: rawID, Result: result}
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[WARN] failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// fakeBackend 模拟的以太坊客户端，未覆盖的方法调用时会 panic
+type fakeBackend struct {
+	Backend
+	balances map[string]*big.Int
+	logs     []*eth.Log
+	filter   *eth.LogFilter
+	read     int // IterLogs 产出的日志数
+}
+
+func (f *fakeBackend) GetBalanceBig(ctx context.Context, address string, numberOrTag string) (*big.Int, error) {
+	return f.balances[address], nil
+}
+
+func (f *fakeBackend) GetBalancesBig(ctx context.Context, addresses []string, numberOrTag string, maxAddresses ...int) (map[string]*big.Int, error) {
+	result := make(map[string]*big.Int)
+	for _, addr := range addresses {
+		result[addr] = f.balances[addr]
+	}
+	return result, nil
+}
+
+func (f *fakeBackend) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	return 0xc36b29, nil
+}
+
+func (f *fakeBackend) GetBlockByNumber(ctx context.Context, numberOrTag string, fullTx bool) (*eth.Block, error) {
+	return &eth.Block{Number: eth.MustQuantity(numberOrTag), Timestamp: *eth.MustQuantity("0x5f5e100")}, nil
+}
+
+func (f *fakeBackend) IterLogs(ctx context.Context, filter eth.LogFilter, opts *ethereum.LogRangeOptions) iter.Seq2[*eth.Log, error] {
+	f.filter, f.read = &filter, 0
+	return func(yield func(*eth.Log, error) bool) {
+		for _, l := range f.logs {
+			f.read++
+			if !yield(l, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (f *fakeBackend) CallWithMsg(ctx context.Context, msg ethereum.CallMsg, numberOrTag string) (string, error) {
+	return "", &ethereum.RPCError{Code: 3, Message: "execution reverted", Data: json.RawMessage(`"0x08c379a0"`)}
+}
+
+func (f *fakeBackend) SuggestFees(ctx context.Context, opts *ethereum.FeeOracleOptions) (*ethereum.FeeSuggestion, error) {
+	baseFee := big.NewInt(12_500_000_000)
+	return &ethereum.FeeSuggestion{
+		BlockNumber:  0xc36b29,
+		BaseFee:      baseFee,
+		GasUsedRatio: []float64{0.5, 0.987},
+		Slow:         ethereum.FeeEstimate{MaxPriorityFeePerGas: big.NewInt(100_000_000)},
+		Standard:     ethereum.FeeEstimate{MaxPriorityFeePerGas: big.NewInt(1_000_000_000)},
+		Fast:         ethereum.FeeEstimate{MaxPriorityFeePerGas: big.NewInt(2_000_000_000)},
+	}, nil
+}
+
+func (f *fakeBackend) Endpoints() []ethereum.EndpointStatus {
+	return []ethereum.EndpointStatus{{URL: "wss://node", Healthy: true}}
+}
+
+const (
+	testAddr1 = "0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae"
+	testAddr2 = "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
+)
+
+func newTestServer() (*Server, *fakeBackend) {
+	whale, _ := new(big.Int).SetString("40891626854930000000000", 10)
+	backend := &fakeBackend{balances: map[string]*big.Int{
+		testAddr1: whale,
+		testAddr2: big.NewInt(0),
+	}}
+	return NewServer(backend, 0), backend
+}
+
+// get 发送请求并解码 JSON 响应
+func get(t *testing.T, s *Server, query string) map[string]any {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api?"+query, nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var out map[string]any
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	return out
+}
+
+func TestAccountBalance(t *testing.T) {
+	s, _ := newTestServer()
+
+	out := get(t, s, "module=account&action=balance&address="+testAddr1+"&tag=latest&apikey=x")
+	assert.Equal(t, map[string]any{"status": "1", "message": "OK", "result": "40891626854930000000000"}, out)
+
+	out = get(t, s, "module=account&action=balance&address=0x123")
+	assert.Equal(t, map[string]any{"status": "0", "message": "NOTOK", "result": "Error! Invalid address format"}, out)
+
+	out = get(t, s, "module=account&action=balancemulti&address="+testAddr2+","+testAddr1)
+	assert.Equal(t, []any{
+		map[string]any{"account": testAddr2, "balance": "0"},
+		map[string]any{"account": testAddr1, "balance": "40891626854930000000000"},
+	}, out["result"])
+}
+
+func TestUnknownModuleAndAction(t *testing.T) {
+	s, _ := newTestServer()
+
+	out := get(t, s, "module=unknown&action=balance")
+	assert.Equal(t, "Error! Missing Or invalid Module name", out["result"])
+
+	out = get(t, s, "module=account&action=unknown")
+	assert.Equal(t, "Error! Missing Or invalid Action name", out["result"])
+}
+
+func TestProxyEnvelope(t *testing.T) {
+	s, _ := newTestServer()
+
+	out := get(t, s, "module=proxy&action=eth_blockNumber&id=83")
+	assert.Equal(t, map[string]any{"jsonrpc": "2.0", "id": "83", "result": "0xc36b29"}, out)
+
+	out = get(t, s, "module=proxy&action=eth_getTransactionCount&address=bad")
+	assert.Equal(t, "2.0", out["jsonrpc"])
+	assert.Equal(t, map[string]any{"code": float64(-32602), "message": "Error! Invalid address format"}, out["error"])
+
+	// 节点返回的错误码和回滚数据原样透传
+	out = get(t, s, "module=proxy&action=eth_call&to="+testAddr1+"&data=0x70a08231")
+	assert.Equal(t, map[string]any{"code": float64(3), "message": "execution reverted", "data": "0x08c379a0"}, out["error"])
+}
+
+func TestLogsGetLogs(t *testing.T) {
+	s, backend := newTestServer()
+	topic := "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	backend.logs = []*eth.Log{{
+		Address:     *eth.MustAddress(testAddr1),
+		Topics:      []eth.Topic{*eth.MustTopic(topic)},
+		Data:        *eth.MustData("0x"),
+		BlockNumber: eth.MustQuantity("0x10"),
+		LogIndex:    eth.MustQuantity("0x0"),
+	}}
+
+	out := get(t, s, "module=logs&action=getLogs&fromBlock=16&toBlock=latest&address="+testAddr1+"&topic0="+topic)
+	assert.Equal(t, "1", out["status"])
+	entries := out["result"].([]any)
+	assert.Len(t, entries, 1)
+	entry := entries[0].(map[string]any)
+	assert.Equal(t, "0x10", entry["blockNumber"])
+	assert.Equal(t, "0x5f5e100", entry["timeStamp"])
+	assert.Equal(t, []any{topic}, entry["topics"])
+
+	from, _ := backend.filter.FromBlock.Quantity()
+	assert.Equal(t, uint64(16), from.UInt64())
+	assert.Len(t, backend.filter.Topics, 1)
+
+	out = get(t, s, "module=logs&action=getLogs&fromBlock=16&topic0="+topic+"&topic1="+topic+"&topic0_1_opr=or")
+	assert.Equal(t, "0", out["status"])
+
+	// 未指定区块范围时只查询最新区块，只指定 toBlock 时返回参数错误
+	get(t, s, "module=logs&action=getLogs&address="+testAddr1)
+	tag, _ := backend.filter.FromBlock.Tag()
+	assert.Equal(t, eth.TagLatest, tag)
+	tag, _ = backend.filter.ToBlock.Tag()
+	assert.Equal(t, eth.TagLatest, tag)
+	out = get(t, s, "module=logs&action=getLogs&toBlock=16&address="+testAddr1)
+	assert.Equal(t, "Error! Missing fromBlock", out["result"])
+
+	out = get(t, s, "module=logs&action=getLogs&fromBlock=16&page=2&offset=1")
+	assert.Equal(t, map[string]any{"status": "0", "message": "No records found", "result": []any{}}, out)
+
+	// 超过 Etherscan 的结果窗口时返回参数错误，page 过大时不会溢出
+	for _, query := range []string{"page=11&offset=1000", "page=10001", "page=4611686018427387904&offset=1000"} {
+		out = get(t, s, "module=logs&action=getLogs&fromBlock=16&"+query)
+		assert.Equal(t, "0", out["status"], query)
+		assert.Contains(t, out["result"], "Result window is too large", query)
+	}
+}
+
+func TestLogsGetLogsPaging(t *testing.T) {
+	s, backend := newTestServer()
+	for i := range 5 {
+		backend.logs = append(backend.logs, &eth.Log{
+			Address:     *eth.MustAddress(testAddr1),
+			Data:        *eth.MustData("0x"),
+			BlockNumber: eth.MustQuantity(hexUint(uint64(16 + i))),
+			LogIndex:    eth.MustQuantity("0x0"),
+		})
+	}
+
+	// 读到请求的页后停止查询
+	out := get(t, s, "module=logs&action=getLogs&fromBlock=16&page=2&offset=2")
+	entries := out["result"].([]any)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "0x12", entries[0].(map[string]any)["blockNumber"])
+	assert.Equal(t, "0x13", entries[1].(map[string]any)["blockNumber"])
+	assert.Equal(t, 4, backend.read)
+
+	// 最后一页不满 offset
+	out = get(t, s, "module=logs&action=getLogs&fromBlock=16&page=3&offset=2")
+	assert.Len(t, out["result"], 1)
+	assert.Equal(t, 5, backend.read)
+}
+
+func TestGasOracle(t *testing.T) {
+	s, _ := newTestServer()
+
+	out := get(t, s, "module=gastracker&action=gasoracle")
+	assert.Equal(t, map[string]any{"status": "1", "message": "OK", "result": map[string]any{
+		"LastBlock":       "12806953",
+		"SafeGasPrice":    "12.6",
+		"ProposeGasPrice": "13.5",
+		"FastGasPrice":    "14.5",
+		"suggestBaseFee":  "12.5",
+		"gasUsedRatio":    "0.5,0.987",
+	}}, out)
+}
+
+func TestHealth(t *testing.T) {
+	s, _ := newTestServer()
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"healthy":true`)
+}
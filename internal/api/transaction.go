@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// transactionHandlers 返回 transaction 模块的处理函数
+func (s *Server) transactionHandlers() map[string]handlerFunc {
+	return map[string]handlerFunc{
+		"gettxreceiptstatus": s.transactionReceiptStatus,
+	}
+}
+
+// transactionReceiptStatus 查询交易收据状态
+//
+// 参数：txhash。返回 {"status": "1"} 表示成功，"0" 表示失败，拜占庭分叉前的交易或未打包的交易为空字符串
+func (s *Server) transactionReceiptStatus(ctx context.Context, params url.Values) (any, error) {
+	hash, err := hashParam(params, "txhash")
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.backend.GetTransactionReceipt(ctx, hash)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	status := ""
+	if receipt != nil && receipt.Status != nil {
+		status = fmt.Sprintf("%d", receipt.Status.UInt64())
+	}
+	return okResponse(map[string]string{"status": status}), nil
+}
@@ -0,0 +1,382 @@
+// Package config 负责加载 config.yaml 配置文件，提供以下功能：
+//   - 解析节点端点、连接池、健康检查、重试策略和链ID配置
+//   - 使用环境变量覆盖配置文件中的值
+//   - 校验配置并给出明确的错误信息
+//   - 根据配置创建可直接使用的以太坊客户端
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// DefaultPath 默认的配置文件路径
+const DefaultPath = "config.yaml"
+
+// Config 应用配置的根节点
+type Config struct {
+	Ethereum EthereumConfig `yaml:"ethereum"`
+}
+
+// EthereumConfig 以太坊客户端配置
+type EthereumConfig struct {
+	NodeURL     string            `yaml:"node_url"`     // 单个节点URL，与 endpoints 同时配置时作为最高优先级端点
+	Endpoints   []EndpointConfig  `yaml:"endpoints"`    // 多个节点端点，用于故障转移
+	ChainID     uint64            `yaml:"chain_id"`     // 期望的链ID，为0时不校验
+	Pool        PoolConfig        `yaml:"pool"`         // 连接池配置
+	HealthCheck HealthCheckConfig `yaml:"health_check"` // 健康检查配置
+	Retry       RetryConfig       `yaml:"retry"`        // 重试策略配置
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`   // 每个端点的客户端限流配置
+}
+
+// EndpointConfig 单个节点端点配置
+type EndpointConfig struct {
+	URL       string           `yaml:"url"`        // 节点URL
+	Priority  int              `yaml:"priority"`   // 优先级，数值越小越优先
+	RateLimit *RateLimitConfig `yaml:"rate_limit"` // 端点单独的限流配置，未配置时使用 ethereum.rate_limit
+}
+
+// PoolConfig 连接池配置
+type PoolConfig struct {
+	MaxConns     int           `yaml:"max_conns"`      // 每个端点的最大并发连接数
+	MaxIdleConns int           `yaml:"max_idle_conns"` // 每个端点的最大空闲连接数
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`   // 空闲连接的超时时间
+}
+
+// HealthCheckConfig 健康检查配置
+type HealthCheckConfig struct {
+	Enabled     bool          `yaml:"enabled"`      // 是否启用连接健康检查
+	Interval    time.Duration `yaml:"interval"`     // 健康检查和不健康端点重新探测的间隔
+	MaxFailures int           `yaml:"max_failures"` // 连续失败多少次后将端点标记为不健康
+}
+
+// RetryConfig 重试策略配置
+type RetryConfig struct {
+	MaxAttempts        int           `yaml:"max_attempts"`         // 最大尝试次数（包含第一次请求），1表示不重试
+	InitialBackoff     time.Duration `yaml:"initial_backoff"`      // 第一次重试前的等待时间
+	MaxBackoff         time.Duration `yaml:"max_backoff"`          // 重试等待时间的上限
+	SendRawTransaction bool          `yaml:"send_raw_transaction"` // 是否重试发送交易，默认只重试读请求
+}
+
+// RateLimitConfig 客户端限流配置，使用令牌桶算法
+type RateLimitConfig struct {
+	RequestsPerSecond float64        `yaml:"requests_per_second"` // 每秒补充的令牌数，为0时不限流
+	Burst             int            `yaml:"burst"`               // 令牌桶容量，为0时等于 requests_per_second
+	MethodWeights     map[string]int `yaml:"method_weights"`      // 各方法消耗的令牌数，未列出的方法消耗1个
+}
+
+// rateLimit 转换为客户端的限流配置
+func (r RateLimitConfig) rateLimit() ethereum.RateLimit {
+	return ethereum.RateLimit{
+		RequestsPerSecond: r.RequestsPerSecond,
+		Burst:             r.Burst,
+		MethodWeights:     r.MethodWeights,
+	}
+}
+
+// Default 返回默认配置，未在配置文件中出现的字段使用这些默认值
+//
+// Returns:
+//   - *Config: 默认配置，不包含任何节点端点
+func Default() *Config {
+	opts := ethereum.DefaultClientOptions()
+	return &Config{
+		Ethereum: EthereumConfig{
+			Pool: PoolConfig{
+				MaxConns:     opts.MaxConns,
+				MaxIdleConns: opts.MaxIdleConns,
+				IdleTimeout:  opts.IdleTimeout,
+			},
+			HealthCheck: HealthCheckConfig{
+				Enabled:     opts.HealthCheck,
+				Interval:    opts.HealthCheckInterval,
+				MaxFailures: opts.MaxFailures,
+			},
+			Retry: RetryConfig{
+				MaxAttempts:        opts.Retry.MaxAttempts,
+				InitialBackoff:     opts.Retry.InitialBackoff,
+				MaxBackoff:         opts.Retry.MaxBackoff,
+				SendRawTransaction: opts.Retry.RetrySendRawTransaction,
+			},
+		},
+	}
+}
+
+// Load 从文件加载配置，并应用环境变量覆盖和配置校验
+//
+// Parameters:
+//   - path: string 配置文件路径，为空时使用 DefaultPath
+//
+// Returns:
+//   - *Config: 加载并校验后的配置
+//   - error: 可能的错误：
+//   - 无法读取配置文件
+//   - 配置文件格式错误
+//   - 环境变量格式错误
+//   - 配置校验失败
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Parse 解析 YAML 格式的配置并进行校验，不读取环境变量
+//
+// Parameters:
+//   - data: []byte YAML 配置内容
+//
+// Returns:
+//   - *Config: 解析并校验后的配置
+//   - error: 格式错误或校验失败时返回错误
+func Parse(data []byte) (*Config, error) {
+	cfg, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parse 在默认配置的基础上解析 YAML 内容
+func parse(data []byte) (*Config, error) {
+	cfg := Default()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate 校验配置，一次返回所有不合法的字段
+//
+// Returns:
+//   - error: 配置合法时返回 nil，否则返回包含所有问题的错误
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("config: ethereum.%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	e := c.Ethereum
+	if e.NodeURL == "" && len(e.Endpoints) == 0 {
+		fail("node_url", "either node_url or endpoints must be set")
+	}
+	if e.NodeURL != "" {
+		if err := validateURL(e.NodeURL); err != nil {
+			fail("node_url", "%v", err)
+		}
+	}
+	seen := make(map[string]bool)
+	if e.NodeURL != "" {
+		seen[e.NodeURL] = true
+	}
+	for i, ep := range e.Endpoints {
+		field := fmt.Sprintf("endpoints[%d].url", i)
+		if ep.URL == "" {
+			fail(field, "must not be empty")
+			continue
+		}
+		if err := validateURL(ep.URL); err != nil {
+			fail(field, "%v", err)
+		}
+		if seen[ep.URL] {
+			fail(field, "duplicate endpoint %s", ep.URL)
+		}
+		seen[ep.URL] = true
+		if ep.RateLimit != nil {
+			validateRateLimit(fmt.Sprintf("endpoints[%d].rate_limit", i), *ep.RateLimit, fail)
+		}
+	}
+
+	if e.Pool.MaxConns <= 0 {
+		fail("pool.max_conns", "must be positive, got %d", e.Pool.MaxConns)
+	}
+	if e.Pool.MaxIdleConns < 0 {
+		fail("pool.max_idle_conns", "must not be negative, got %d", e.Pool.MaxIdleConns)
+	}
+	if e.Pool.MaxConns > 0 && e.Pool.MaxIdleConns > e.Pool.MaxConns {
+		fail("pool.max_idle_conns", "must not exceed pool.max_conns (%d), got %d", e.Pool.MaxConns, e.Pool.MaxIdleConns)
+	}
+	if e.Pool.IdleTimeout <= 0 {
+		fail("pool.idle_timeout", "must be positive, got %s", e.Pool.IdleTimeout)
+	}
+	if e.HealthCheck.Interval <= 0 {
+		fail("health_check.interval", "must be positive, got %s", e.HealthCheck.Interval)
+	}
+	if e.HealthCheck.MaxFailures <= 0 {
+		fail("health_check.max_failures", "must be positive, got %d", e.HealthCheck.MaxFailures)
+	}
+	if e.Retry.MaxAttempts <= 0 {
+		fail("retry.max_attempts", "must be positive, got %d", e.Retry.MaxAttempts)
+	}
+	if e.Retry.InitialBackoff < 0 {
+		fail("retry.initial_backoff", "must not be negative, got %s", e.Retry.InitialBackoff)
+	}
+	if e.Retry.MaxBackoff < e.Retry.InitialBackoff {
+		fail("retry.max_backoff", "must not be less than retry.initial_backoff (%s), got %s", e.Retry.InitialBackoff, e.Retry.MaxBackoff)
+	}
+	validateRateLimit("rate_limit", e.RateLimit, fail)
+
+	return errors.Join(errs...)
+}
+
+// validateRateLimit 校验限流配置，field 为配置项的路径前缀
+func validateRateLimit(field string, r RateLimitConfig, fail func(field, format string, args ...any)) {
+	if r.RequestsPerSecond < 0 {
+		fail(field+".requests_per_second", "must not be negative, got %g", r.RequestsPerSecond)
+	}
+	if r.Burst < 0 {
+		fail(field+".burst", "must not be negative, got %d", r.Burst)
+	}
+	methods := make([]string, 0, len(r.MethodWeights))
+	for method := range r.MethodWeights {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		if w := r.MethodWeights[method]; w < 0 {
+			fail(field+".method_weights."+method, "must not be negative, got %d", w)
+		}
+	}
+}
+
+// validateURL 校验节点URL的格式，支持 http(s)、ws(s) 和 IPC 路径
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+		if u.Host == "" {
+			return fmt.Errorf("invalid url %q: missing host", raw)
+		}
+	case "":
+		// IPC 路径
+		if u.Path == "" {
+			return fmt.Errorf("invalid url %q: missing ipc path", raw)
+		}
+	default:
+		return fmt.Errorf("unsupported url scheme %q in %q", u.Scheme, raw)
+	}
+	return nil
+}
+
+// EndpointList 返回客户端使用的端点列表
+//
+// node_url 作为最高优先级端点排在最前面，其余端点保持配置中的优先级。
+//
+// Returns:
+//   - []ethereum.Endpoint: 端点列表
+func (c *Config) EndpointList() []ethereum.Endpoint {
+	e := c.Ethereum
+	endpoints := make([]ethereum.Endpoint, 0, len(e.Endpoints)+1)
+	if e.NodeURL != "" {
+		priority := 0
+		for _, ep := range e.Endpoints {
+			if ep.Priority < priority {
+				priority = ep.Priority
+			}
+		}
+		endpoints = append(endpoints, ethereum.Endpoint{URL: e.NodeURL, Priority: priority})
+	}
+	for _, ep := range e.Endpoints {
+		endpoint := ethereum.Endpoint{URL: ep.URL, Priority: ep.Priority}
+		if ep.RateLimit != nil {
+			limit := ep.RateLimit.rateLimit()
+			endpoint.RateLimit = &limit
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// ClientOptions 将配置转换为客户端配置选项
+//
+// Returns:
+//   - *ethereum.ClientOptions: 客户端配置选项
+func (c *Config) ClientOptions() *ethereum.ClientOptions {
+	e := c.Ethereum
+	return &ethereum.ClientOptions{
+		MaxConns:            e.Pool.MaxConns,
+		IdleTimeout:         e.Pool.IdleTimeout,
+		HealthCheck:         e.HealthCheck.Enabled,
+		MaxIdleConns:        e.Pool.MaxIdleConns,
+		HealthCheckInterval: e.HealthCheck.Interval,
+		MaxFailures:         e.HealthCheck.MaxFailures,
+		ChainID:             e.ChainID,
+		Retry: ethereum.RetryPolicy{
+			MaxAttempts:             e.Retry.MaxAttempts,
+			InitialBackoff:          e.Retry.InitialBackoff,
+			MaxBackoff:              e.Retry.MaxBackoff,
+			RetrySendRawTransaction: e.Retry.SendRawTransaction,
+		},
+		RateLimit: e.RateLimit.rateLimit(),
+	}
+}
+
+// NewClient 根据配置创建以太坊客户端
+//
+// Parameters:
+//   - ctx: context.Context 用于控制客户端生命周期的上下文
+//
+// Returns:
+//   - *ethereum.Client: 初始化后的客户端
+//   - error: 配置校验失败或无法连接任何端点时返回错误
+func (c *Config) NewClient(ctx context.Context) (*ethereum.Client, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return ethereum.NewMultiClient(ctx, c.EndpointList(), c.ClientOptions())
+}
+
+// NewClient 从配置文件加载配置并创建以太坊客户端
+//
+// Parameters:
+//   - ctx: context.Context 用于控制客户端生命周期的上下文
+//   - path: string 配置文件路径，为空时使用 DefaultPath
+//
+// Returns:
+//   - *ethereum.Client: 初始化后的客户端
+//   - *Config: 加载的配置
+//   - error: 加载配置或创建客户端失败时返回错误
+func NewClient(ctx context.Context, path string) (*ethereum.Client, *Config, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := cfg.NewClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, cfg, nil
+}
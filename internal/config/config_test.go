@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(`
+ethereum:
+  node_url: "wss://primary.example.com"
+  chain_id: 1
+  endpoints:
+    - url: "https://backup.example.com"
+      priority: 1
+  pool:
+    max_conns: 20
+    idle_timeout: 30s
+  health_check:
+    enabled: false
+`))
+	assert.NoError(t, err)
+
+	e := cfg.Ethereum
+	assert.Equal(t, uint64(1), e.ChainID)
+	assert.Equal(t, 20, e.Pool.MaxConns)
+	assert.Equal(t, 30*time.Second, e.Pool.IdleTimeout)
+	assert.False(t, e.HealthCheck.Enabled)
+	// 未配置的字段使用默认值
+	assert.Equal(t, ethereum.DefaultClientOptions().MaxIdleConns, e.Pool.MaxIdleConns)
+	assert.Equal(t, 3, e.Retry.MaxAttempts)
+
+	assert.Equal(t, []ethereum.Endpoint{
+		{URL: "wss://primary.example.com", Priority: 0},
+		{URL: "https://backup.example.com", Priority: 1},
+	}, cfg.EndpointList())
+
+	opts := cfg.ClientOptions()
+	assert.Equal(t, 20, opts.MaxConns)
+	assert.Equal(t, uint64(1), opts.ChainID)
+	assert.False(t, opts.HealthCheck)
+	assert.Equal(t, 3, opts.Retry.MaxAttempts)
+	assert.Equal(t, 200*time.Millisecond, opts.Retry.InitialBackoff)
+	assert.False(t, opts.Retry.RetrySendRawTransaction)
+}
+
+func TestValidate(t *testing.T) {
+	_, err := Parse([]byte(`
+ethereum:
+  endpoints:
+    - url: "ftp://node.example.com"
+    - url: ""
+  pool:
+    max_conns: 0
+    idle_timeout: -1s
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ethereum.endpoints[0].url: unsupported url scheme")
+	assert.Contains(t, err.Error(), "ethereum.endpoints[1].url: must not be empty")
+	assert.Contains(t, err.Error(), "ethereum.pool.max_conns: must be positive")
+	assert.Contains(t, err.Error(), "ethereum.pool.idle_timeout: must be positive")
+
+	_, err = Parse([]byte(`ethereum: {}`))
+	assert.ErrorContains(t, err, "either node_url or endpoints must be set")
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	cfg, err := Parse([]byte(`
+ethereum:
+  node_url: "wss://primary.example.com"
+  endpoints:
+    - url: "https://backup.example.com"
+      rate_limit:
+        requests_per_second: 5
+  rate_limit:
+    requests_per_second: 25
+    burst: 50
+    method_weights:
+      eth_getLogs: 5
+`))
+	assert.NoError(t, err)
+
+	opts := cfg.ClientOptions()
+	assert.Equal(t, ethereum.RateLimit{RequestsPerSecond: 25, Burst: 50, MethodWeights: map[string]int{"eth_getLogs": 5}}, opts.RateLimit)
+	endpoints := cfg.EndpointList()
+	assert.Nil(t, endpoints[0].RateLimit)
+	assert.Equal(t, &ethereum.RateLimit{RequestsPerSecond: 5}, endpoints[1].RateLimit)
+
+	_, err = Parse([]byte(`
+ethereum:
+  node_url: "wss://primary.example.com"
+  rate_limit:
+    requests_per_second: -1
+    method_weights:
+      eth_call: -2
+`))
+	assert.ErrorContains(t, err, "ethereum.rate_limit.requests_per_second: must not be negative")
+	assert.ErrorContains(t, err, "ethereum.rate_limit.method_weights.eth_call: must not be negative")
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg, err := Parse([]byte(`
+ethereum:
+  node_url: "wss://primary.example.com"
+`))
+	assert.NoError(t, err)
+
+	env := map[string]string{
+		"ETHERSCAN_NODE_URL":             "https://override.example.com",
+		"ETHERSCAN_ENDPOINTS":            "wss://a.example.com, wss://b.example.com",
+		"ETHERSCAN_CHAIN_ID":             "11155111",
+		"ETHERSCAN_POOL_IDLE_TIMEOUT":    "2m",
+		"ETHERSCAN_HEALTH_CHECK_ENABLED": "false",
+	}
+	err = cfg.ApplyEnv(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, cfg.Validate())
+
+	e := cfg.Ethereum
+	assert.Equal(t, "https://override.example.com", e.NodeURL)
+	assert.Equal(t, []EndpointConfig{
+		{URL: "wss://a.example.com", Priority: 0},
+		{URL: "wss://b.example.com", Priority: 1},
+	}, e.Endpoints)
+	assert.Equal(t, uint64(11155111), e.ChainID)
+	assert.Equal(t, 2*time.Minute, e.Pool.IdleTimeout)
+	assert.False(t, e.HealthCheck.Enabled)
+
+	err = cfg.ApplyEnv(func(key string) (string, bool) {
+		if key == "ETHERSCAN_POOL_MAX_CONNS" {
+			return "many", true
+		}
+		return "", false
+	})
+	assert.ErrorContains(t, err, "ETHERSCAN_POOL_MAX_CONNS")
+}
+
+func TestLoadRepositoryConfig(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", DefaultPath))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cfg.EndpointList())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
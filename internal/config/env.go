@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix 环境变量前缀
+const EnvPrefix = "ETHERSCAN_"
+
+// envVar 描述一个可覆盖配置的环境变量
+type envVar struct {
+	name  string                              // 去掉前缀后的变量名
+	apply func(c *Config, value string) error // 将变量值写入配置
+}
+
+// envVars 支持的环境变量，变量名为 EnvPrefix 加上 name，例如 ETHERSCAN_NODE_URL
+var envVars = []envVar{
+	{"NODE_URL", func(c *Config, v string) error {
+		c.Ethereum.NodeURL = v
+		return nil
+	}},
+	// 逗号分隔的端点列表，按出现顺序分配优先级，会替换配置文件中的 endpoints
+	{"ENDPOINTS", func(c *Config, v string) error {
+		c.Ethereum.Endpoints = nil
+		for _, u := range strings.Split(v, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				c.Ethereum.Endpoints = append(c.Ethereum.Endpoints, EndpointConfig{URL: u, Priority: len(c.Ethereum.Endpoints)})
+			}
+		}
+		return nil
+	}},
+	{"CHAIN_ID", uintVar(func(c *Config) *uint64 { return &c.Ethereum.ChainID })},
+	{"POOL_MAX_CONNS", intVar(func(c *Config) *int { return &c.Ethereum.Pool.MaxConns })},
+	{"POOL_MAX_IDLE_CONNS", intVar(func(c *Config) *int { return &c.Ethereum.Pool.MaxIdleConns })},
+	{"POOL_IDLE_TIMEOUT", durationVar(func(c *Config) *time.Duration { return &c.Ethereum.Pool.IdleTimeout })},
+	{"HEALTH_CHECK_ENABLED", boolVar(func(c *Config) *bool { return &c.Ethereum.HealthCheck.Enabled })},
+	{"HEALTH_CHECK_INTERVAL", durationVar(func(c *Config) *time.Duration { return &c.Ethereum.HealthCheck.Interval })},
+	{"HEALTH_CHECK_MAX_FAILURES", intVar(func(c *Config) *int { return &c.Ethereum.HealthCheck.MaxFailures })},
+	{"RETRY_MAX_ATTEMPTS", intVar(func(c *Config) *int { return &c.Ethereum.Retry.MaxAttempts })},
+	{"RETRY_INITIAL_BACKOFF", durationVar(func(c *Config) *time.Duration { return &c.Ethereum.Retry.InitialBackoff })},
+	{"RETRY_MAX_BACKOFF", durationVar(func(c *Config) *time.Duration { return &c.Ethereum.Retry.MaxBackoff })},
+	{"RETRY_SEND_RAW_TRANSACTION", boolVar(func(c *Config) *bool { return &c.Ethereum.Retry.SendRawTransaction })},
+	{"RATE_LIMIT_REQUESTS_PER_SECOND", floatVar(func(c *Config) *float64 { return &c.Ethereum.RateLimit.RequestsPerSecond })},
+	{"RATE_LIMIT_BURST", intVar(func(c *Config) *int { return &c.Ethereum.RateLimit.Burst })},
+}
+
+// ApplyEnv 使用环境变量覆盖配置
+//
+// Parameters:
+//   - lookup: func(string) (string, bool) 环境变量查询函数，通常为 os.LookupEnv
+//
+// Returns:
+//   - error: 环境变量格式错误时返回包含所有问题的错误
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	for _, v := range envVars {
+		value, ok := lookup(EnvPrefix + v.name)
+		if !ok {
+			continue
+		}
+		if err := v.apply(c, strings.TrimSpace(value)); err != nil {
+			errs = append(errs, fmt.Errorf("config: env %s%s: %v", EnvPrefix, v.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func intVar(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func floatVar(field func(c *Config) *float64) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func uintVar(field func(c *Config) *uint64) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.ParseUint(value, 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolVar(field func(c *Config) *bool) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func durationVar(field func(c *Config) *time.Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		*field(c) = d
+		return nil
+	}
+}
@@ -0,0 +1,242 @@
+// Package contract 在 JSON-RPC 客户端之上提供合约 ABI 编解码，包括：
+//   - 加载 JSON 格式的合约 ABI
+//   - 将 Go 值编码为方法调用数据，通过 eth_call 调用合约
+//   - 将返回值解码为 Go 类型
+//   - 将回滚数据解码为 Error(string)、Panic(uint256) 或 ABI 中定义的自定义错误
+//   - 根据 ABI 或事件签名将日志解码为事件
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// ErrNoData 合约调用没有返回数据，通常是因为地址上没有部署合约或合约不支持该方法
+var ErrNoData = errors.New("contract call returned no data")
+
+// Caller 合约调用所依赖的客户端接口，由 *ethereum.Client 实现
+type Caller interface {
+	CallWithMsg(ctx context.Context, msg ethereum.CallMsg, numberOrTag string) (string, error)
+}
+
+// CallOpts 合约调用的可选参数
+type CallOpts struct {
+	From  string   // 调用发送方地址，合约依赖 msg.sender 时需要设置
+	Value *big.Int // 随调用发送的以太币数量（单位：wei），用于模拟 payable 方法
+	Gas   uint64   // gas 限制，为0时由节点决定
+	Block string   // 区块号或标签，为空时使用 "latest"
+}
+
+// Contract 绑定了地址和 ABI 的合约，具有以下特性：
+//   - 方法参数使用 go-ethereum ABI 编码器支持的 Go 类型，如 common.Address、*big.Int、[]byte、string
+//   - 返回值解码为 []any，或通过 CallInto 解码到指定的变量或结构体
+//   - 调用回滚时返回 *RevertError，包含解码后的回滚原因
+//
+// Contract 是并发安全的。
+type Contract struct {
+	address common.Address
+	abi     abi.ABI
+	caller  Caller
+}
+
+// ParseABI 解析 JSON 格式的合约 ABI
+//
+// Parameters:
+//   - abiJSON: string ABI 的 JSON 内容，即 solc 或 Etherscan 输出的数组
+//
+// Returns:
+//   - abi.ABI: 解析后的 ABI
+//   - error: JSON 格式或类型定义无效时返回错误
+func ParseABI(abiJSON string) (abi.ABI, error) {
+	return LoadABI(strings.NewReader(abiJSON))
+}
+
+// LoadABI 从 reader 中读取并解析 JSON 格式的合约 ABI
+//
+// Parameters:
+//   - r: io.Reader ABI 的 JSON 内容，例如打开的 ABI 文件
+//
+// Returns:
+//   - abi.ABI: 解析后的 ABI
+//   - error: 读取失败、JSON 格式或类型定义无效时返回错误
+func LoadABI(r io.Reader) (abi.ABI, error) {
+	parsed, err := abi.JSON(r)
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("invalid contract abi: %v", err)
+	}
+	return parsed, nil
+}
+
+// New 创建绑定到指定地址的合约
+//
+// Parameters:
+//   - address: string 合约地址
+//   - contractABI: abi.ABI 合约 ABI，可以通过 ParseABI 或 LoadABI 获得
+//   - caller: Caller 发送 eth_call 请求的客户端，通常为 *ethereum.Client
+//
+// Returns:
+//   - *Contract: 合约实例
+//   - error: 地址无效时返回满足 errors.Is(err, ethereum.ErrInvalidAddress) 的错误
+func New(address string, contractABI abi.ABI, caller Caller) (*Contract, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: %s", ethereum.ErrInvalidAddress, address)
+	}
+	return &Contract{
+		address: common.HexToAddress(address),
+		abi:     contractABI,
+		caller:  caller,
+	}, nil
+}
+
+// Address 返回合约地址
+func (c *Contract) Address() common.Address {
+	return c.address
+}
+
+// ABI 返回合约 ABI
+func (c *Contract) ABI() abi.ABI {
+	return c.abi
+}
+
+// Pack 将方法调用编码为交易数据
+//
+// Parameters:
+//   - method: string 方法名，重载的方法使用 ABI 中的唯一名称（如 "safeTransferFrom0"）
+//   - args: ...any 方法参数，类型需与 ABI 定义匹配
+//
+// Returns:
+//   - string: 十六进制编码的调用数据，包含4字节的方法选择器
+//   - error: 方法不存在或参数类型不匹配时返回错误
+func (c *Contract) Pack(method string, args ...any) (string, error) {
+	data, err := c.abi.Pack(method, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s call: %v", method, err)
+	}
+	return hexutil.Encode(data), nil
+}
+
+// Unpack 解码方法的返回值
+//
+// Parameters:
+//   - method: string 方法名
+//   - result: string 十六进制编码的返回数据
+//
+// Returns:
+//   - []any: 按 ABI 定义顺序排列的返回值
+//   - error: 可能的错误：
+//   - 方法不存在
+//   - 返回数据为空（ErrNoData）
+//   - 返回数据与 ABI 定义不匹配
+func (c *Contract) Unpack(method string, result string) ([]any, error) {
+	data, err := c.resultData(method, result)
+	if err != nil {
+		return nil, err
+	}
+	values, err := c.abi.Unpack(method, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %v", method, err)
+	}
+	return values, nil
+}
+
+// resultData 校验并解码十六进制的返回数据
+func (c *Contract) resultData(method string, result string) ([]byte, error) {
+	m, ok := c.abi.Methods[method]
+	if !ok {
+		return nil, fmt.Errorf("method %s not found in contract abi", method)
+	}
+	data, err := hexutil.Decode(result)
+	if err != nil {
+		if result != "0x" && result != "" {
+			return nil, fmt.Errorf("invalid %s result %q: %v", method, result, err)
+		}
+		data = nil
+	}
+	if len(data) == 0 && len(m.Outputs) > 0 {
+		return nil, fmt.Errorf("%w: %s on %s", ErrNoData, method, c.address.Hex())
+	}
+	return data, nil
+}
+
+// Call 调用合约的只读方法并解码返回值
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - opts: *CallOpts 调用参数，为 nil 时在最新区块上调用
+//   - method: string 方法名
+//   - args: ...any 方法参数
+//
+// Returns:
+//   - []any: 按 ABI 定义顺序排列的返回值
+//   - error: 可能的错误：
+//   - 参数编码失败
+//   - 合约回滚（*RevertError，包含解码后的回滚原因）
+//   - 节点连接错误
+//   - 返回值解码失败
+func (c *Contract) Call(ctx context.Context, opts *CallOpts, method string, args ...any) ([]any, error) {
+	result, err := c.call(ctx, opts, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return c.Unpack(method, result)
+}
+
+// CallInto 调用合约的只读方法，并将返回值解码到 out
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - opts: *CallOpts 调用参数，为 nil 时在最新区块上调用
+//   - out: any 接收返回值的指针；单个返回值时指向对应类型（如 **big.Int），
+//     多个返回值时指向字段名与返回值名称对应的结构体
+//   - method: string 方法名
+//   - args: ...any 方法参数
+//
+// Returns:
+//   - error: 与 Call 相同，另外 out 的类型与返回值不匹配时返回错误
+func (c *Contract) CallInto(ctx context.Context, opts *CallOpts, out any, method string, args ...any) error {
+	result, err := c.call(ctx, opts, method, args...)
+	if err != nil {
+		return err
+	}
+	data, err := c.resultData(method, result)
+	if err != nil {
+		return err
+	}
+	if err := c.abi.UnpackIntoInterface(out, method, data); err != nil {
+		return fmt.Errorf("failed to decode %s result: %v", method, err)
+	}
+	return nil
+}
+
+// call 编码参数并发送 eth_call，回滚错误转换为 *RevertError
+func (c *Contract) call(ctx context.Context, opts *CallOpts, method string, args ...any) (string, error) {
+	data, err := c.Pack(method, args...)
+	if err != nil {
+		return "", err
+	}
+	if opts == nil {
+		opts = &CallOpts{}
+	}
+
+	result, err := c.caller.CallWithMsg(ctx, ethereum.CallMsg{
+		From:  opts.From,
+		To:    c.address.Hex(),
+		Gas:   opts.Gas,
+		Value: opts.Value,
+		Data:  data,
+	}, opts.Block)
+	if err != nil {
+		return "", c.revertError(err)
+	}
+	return result, nil
+}
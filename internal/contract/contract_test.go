@@ -0,0 +1,154 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+const testABI = `[
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"getReserves","stateMutability":"view","inputs":[],"outputs":[{"name":"reserve0","type":"uint112"},{"name":"reserve1","type":"uint112"},{"name":"blockTimestampLast","type":"uint32"}]},
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}
+]`
+
+const (
+	testToken = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	testOwner = "0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae"
+)
+
+// fakeCaller 记录调用参数并返回预设结果的客户端
+type fakeCaller struct {
+	msg    ethereum.CallMsg
+	block  string
+	result string
+	err    error
+}
+
+func (f *fakeCaller) CallWithMsg(ctx context.Context, msg ethereum.CallMsg, numberOrTag string) (string, error) {
+	f.msg, f.block = msg, numberOrTag
+	return f.result, f.err
+}
+
+func newTestContract(t *testing.T, caller Caller) *Contract {
+	parsed, err := ParseABI(testABI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(testToken, parsed, caller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// revertRPCError 构造节点返回的带回滚数据的 JSON-RPC 错误
+func revertRPCError(data []byte) error {
+	raw, _ := json.Marshal(hexutil.Encode(data))
+	return &ethereum.RPCError{Code: 3, Message: "execution reverted", Data: raw}
+}
+
+func TestContractCall(t *testing.T) {
+	caller := &fakeCaller{result: "0x00000000000000000000000000000000000000000000000000000000000f4240"}
+	c := newTestContract(t, caller)
+
+	values, err := c.Call(context.Background(), &CallOpts{Block: "0x10"}, "balanceOf", common.HexToAddress(testOwner))
+	assert.NoError(t, err)
+	assert.Equal(t, []any{big.NewInt(1000000)}, values)
+	assert.Equal(t, "0x70a08231000000000000000000000000de0b295669a9fd93d5f28d9ec85e40f4cb697bae", caller.msg.Data)
+	assert.Equal(t, common.HexToAddress(testToken).Hex(), caller.msg.To)
+	assert.Equal(t, "0x10", caller.block)
+
+	var balance *big.Int
+	assert.NoError(t, c.CallInto(context.Background(), nil, &balance, "balanceOf", common.HexToAddress(testOwner)))
+	assert.Equal(t, big.NewInt(1000000), balance)
+
+	// 多个返回值解码到结构体
+	packed, err := c.abi.Methods["getReserves"].Outputs.Pack(big.NewInt(5), big.NewInt(7), uint32(1700000000))
+	assert.NoError(t, err)
+	caller.result = hexutil.Encode(packed)
+	var reserves struct {
+		Reserve0           *big.Int
+		Reserve1           *big.Int
+		BlockTimestampLast uint32
+	}
+	assert.NoError(t, c.CallInto(context.Background(), nil, &reserves, "getReserves"))
+	assert.Equal(t, big.NewInt(7), reserves.Reserve1)
+	assert.Equal(t, uint32(1700000000), reserves.BlockTimestampLast)
+
+	// 地址上没有合约
+	caller.result = "0x"
+	_, err = c.Call(context.Background(), nil, "balanceOf", common.HexToAddress(testOwner))
+	assert.ErrorIs(t, err, ErrNoData)
+
+	_, err = c.Pack("balanceOf", "not an address")
+	assert.Error(t, err)
+	_, err = c.Call(context.Background(), nil, "missing")
+	assert.Error(t, err)
+
+	_, err = New("0x1234", c.abi, caller)
+	assert.ErrorIs(t, err, ethereum.ErrInvalidAddress)
+}
+
+func TestContractCallRevert(t *testing.T) {
+	caller := &fakeCaller{}
+	c := newTestContract(t, caller)
+	to := common.HexToAddress(testOwner)
+
+	// require(false, "reason")
+	reason, _ := abi.Arguments{{Type: mustType("string")}}.Pack("ERC20: transfer amount exceeds balance")
+	caller.err = revertRPCError(append(append([]byte{}, errorSelector...), reason...))
+	_, err := c.Call(context.Background(), nil, "transfer", to, big.NewInt(1))
+	var rev *RevertError
+	assert.ErrorAs(t, err, &rev)
+	assert.Equal(t, "ERC20: transfer amount exceeds balance", rev.Reason)
+	assert.EqualError(t, err, "execution reverted: ERC20: transfer amount exceeds balance")
+	var rpcErr *ethereum.RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+
+	// 算术溢出
+	code, _ := abi.Arguments{{Type: mustType("uint256")}}.Pack(big.NewInt(0x11))
+	caller.err = revertRPCError(append(append([]byte{}, panicSelector...), code...))
+	_, err = c.Call(context.Background(), nil, "transfer", to, big.NewInt(1))
+	assert.ErrorAs(t, err, &rev)
+	assert.Equal(t, big.NewInt(0x11), rev.PanicCode)
+	assert.EqualError(t, err, "execution reverted: panic: arithmetic underflow or overflow (0x11)")
+
+	// 自定义错误
+	custom := c.abi.Errors["InsufficientBalance"]
+	args, _ := custom.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	caller.err = revertRPCError(append(custom.ID[:4], args...))
+	_, err = c.Call(context.Background(), nil, "transfer", to, big.NewInt(2))
+	assert.ErrorAs(t, err, &rev)
+	assert.Equal(t, "InsufficientBalance", rev.ErrorName)
+	assert.Equal(t, []any{big.NewInt(1), big.NewInt(2)}, rev.Args)
+	assert.EqualError(t, err, "execution reverted: InsufficientBalance(available=1, required=2)")
+
+	// 无法识别的回滚数据
+	caller.err = revertRPCError([]byte{0xde, 0xad, 0xbe, 0xef})
+	_, err = c.Call(context.Background(), nil, "transfer", to, big.NewInt(1))
+	assert.EqualError(t, err, "execution reverted: unknown error 0xdeadbeef")
+
+	// 节点不返回回滚数据
+	caller.err = &ethereum.RPCError{Code: -32000, Message: "execution reverted: Ownable: caller is not the owner"}
+	_, err = c.Call(context.Background(), nil, "transfer", to, big.NewInt(1))
+	assert.ErrorAs(t, err, &rev)
+	assert.Equal(t, "Ownable: caller is not the owner", rev.Reason)
+
+	// 其他错误原样返回
+	caller.err = errors.New("connection reset")
+	_, err = c.Call(context.Background(), nil, "transfer", to, big.NewInt(1))
+	assert.EqualError(t, err, "connection reset")
+	_, ok := ParseRevert(err, nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,296 @@
+package contract
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// EventArg 解码后的事件参数
+type EventArg struct {
+	Name    string // 参数名，ABI 中未命名的参数为 "arg0"、"arg1" 等
+	Type    string // Solidity 类型，如 "address"、"uint256"
+	Indexed bool   // 是否为 indexed 参数；indexed 的动态类型（string、bytes、数组）只能得到其 keccak256 哈希（common.Hash）
+	Value   any    // 参数值，类型与 go-ethereum ABI 解码结果一致，如 common.Address、*big.Int
+}
+
+// Event 解码后的事件日志
+type Event struct {
+	Name      string     // 事件名，未知事件为空
+	Signature string     // 事件签名，如 "Transfer(address,address,uint256)"，未知事件为空
+	Args      []EventArg // 按 ABI 定义顺序排列的参数，未知事件为 nil
+	Log       *eth.Log   // 原始日志，未知事件可以从中读取 topics 和 data
+}
+
+// Known 返回事件是否被成功解码
+func (e *Event) Known() bool {
+	return e.Name != ""
+}
+
+// Arg 按名称查找参数值
+//
+// Parameters:
+//   - name: string 参数名
+//
+// Returns:
+//   - any: 参数值
+//   - bool: 是否存在该参数
+func (e *Event) Arg(name string) (any, bool) {
+	for _, arg := range e.Args {
+		if arg.Name == name {
+			return arg.Value, true
+		}
+	}
+	return nil, false
+}
+
+// eventCandidate 一个 topic0 对应的候选事件定义
+type eventCandidate struct {
+	event abi.Event
+	// inferIndexed 由不带 indexed 标记的签名注册，解码时按 topic 数量将前几个参数视为 indexed
+	inferIndexed bool
+}
+
+// EventDecoder 根据注册的 ABI 和事件签名解码日志，具有以下特性：
+//   - 按合约地址注册的 ABI 优先于全局注册的 ABI 和事件签名
+//   - 同一 topic0 可以对应多个定义（如 ERC-20 与 ERC-721 的 Transfer），按 indexed 参数数量和数据长度选择能解码的定义
+//   - 无法解码的日志返回未知事件，保留原始日志，不返回错误
+//
+// EventDecoder 是并发安全的。
+type EventDecoder struct {
+	mu         sync.RWMutex
+	global     map[common.Hash][]eventCandidate
+	byContract map[common.Address]map[common.Hash][]eventCandidate
+}
+
+// NewEventDecoder 创建没有注册任何事件的解码器
+//
+// Returns:
+//   - *EventDecoder: 事件解码器
+func NewEventDecoder() *EventDecoder {
+	return &EventDecoder{
+		global:     make(map[common.Hash][]eventCandidate),
+		byContract: make(map[common.Address]map[common.Hash][]eventCandidate),
+	}
+}
+
+// RegisterABI 注册 ABI 中的所有事件，用于解码任意合约发出的日志
+//
+// Parameters:
+//   - contractABI: abi.ABI 合约 ABI，匿名事件没有 topic0，会被忽略
+func (d *EventDecoder) RegisterABI(contractABI abi.ABI) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	addEvents(d.global, contractABI)
+}
+
+// RegisterContractABI 注册只用于解码指定合约日志的 ABI
+//
+// Parameters:
+//   - address: string 合约地址
+//   - contractABI: abi.ABI 合约 ABI
+//
+// Returns:
+//   - error: 地址无效时返回错误
+func (d *EventDecoder) RegisterContractABI(address string, contractABI abi.ABI) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid contract address: %s", address)
+	}
+	addr := common.HexToAddress(address)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.byContract[addr] == nil {
+		d.byContract[addr] = make(map[common.Hash][]eventCandidate)
+	}
+	addEvents(d.byContract[addr], contractABI)
+	return nil
+}
+
+// RegisterEvent 通过可读的事件签名注册事件，用于没有完整 ABI 的场景
+//
+// Parameters:
+//   - signature: string 事件签名，可以带 "event" 前缀、indexed 标记和参数名，例如：
+//   - "event Transfer(address indexed from, address indexed to, uint256 value)"
+//   - "Transfer(address,address,uint256)"：不带 indexed 标记时按日志的 topic 数量将前几个参数视为 indexed
+//
+// Returns:
+//   - error: 签名格式或参数类型无效时返回错误，暂不支持 tuple 类型的参数
+func (d *EventDecoder) RegisterEvent(signature string) error {
+	event, hasIndexed, err := parseEventSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.global[event.ID] = append(d.global[event.ID], eventCandidate{event: event, inferIndexed: !hasIndexed})
+	return nil
+}
+
+// addEvents 将 ABI 中的非匿名事件加入索引，调用方需持有写锁
+func addEvents(index map[common.Hash][]eventCandidate, contractABI abi.ABI) {
+	for _, event := range contractABI.Events {
+		if event.Anonymous {
+			continue
+		}
+		index[event.ID] = append(index[event.ID], eventCandidate{event: event})
+	}
+}
+
+// Decode 解码一条日志
+//
+// Parameters:
+//   - log: *eth.Log 节点返回的日志
+//
+// Returns:
+//   - *Event: 解码后的事件；没有匹配的事件定义或解码失败时返回未知事件，Name 为空
+func (d *EventDecoder) Decode(log *eth.Log) *Event {
+	unknown := &Event{Log: log}
+	if log == nil || len(log.Topics) == 0 {
+		return unknown
+	}
+	topics := make([]common.Hash, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = common.HexToHash(topic.String())
+	}
+	data := log.Data.Bytes()
+
+	d.mu.RLock()
+	candidates := append([]eventCandidate{}, d.byContract[common.HexToAddress(log.Address.String())][topics[0]]...)
+	candidates = append(candidates, d.global[topics[0]]...)
+	d.mu.RUnlock()
+
+	for _, candidate := range candidates {
+		if args, err := decodeEvent(candidate, topics, data); err == nil {
+			return &Event{
+				Name:      candidate.event.RawName,
+				Signature: candidate.event.Sig,
+				Args:      args,
+				Log:       log,
+			}
+		}
+	}
+	return unknown
+}
+
+// DecodeAll 解码多条日志，结果与输入一一对应
+//
+// Parameters:
+//   - logs: []*eth.Log 节点返回的日志，如 GetLogs 或交易收据中的日志
+//
+// Returns:
+//   - []*Event: 解码后的事件，无法解码的日志为未知事件
+func (d *EventDecoder) DecodeAll(logs []*eth.Log) []*Event {
+	events := make([]*Event, len(logs))
+	for i, log := range logs {
+		events[i] = d.Decode(log)
+	}
+	return events
+}
+
+// decodeEvent 按一个候选定义解码日志的 topics 和 data
+func decodeEvent(candidate eventCandidate, topics []common.Hash, data []byte) ([]EventArg, error) {
+	inputs := candidate.event.Inputs
+	if candidate.inferIndexed {
+		n := len(topics) - 1
+		if n > len(inputs) {
+			return nil, fmt.Errorf("log has %d indexed topics but event has %d arguments", n, len(inputs))
+		}
+		inputs = append(abi.Arguments{}, inputs...)
+		for i := range inputs {
+			inputs[i].Indexed = i < n
+		}
+	}
+
+	indexed := 0
+	for _, input := range inputs {
+		if input.Indexed {
+			indexed++
+		}
+	}
+	if indexed != len(topics)-1 {
+		return nil, fmt.Errorf("log has %d indexed topics, event %s expects %d", len(topics)-1, candidate.event.Sig, indexed)
+	}
+
+	values, err := inputs.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	// 数据长度与定义不一致说明是同一签名的其他定义（如 indexed 参数不同）
+	if packed, err := inputs.NonIndexed().PackValues(values); err != nil || len(packed) != len(data) {
+		return nil, fmt.Errorf("log data length %d does not match event %s", len(data), candidate.event.Sig)
+	}
+
+	args := make([]EventArg, len(inputs))
+	topic, value := 1, 0
+	for i, input := range inputs {
+		args[i] = EventArg{Name: input.Name, Type: input.Type.String(), Indexed: input.Indexed}
+		if !input.Indexed {
+			args[i].Value = values[value]
+			value++
+			continue
+		}
+		out := make(map[string]any, 1)
+		if err := abi.ParseTopicsIntoMap(out, abi.Arguments{input}, topics[topic:topic+1]); err != nil {
+			return nil, err
+		}
+		args[i].Value = out[input.Name]
+		topic++
+	}
+	return args, nil
+}
+
+// parseEventSignature 解析可读的事件签名
+//
+// Returns:
+//   - abi.Event: 事件定义
+//   - bool: 签名中是否带有 indexed 标记
+//   - error: 签名格式或参数类型无效时返回错误
+func parseEventSignature(signature string) (abi.Event, bool, error) {
+	sig := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(signature), "event "))
+	open := strings.Index(sig, "(")
+	if open <= 0 || !strings.HasSuffix(sig, ")") {
+		return abi.Event{}, false, fmt.Errorf("invalid event signature %q", signature)
+	}
+	name := strings.TrimSpace(sig[:open])
+	params := strings.TrimSpace(sig[open+1 : len(sig)-1])
+	if strings.ContainsAny(params, "()") {
+		return abi.Event{}, false, fmt.Errorf("invalid event signature %q: tuple arguments are not supported", signature)
+	}
+
+	var inputs abi.Arguments
+	hasIndexed := false
+	if params != "" {
+		for i, param := range strings.Split(params, ",") {
+			fields := strings.Fields(param)
+			if len(fields) == 0 {
+				return abi.Event{}, false, fmt.Errorf("invalid event signature %q: empty argument %d", signature, i)
+			}
+			typ, err := abi.NewType(fields[0], "", nil)
+			if err != nil {
+				return abi.Event{}, false, fmt.Errorf("invalid event signature %q: %v", signature, err)
+			}
+			arg := abi.Argument{Type: typ}
+			rest := fields[1:]
+			if len(rest) > 0 && rest[0] == "indexed" {
+				arg.Indexed = true
+				hasIndexed = true
+				rest = rest[1:]
+			}
+			switch len(rest) {
+			case 0:
+			case 1:
+				arg.Name = rest[0]
+			default:
+				return abi.Event{}, false, fmt.Errorf("invalid event signature %q: malformed argument %q", signature, param)
+			}
+			inputs = append(inputs, arg)
+		}
+	}
+	return abi.NewEvent(name, name, false, inputs), hasIndexed, nil
+}
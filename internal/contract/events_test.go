@@ -0,0 +1,138 @@
+package contract
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/stretchr/testify/assert"
+)
+
+const erc20EventsABI = `[
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Approval","anonymous":false,"inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+const erc721EventsABI = `[
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]}
+]`
+
+// testLog 构造节点返回的日志
+func testLog(address string, data []byte, topics ...common.Hash) *eth.Log {
+	log := &eth.Log{Address: eth.Address(address), Data: eth.Data(hexutil.Encode(data))}
+	for _, topic := range topics {
+		log.Topics = append(log.Topics, eth.Topic(topic.Hex()))
+	}
+	return log
+}
+
+func TestEventDecoder(t *testing.T) {
+	erc20, err := ParseABI(erc20EventsABI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	erc721, err := ParseABI(erc721EventsABI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewEventDecoder()
+	d.RegisterABI(erc20)
+	d.RegisterABI(erc721)
+
+	transferID := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	from := common.HexToAddress(testOwner)
+	to := common.HexToAddress(testToken)
+	amount := common.BigToHash(big.NewInt(1000000))
+
+	// ERC-20 Transfer：value 在 data 中
+	event := d.Decode(testLog(testToken, amount[:], transferID, common.BytesToHash(from[:]), common.BytesToHash(to[:])))
+	assert.True(t, event.Known())
+	assert.Equal(t, "Transfer", event.Name)
+	assert.Equal(t, "Transfer(address,address,uint256)", event.Signature)
+	assert.Equal(t, []EventArg{
+		{Name: "from", Type: "address", Indexed: true, Value: from},
+		{Name: "to", Type: "address", Indexed: true, Value: to},
+		{Name: "value", Type: "uint256", Value: big.NewInt(1000000)},
+	}, event.Args)
+
+	// ERC-721 Transfer：topic0 相同，tokenId 为 indexed 参数
+	event = d.Decode(testLog(testToken, nil, transferID, common.BytesToHash(from[:]), common.BytesToHash(to[:]), common.BigToHash(big.NewInt(42))))
+	assert.True(t, event.Known())
+	tokenID, ok := event.Arg("tokenId")
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(42), tokenID)
+	_, ok = event.Arg("value")
+	assert.False(t, ok)
+
+	// 未注册的事件保留原始日志
+	unknownLog := testLog(testToken, nil, crypto.Keccak256Hash([]byte("Sync(uint112,uint112)")))
+	event = d.Decode(unknownLog)
+	assert.False(t, event.Known())
+	assert.Nil(t, event.Args)
+	assert.Same(t, unknownLog, event.Log)
+
+	// 数据与定义不匹配时同样返回未知事件
+	assert.False(t, d.Decode(testLog(testToken, []byte{1, 2, 3}, transferID, common.BytesToHash(from[:]), common.BytesToHash(to[:]))).Known())
+	assert.False(t, d.Decode(testLog(testToken, nil)).Known())
+
+	events := d.DecodeAll([]*eth.Log{unknownLog, testLog(testToken, amount[:], erc20.Events["Approval"].ID, common.BytesToHash(from[:]), common.BytesToHash(to[:]))})
+	assert.Len(t, events, 2)
+	assert.Equal(t, "Approval", events[1].Name)
+}
+
+func TestEventDecoderContractABI(t *testing.T) {
+	// 指定合约的 ABI 优先于全局注册的事件签名
+	d := NewEventDecoder()
+	assert.NoError(t, d.RegisterEvent("Deposit(address,uint256)"))
+	weth, err := ParseABI(`[{"type":"event","name":"Deposit","inputs":[{"name":"dst","type":"address","indexed":true},{"name":"wad","type":"uint256","indexed":false}]}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, d.RegisterContractABI(testToken, weth))
+	assert.Error(t, d.RegisterContractABI("0x1234", weth))
+
+	owner := common.HexToAddress(testOwner)
+	wad := common.BigToHash(big.NewInt(5))
+	log := testLog(testToken, wad[:], weth.Events["Deposit"].ID, common.BytesToHash(owner[:]))
+	event := d.Decode(log)
+	dst, _ := event.Arg("dst")
+	assert.Equal(t, owner, dst)
+
+	// 其他合约的同名事件使用签名解码，参数名为 arg0、arg1
+	log.Address = eth.Address(testOwner)
+	event = d.Decode(log)
+	assert.True(t, event.Known())
+	assert.Equal(t, []EventArg{
+		{Name: "arg0", Type: "address", Indexed: true, Value: owner},
+		{Name: "arg1", Type: "uint256", Value: big.NewInt(5)},
+	}, event.Args)
+}
+
+func TestRegisterEvent(t *testing.T) {
+	d := NewEventDecoder()
+	assert.NoError(t, d.RegisterEvent("event Named(string indexed name, address indexed owner, bytes data)"))
+
+	// indexed 的动态类型只能得到哈希
+	name := crypto.Keccak256Hash([]byte("alice.eth"))
+	owner := common.HexToAddress(testOwner)
+	data, _ := abi.Arguments{{Type: mustType("bytes")}}.Pack([]byte{0xca, 0xfe})
+	event := d.Decode(testLog(testToken, data, crypto.Keccak256Hash([]byte("Named(string,address,bytes)")), name, common.BytesToHash(owner[:])))
+	assert.True(t, event.Known())
+	assert.Equal(t, []EventArg{
+		{Name: "name", Type: "string", Indexed: true, Value: name},
+		{Name: "owner", Type: "address", Indexed: true, Value: owner},
+		{Name: "data", Type: "bytes", Value: []byte{0xca, 0xfe}},
+	}, event.Args)
+
+	assert.NoError(t, d.RegisterEvent("Paused()"))
+	assert.True(t, d.Decode(testLog(testToken, nil, crypto.Keccak256Hash([]byte("Paused()")))).Known())
+
+	assert.Error(t, d.RegisterEvent("Transfer"))
+	assert.Error(t, d.RegisterEvent("Transfer(address,addr)"))
+	assert.Error(t, d.RegisterEvent("Swap((address,uint256) order)"))
+	assert.Error(t, d.RegisterEvent("Transfer(address indexed from to)"))
+}
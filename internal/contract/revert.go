@@ -0,0 +1,176 @@
+package contract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/justinwongcn/etherscan/internal/ethereum"
+)
+
+// Solidity 内置错误的选择器
+var (
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// panicReasons Solidity Panic(uint256) 错误码的含义
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "pop on an empty array",
+	0x32: "out-of-bounds array access",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
+
+// RevertError 合约执行回滚的错误，可以通过 errors.As 获取，具有以下特性：
+//   - require(cond, "reason") 和 revert("reason") 解码为 Reason
+//   - assert、算术溢出等触发的 Panic(uint256) 解码为 PanicCode，Reason 为错误码的含义
+//   - ABI 中定义的自定义错误解码为 ErrorName 和 Args
+//   - 通过 errors.Unwrap 可以获取节点返回的原始错误（通常为 *ethereum.RPCError）
+type RevertError struct {
+	Reason    string   // 可读的回滚原因，无法解码时为空
+	PanicCode *big.Int // Panic(uint256) 的错误码，其他回滚为 nil
+	ErrorName string   // 自定义错误的名称（如 "InsufficientBalance"），其他回滚为空
+	Args      []any    // 自定义错误的参数，按 ABI 定义顺序排列
+	Data      []byte   // 原始回滚数据，节点未返回时为空
+	err       error
+}
+
+// Error 实现 error 接口
+func (e *RevertError) Error() string {
+	switch {
+	case e.Reason != "":
+		return "execution reverted: " + e.Reason
+	case len(e.Data) > 0:
+		return fmt.Sprintf("execution reverted: unknown error %s", hexutil.Encode(e.Data))
+	default:
+		return "execution reverted"
+	}
+}
+
+// Unwrap 返回节点返回的原始错误
+func (e *RevertError) Unwrap() error {
+	return e.err
+}
+
+// DecodeRevert 解码回滚数据
+//
+// Parameters:
+//   - data: []byte 回滚数据，即 eth_call 错误中 data 字段的内容
+//   - contractABI: *abi.ABI 用于解码自定义错误的合约 ABI，为 nil 时只解码 Error(string) 和 Panic(uint256)
+//
+// Returns:
+//   - *RevertError: 解码后的回滚错误，数据无法识别时只填充 Data
+func DecodeRevert(data []byte, contractABI *abi.ABI) *RevertError {
+	rev := &RevertError{Data: data}
+	if len(data) < 4 {
+		return rev
+	}
+
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			rev.Reason = reason
+		}
+	case bytes.Equal(data[:4], panicSelector):
+		values, err := abi.Arguments{{Type: mustType("uint256")}}.Unpack(data[4:])
+		if err != nil {
+			break
+		}
+		rev.PanicCode = values[0].(*big.Int)
+		reason := "unknown panic code"
+		if rev.PanicCode.IsUint64() {
+			if r, ok := panicReasons[rev.PanicCode.Uint64()]; ok {
+				reason = r
+			}
+		}
+		rev.Reason = fmt.Sprintf("panic: %s (%#x)", reason, rev.PanicCode)
+	case contractABI != nil:
+		abiErr, err := contractABI.ErrorByID([4]byte(data[:4]))
+		if err != nil {
+			break
+		}
+		args, err := abiErr.Inputs.Unpack(data[4:])
+		if err != nil {
+			break
+		}
+		rev.ErrorName = abiErr.Name
+		rev.Args = args
+		rev.Reason = formatCustomError(abiErr, args)
+	}
+	return rev
+}
+
+// ParseRevert 从合约调用或 gas 估算的错误中提取回滚错误
+//
+// Parameters:
+//   - err: error Client.Call、Client.EstimateGas 等方法返回的错误
+//   - contractABI: *abi.ABI 用于解码自定义错误的合约 ABI，可以为 nil
+//
+// Returns:
+//   - *RevertError: 解码后的回滚错误，包装了原始错误
+//   - bool: err 是否为合约回滚；节点未返回回滚数据但错误信息表明回滚时同样返回 true
+func ParseRevert(err error, contractABI *abi.ABI) (*RevertError, bool) {
+	var rev *RevertError
+	if errors.As(err, &rev) {
+		return rev, true
+	}
+	var rpcErr *ethereum.RPCError
+	if !errors.As(err, &rpcErr) {
+		return nil, false
+	}
+
+	if hexData, ok := rpcErr.RevertData(); ok {
+		if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil {
+			rev = DecodeRevert(data, contractABI)
+			rev.err = err
+			return rev, true
+		}
+	}
+	// 部分节点不返回回滚数据，只在错误信息中给出原因
+	if msg, ok := strings.CutPrefix(rpcErr.Message, "execution reverted"); ok {
+		return &RevertError{Reason: strings.TrimPrefix(msg, ": "), err: err}, true
+	}
+	return nil, false
+}
+
+// revertError 将调用错误中的回滚转换为 *RevertError，其他错误原样返回
+func (c *Contract) revertError(err error) error {
+	if rev, ok := ParseRevert(err, &c.abi); ok {
+		return rev
+	}
+	return err
+}
+
+// formatCustomError 将自定义错误格式化为 Name(arg1, arg2) 的形式
+func formatCustomError(abiErr *abi.Error, args []any) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = fmt.Sprint(arg)
+		if name := abiErr.Inputs[i].Name; name != "" {
+			parts[i] = name + "=" + parts[i]
+		}
+	}
+	return fmt.Sprintf("%s(%s)", abiErr.Name, strings.Join(parts, ", "))
+}
+
+// mustType 创建基础 ABI 类型，类型名无效时 panic，只用于常量类型名
+func mustType(name string) abi.Type {
+	typ, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
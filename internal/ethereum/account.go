@@ -3,11 +3,10 @@ package ethereum
 import (
 	"context"
 	"fmt"
-	"sync"
+	"math/big"
 
 	"github.com/justinwongcn/go-ethlibs/eth"
 	"github.com/justinwongcn/go-ethlibs/node"
-	"golang.org/x/sync/errgroup"
 )
 
 // GetBalance 获取指定地址的账户余额
@@ -27,27 +26,46 @@ import (
 //   - 无效的地址格式
 //   - 无效的区块号格式
 //   - 节点连接错误
+//   - 余额超出 uint64 范围（约18.4 ether），请改用 GetBalanceBig
 func (c *Client) GetBalance(ctx context.Context, address string, numberOrTag string) (uint64, error) {
+	balance, err := c.GetBalanceBig(ctx, address, numberOrTag)
+	if err != nil {
+		return 0, err
+	}
+	return toUint64(balance, "balance")
+}
+
+// GetBalanceBig 获取指定地址的账户余额
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - address: string 要查询的账户地址
+//   - numberOrTag: string 区块号，可以是以下格式：
+//   - 十六进制字符串（如"0x1"）表示具体区块号
+//   - "latest" - 最新区块（默认）
+//   - "earliest" - 创世区块
+//   - "pending" - 待处理区块
+//
+// Returns:
+//   - *big.Int: 账户余额（单位：wei）
+//   - error: 可能的错误：
+//   - 无效的地址格式
+//   - 无效的区块号格式
+//   - 节点连接错误
+func (c *Client) GetBalanceBig(ctx context.Context, address string, numberOrTag string) (*big.Int, error) {
 	// 验证并转换地址格式
 	addr, err := eth.NewAddress(address)
 	if err != nil {
-		return 0, fmt.Errorf("invalid ethereum address: %v", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
 	}
 
 	// 处理默认值并验证区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return 0, fmt.Errorf("invalid block number or tag: %s", numberOrTag)
-	}
-
-	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
-		return conn.GetBalance(ctx, *addr, *numOrTag)
-	})
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
-	return result.(uint64), nil
+
+	return c.requestQuantity(ctx, "eth_getBalance", addr, numOrTag)
 }
 
 // GetBalances 批量获取多个地址的账户余额
@@ -60,81 +78,93 @@ func (c *Client) GetBalance(ctx context.Context, address string, numberOrTag str
 //   - "latest" - 最新区块（默认）
 //   - "earliest" - 创世区块
 //   - "pending" - 待处理区块
-//   - maxAddresses: int 单次查询最多支持的地址数量，默认值为5
+//   - maxAddresses: int 可选，单次查询最多支持的地址数量，不设置时不限制
 //
 // Returns:
-//   - map[string]uint64: 地址到余额的映射，以wei为单位
+//   - map[string]uint64: 地址到余额的映射，以wei为单位，仅包含查询成功的地址
 //   - error: 可能的错误：
 //   - 地址列表为空
 //   - 地址数量超过限制
-//   - 无效的地址格式
 //   - 无效的区块号格式
-//   - 节点连接错误
+//   - *BatchError: 部分地址查询失败（如地址格式无效），此时仍返回其余地址的余额
+//   - 余额超出 uint64 范围（约18.4 ether），请改用 GetBalancesBig
 func (c *Client) GetBalances(ctx context.Context, addresses []string, numberOrTag string, maxAddresses ...int) (map[string]uint64, error) {
-	// 验证地址列表
-	if len(addresses) == 0 {
-		return nil, fmt.Errorf("address list is empty")
+	balances, err := c.GetBalancesBig(ctx, addresses, numberOrTag, maxAddresses...)
+	if balances == nil {
+		return nil, err
 	}
 
-	// 设置最大地址数量限制
-	maxAddr := 5 // 默认值
-	if len(maxAddresses) > 0 && maxAddresses[0] > 0 {
-		maxAddr = maxAddresses[0]
+	result := make(map[string]uint64, len(balances))
+	for addr, balance := range balances {
+		v, err := toUint64(balance, "balance of "+addr)
+		if err != nil {
+			return nil, err
+		}
+		result[addr] = v
 	}
+	return result, err
+}
 
-	if len(addresses) > maxAddr {
-		return nil, fmt.Errorf("too many addresses: %d (max: %d)", len(addresses), maxAddr)
+// GetBalancesBig 批量获取多个地址的账户余额
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - addresses: []string 要查询余额的账户地址列表
+//   - numberOrTag: string 区块号，可以是以下格式：
+//   - 十六进制字符串（如"0x1"）表示具体区块号
+//   - "latest" - 最新区块（默认）
+//   - "earliest" - 创世区块
+//   - "pending" - 待处理区块
+//   - maxAddresses: int 可选，单次查询最多支持的地址数量，不设置时不限制
+//
+// Returns:
+//   - map[string]*big.Int: 地址到余额的映射，以wei为单位，仅包含查询成功的地址
+//   - error: 可能的错误：
+//   - 地址列表为空
+//   - 地址数量超过限制
+//   - 无效的区块号格式
+//   - *BatchError: 部分地址查询失败（如地址格式无效），此时仍返回其余地址的余额
+//
+// 余额通过 JSON-RPC 批量请求查询，每 ClientOptions.BatchSize 个地址一次往返。
+func (c *Client) GetBalancesBig(ctx context.Context, addresses []string, numberOrTag string, maxAddresses ...int) (map[string]*big.Int, error) {
+	// 验证地址列表
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("address list is empty")
 	}
-
-	// 验证并转换区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return nil, fmt.Errorf("invalid block number or tag: %s", numberOrTag)
+	if len(maxAddresses) > 0 && maxAddresses[0] > 0 && len(addresses) > maxAddresses[0] {
+		return nil, fmt.Errorf("too many addresses: %d (max: %d)", len(addresses), maxAddresses[0])
 	}
 
-	// 创建结果映射
-	result := make(map[string]uint64)
-
-	// 使用 errgroup 进行并发请求
-	g, ctx := errgroup.WithContext(ctx)
-	var mu sync.Mutex
-
-	// 并发获取每个地址的余额
-	for _, address := range addresses {
-		addr := address // 创建副本以避免闭包问题
-		g.Go(func() error {
-			// 从连接池获取连接
-			conn, err := c.getConnection(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to get connection: %v", err)
-			}
-			defer c.releaseConnection(conn)
-
-			// 验证并转换地址格式
-			ethAddr, err := eth.NewAddress(addr)
-			if err != nil {
-				return fmt.Errorf("invalid ethereum address: %v", err)
-			}
-
-			// 调用节点接口获取余额
-			balance, err := conn.GetBalance(ctx, *ethAddr, *numOrTag)
-			if err != nil {
-				return fmt.Errorf("failed to get balance for %s: %v", addr, err)
-			}
-
-			// 线程安全地更新结果映射
-			mu.Lock()
-			result[addr] = balance
-			mu.Unlock()
-			return nil
-		})
+	// 验证区块号格式，区块号无效时所有地址都会失败
+	if _, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag)); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
 
-	// 等待所有请求完成
-	if err := g.Wait(); err != nil {
+	// 每个地址只查询一次
+	batch := c.Batch(ctx)
+	pending := make(map[string]*BatchResult[*big.Int], len(addresses))
+	for _, addr := range addresses {
+		if _, ok := pending[addr]; !ok {
+			pending[addr] = batch.GetBalance(addr, numberOrTag)
+		}
+	}
+	if err := batch.Send(); err != nil && ctx.Err() != nil {
 		return nil, err
 	}
 
+	result := make(map[string]*big.Int, len(pending))
+	failed := make(map[string]error)
+	for addr, r := range pending {
+		balance, err := r.Result()
+		if err != nil {
+			failed[addr] = fmt.Errorf("failed to get balance for %s: %w", addr, err)
+			continue
+		}
+		result[addr] = balance
+	}
+	if len(failed) > 0 {
+		return result, &BatchError{Errors: failed, Total: len(pending)}
+	}
 	return result, nil
 }
 
@@ -159,17 +189,17 @@ func (c *Client) GetCode(ctx context.Context, address string, numberOrTag string
 	// 验证并转换地址格式
 	addr, err := eth.NewAddress(address)
 	if err != nil {
-		return "", fmt.Errorf("invalid ethereum address: %v", err)
+		return "", fmt.Errorf("%w: %v", ErrInvalidAddress, err)
 	}
 
 	// 处理默认值并验证区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return "", fmt.Errorf("invalid block number or tag: %s", numberOrTag)
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getCode", func(conn node.Client) (any, error) {
 		return conn.GetCode(ctx, *addr, *numOrTag)
 	})
 	if err != nil {
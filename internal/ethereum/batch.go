@@ -0,0 +1,514 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/justinwongcn/go-ethlibs/node"
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultBatchSize 单个批量请求默认包含的最大调用数，多数节点服务商的限制在100到1000之间
+const defaultBatchSize = 100
+
+// batchConcurrency 一个 Batch 同时在途的批量请求数
+const batchConcurrency = 4
+
+// errBatchNotSent 在 Send 之前读取结果时返回
+var errBatchNotSent = errors.New("batch has not been sent")
+
+// BatchError 批量查询中部分调用失败时返回的错误
+type BatchError struct {
+	Errors map[string]error // 失败的调用，以查询的键（如地址）索引
+	Total  int              // 查询的调用总数
+}
+
+// Error 实现 error 接口，包含失败数量和其中一个失败原因
+func (e *BatchError) Error() string {
+	keys := e.keys()
+	if len(keys) == 0 {
+		return fmt.Sprintf("0 of %d batch calls failed", e.Total)
+	}
+	return fmt.Sprintf("%d of %d batch calls failed, first: %v", len(keys), e.Total, e.Errors[keys[0]])
+}
+
+// Unwrap 按键排序返回所有失败调用的错误，使 errors.Is 和 errors.As 可以匹配其中任意一个
+func (e *BatchError) Unwrap() []error {
+	keys := e.keys()
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		errs[i] = e.Errors[key]
+	}
+	return errs
+}
+
+// keys 返回排序后的失败调用的键
+func (e *BatchError) keys() []string {
+	keys := make([]string, 0, len(e.Errors))
+	for key := range e.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Batch JSON-RPC 批量请求构造器，具有以下特性：
+//   - 调用先入队，Send 时按 ClientOptions.BatchSize 分组，每组作为一个 JSON-RPC 批量请求发送
+//   - HTTP 端点发送 JSON 数组，websocket 和 IPC 端点在同一个连接上并发发送
+//   - 单个调用的错误（参数无效、节点返回错误）只影响该调用的结果，不影响整个批次
+//
+// Batch 不是并发安全的，应在同一个协程中入队和发送。
+type Batch struct {
+	c     *Client
+	ctx   context.Context
+	calls []*batchCall
+	sent  bool
+}
+
+// batchCall 批量请求中的一个调用
+type batchCall struct {
+	method string
+	params []any
+	err    error                                   // 入队时的参数错误，此类调用不会发送给节点
+	done   func(result json.RawMessage, err error) // 收到结果后写入对应的 BatchResult
+}
+
+// BatchResult 批量请求中单个调用的结果，在 Batch.Send 返回后可用
+type BatchResult[T any] struct {
+	value T
+	err   error
+	done  bool
+}
+
+// Result 返回调用的结果
+//
+// Returns:
+//   - T: 解码后的结果
+//   - error: 可能的错误：
+//   - 批量请求尚未发送
+//   - 入队时的参数错误
+//   - 节点返回的 JSON-RPC 错误
+//   - 该调用所在的批量请求发送失败
+func (r *BatchResult[T]) Result() (T, error) {
+	if !r.done {
+		var zero T
+		return zero, errBatchNotSent
+	}
+	return r.value, r.err
+}
+
+// Batch 创建一个 JSON-RPC 批量请求构造器
+//
+// Parameters:
+//   - ctx: context.Context 用于控制 Send 发送请求的上下文
+//
+// Returns:
+//   - *Batch: 空的批量请求，通过 GetBalance、GetCode 等方法入队调用，再调用 Send 发送
+func (c *Client) Batch(ctx context.Context) *Batch {
+	return &Batch{c: c, ctx: ctx}
+}
+
+// enqueue 将调用加入批量请求，返回与之关联的结果
+//
+// Parameters:
+//   - b: *Batch 批量请求
+//   - method: string JSON-RPC 方法名
+//   - params: []any 方法参数
+//   - err: error 参数校验错误，非 nil 时调用不会发送，结果直接为该错误
+//   - decode: func(json.RawMessage) (T, error) 将节点返回的 result 解码为结果类型
+func enqueue[T any](b *Batch, method string, params []any, err error, decode func(json.RawMessage) (T, error)) *BatchResult[T] {
+	r := &BatchResult[T]{}
+	b.calls = append(b.calls, &batchCall{
+		method: method,
+		params: params,
+		err:    err,
+		done: func(result json.RawMessage, err error) {
+			if err == nil {
+				r.value, err = decode(result)
+			}
+			r.err = err
+			r.done = true
+		},
+	})
+	return r
+}
+
+// decodeJSON 将 result 解码为指定类型
+func decodeJSON[T any](method string) func(json.RawMessage) (T, error) {
+	return func(result json.RawMessage) (T, error) {
+		var v T
+		if err := json.Unmarshal(result, &v); err != nil {
+			return v, fmt.Errorf("could not decode %s result: %v", method, err)
+		}
+		return v, nil
+	}
+}
+
+// decodeQuantity 将 QUANTITY 类型的 result 解码为 *big.Int
+func decodeQuantity(method string) func(json.RawMessage) (*big.Int, error) {
+	return func(result json.RawMessage) (*big.Int, error) {
+		var q eth.Quantity
+		if err := json.Unmarshal(result, &q); err != nil {
+			return nil, fmt.Errorf("could not decode %s result: %v", method, err)
+		}
+		return new(big.Int).Set(q.Big()), nil
+	}
+}
+
+// addressAndBlock 校验地址和区块号参数，返回 JSON-RPC 参数列表
+func addressAndBlock(address, numberOrTag string) ([]any, error) {
+	addr, err := eth.NewAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
+	}
+	return []any{addr, numOrTag}, nil
+}
+
+// Request 将任意 JSON-RPC 调用加入批量请求
+//
+// Parameters:
+//   - method: string JSON-RPC 方法名
+//   - params: ...any 方法参数
+//
+// Returns:
+//   - *BatchResult[json.RawMessage]: 未解码的 result 字段
+func (b *Batch) Request(method string, params ...any) *BatchResult[json.RawMessage] {
+	return enqueue(b, method, params, nil, func(result json.RawMessage) (json.RawMessage, error) {
+		return result, nil
+	})
+}
+
+// GetBalance 将 eth_getBalance 加入批量请求
+//
+// Parameters:
+//   - address: string 要查询的账户地址
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - *BatchResult[*big.Int]: 账户余额（单位：wei）
+func (b *Batch) GetBalance(address string, numberOrTag string) *BatchResult[*big.Int] {
+	params, err := addressAndBlock(address, numberOrTag)
+	return enqueue(b, "eth_getBalance", params, err, decodeQuantity("eth_getBalance"))
+}
+
+// GetCode 将 eth_getCode 加入批量请求
+//
+// Parameters:
+//   - address: string 合约地址
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - *BatchResult[string]: 合约字节码（十六进制格式），非合约地址为 "0x"
+func (b *Batch) GetCode(address string, numberOrTag string) *BatchResult[string] {
+	params, err := addressAndBlock(address, numberOrTag)
+	return enqueue(b, "eth_getCode", params, err, decodeJSON[string]("eth_getCode"))
+}
+
+// GetTransactionCount 将 eth_getTransactionCount 加入批量请求
+//
+// Parameters:
+//   - address: string 账户地址
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - *BatchResult[uint64]: 账户的 nonce
+func (b *Batch) GetTransactionCount(address string, numberOrTag string) *BatchResult[uint64] {
+	params, err := addressAndBlock(address, numberOrTag)
+	decode := decodeQuantity("eth_getTransactionCount")
+	return enqueue(b, "eth_getTransactionCount", params, err, func(result json.RawMessage) (uint64, error) {
+		n, err := decode(result)
+		if err != nil {
+			return 0, err
+		}
+		return toUint64(n, "nonce")
+	})
+}
+
+// GetBlockByNumber 将 eth_getBlockByNumber 加入批量请求
+//
+// Parameters:
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//   - fullTx: bool 如果为true则返回完整的交易对象，否则仅返回交易哈希
+//
+// Returns:
+//   - *BatchResult[*eth.Block]: 区块信息，区块不存在时返回满足 errors.Is(err, ErrNotFound) 的错误
+func (b *Batch) GetBlockByNumber(numberOrTag string, fullTx bool) *BatchResult[*eth.Block] {
+	var params []any
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
+	} else {
+		params = []any{numOrTag, fullTx}
+	}
+	decode := decodeJSON[*eth.Block]("eth_getBlockByNumber")
+	return enqueue(b, "eth_getBlockByNumber", params, err, func(result json.RawMessage) (*eth.Block, error) {
+		block, err := decode(result)
+		if err == nil && block == nil {
+			return nil, wrapNodeError(node.ErrBlockNotFound)
+		}
+		return block, err
+	})
+}
+
+// GetTransactionReceipt 将 eth_getTransactionReceipt 加入批量请求
+//
+// Parameters:
+//   - txHash: string 交易哈希
+//
+// Returns:
+//   - *BatchResult[*eth.TransactionReceipt]: 交易收据，交易未打包或不存在时为 nil
+func (b *Batch) GetTransactionReceipt(txHash string) *BatchResult[*eth.TransactionReceipt] {
+	var params []any
+	hash, err := eth.NewHash(txHash)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	} else {
+		params = []any{hash}
+	}
+	return enqueue(b, "eth_getTransactionReceipt", params, err, decodeJSON[*eth.TransactionReceipt]("eth_getTransactionReceipt"))
+}
+
+// GetTransactionByHash 将 eth_getTransactionByHash 加入批量请求
+//
+// Parameters:
+//   - txHash: string 交易哈希
+//
+// Returns:
+//   - *BatchResult[*eth.Transaction]: 交易信息，交易不存在时返回满足 errors.Is(err, ErrNotFound) 的错误
+func (b *Batch) GetTransactionByHash(txHash string) *BatchResult[*eth.Transaction] {
+	var params []any
+	hash, err := eth.NewHash(txHash)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	} else {
+		params = []any{hash}
+	}
+	decode := decodeJSON[*eth.Transaction]("eth_getTransactionByHash")
+	return enqueue(b, "eth_getTransactionByHash", params, err, func(result json.RawMessage) (*eth.Transaction, error) {
+		tx, err := decode(result)
+		if err == nil && tx == nil {
+			return nil, wrapNodeError(node.ErrTransactionNotFound)
+		}
+		return tx, err
+	})
+}
+
+// Call 将 eth_call 加入批量请求
+//
+// Parameters:
+//   - msg: CallMsg 调用参数，To 必需
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - *BatchResult[string]: 合约执行的返回值（十六进制格式）
+func (b *Batch) Call(msg CallMsg, numberOrTag string) *BatchResult[string] {
+	var params []any
+	args, err := msg.toCallArgs(true)
+	if err == nil {
+		numOrTag, tagErr := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+		if tagErr != nil {
+			err = fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
+		} else {
+			params = []any{args, numOrTag}
+		}
+	}
+	return enqueue(b, "eth_call", params, err, decodeJSON[string]("eth_call"))
+}
+
+// Len 返回已入队的调用数量
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// Send 发送所有已入队的调用，返回后各调用的 BatchResult 可用
+//
+// Returns:
+//   - error: 批量请求已发送过，或至少有一组批量请求整体发送失败（如连接错误）时返回第一个错误；
+//     单个调用的错误不会通过 Send 返回，而是记录在对应的 BatchResult 中
+func (b *Batch) Send() error {
+	if b.sent {
+		return errors.New("batch has already been sent")
+	}
+	b.sent = true
+
+	// 参数无效的调用直接完成，不发送给节点
+	pending := make([]*batchCall, 0, len(b.calls))
+	for _, call := range b.calls {
+		if call.err != nil {
+			call.done(nil, call.err)
+			continue
+		}
+		pending = append(pending, call)
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	size := b.c.batchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	g := new(errgroup.Group)
+	g.SetLimit(batchConcurrency)
+	for start := 0; start < len(pending); start += size {
+		chunk := pending[start:min(start+size, len(pending))]
+		g.Go(func() error {
+			if err := b.c.sendBatch(b.ctx, chunk); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	return firstErr
+}
+
+// sendBatch 将一组调用作为一个批量请求发送，并把结果写回每个调用
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - calls: []*batchCall 要发送的调用，请求ID为调用在该组中的序号
+//
+// Returns:
+//   - error: 批量请求整体发送失败时返回错误，此时每个调用的结果也都是该错误
+func (c *Client) sendBatch(ctx context.Context, calls []*batchCall) error {
+	requests := make([]*jsonrpc.Request, 0, len(calls))
+	for i, call := range calls {
+		request, err := jsonrpc.MakeRequest(i, call.method, call.params...)
+		if err != nil {
+			return c.failBatch(calls, fmt.Errorf("failed to encode %s params: %v", call.method, err))
+		}
+		requests = append(requests, request)
+	}
+
+	// 包含发送交易的批量请求与 SendRawTransaction 一样只在显式开启时重试
+	idempotent := true
+	methods := make([]string, len(calls))
+	for i, call := range calls {
+		methods[i] = call.method
+		if nonIdempotentMethods[call.method] && !c.retry.RetrySendRawTransaction {
+			idempotent = false
+		}
+	}
+	result, err := c.retryConnection(ctx, idempotent, methods, func(conn node.Client) (any, error) {
+		if isHTTPURL(conn.URL()) {
+			return postBatch(ctx, conn.URL(), requests)
+		}
+		return pipelineBatch(ctx, conn, requests)
+	})
+	if err != nil {
+		return c.failBatch(calls, fmt.Errorf("batch request failed: %w", err))
+	}
+
+	// 节点返回的结果顺序不一定与请求一致，按ID匹配
+	responses := result.([]*jsonrpc.RawResponse)
+	received := make([]bool, len(calls))
+	for _, response := range responses {
+		i := response.ID.Num
+		if response.ID.IsString || i >= uint64(len(calls)) || received[i] {
+			continue
+		}
+		received[i] = true
+		if response.Error != nil {
+			calls[i].done(nil, newRPCError(*response.Error))
+			continue
+		}
+		calls[i].done(response.Result, nil)
+	}
+	for i, ok := range received {
+		if !ok {
+			calls[i].done(nil, fmt.Errorf("no response for %s in batch", calls[i].method))
+		}
+	}
+	return nil
+}
+
+// failBatch 将一组调用的结果都设置为同一个错误
+func (c *Client) failBatch(calls []*batchCall, err error) error {
+	for _, call := range calls {
+		call.done(nil, err)
+	}
+	return err
+}
+
+// postBatch 通过 HTTP 发送 JSON-RPC 批量请求
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - url: string 节点URL
+//   - requests: []*jsonrpc.Request 批量请求中的调用
+//
+// Returns:
+//   - []*jsonrpc.RawResponse: 节点返回的响应，顺序可能与请求不同
+//   - error: 可能的错误：
+//   - 网络错误或非200状态码
+//   - 节点不支持批量请求，返回了单个错误响应
+func postBatch(ctx context.Context, url string, requests []*jsonrpc.Request) ([]*jsonrpc.RawResponse, error) {
+	body, err := json.Marshal(jsonrpc.BatchRequest(requests))
+	if err != nil {
+		return nil, fmt.Errorf("could not encode batch request: %v", err)
+	}
+
+	data, err := postJSON(ctx, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*jsonrpc.RawResponse
+	if err := json.Unmarshal(data, &responses); err != nil {
+		// 不支持批量请求或批量过大的节点会返回单个错误响应
+		var single jsonrpc.RawResponse
+		if json.Unmarshal(data, &single) == nil && single.Error != nil {
+			return nil, newRPCError(*single.Error)
+		}
+		return nil, fmt.Errorf("could not decode batch response: %v", err)
+	}
+	return responses, nil
+}
+
+// pipelineBatch 在同一个双向连接上并发发送多个请求，websocket 连接按请求ID分发响应
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - conn: node.Client 节点连接
+//   - requests: []*jsonrpc.Request 批量请求中的调用
+//
+// Returns:
+//   - []*jsonrpc.RawResponse: 与请求顺序一致的响应
+//   - error: 任一请求发送失败（连接错误）时返回错误
+func pipelineBatch(ctx context.Context, conn node.Client, requests []*jsonrpc.Request) ([]*jsonrpc.RawResponse, error) {
+	responses := make([]*jsonrpc.RawResponse, len(requests))
+	g, ctx := errgroup.WithContext(ctx)
+	for i, request := range requests {
+		g.Go(func() error {
+			response, err := conn.Request(ctx, request)
+			if err != nil {
+				return err
+			}
+			// 部分实现不会回传请求ID，以请求为准
+			response.ID = request.ID
+			responses[i] = response
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
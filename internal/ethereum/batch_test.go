@@ -0,0 +1,142 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// balanceRequester 按地址返回余额的模拟节点，balances 中不存在的地址返回 JSON-RPC 错误
+func balanceRequester(balances map[string]string) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		var params []string
+		b, _ := json.Marshal(r.Params)
+		if err := json.Unmarshal(b, &params); err != nil || r.Method != "eth_getBalance" {
+			return nil, fmt.Errorf("unexpected request %s", r.Method)
+		}
+		balance, ok := balances[params[0]]
+		if !ok {
+			rpcErr := json.RawMessage(`{"code":-32000,"message":"header not found"}`)
+			return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(`"` + balance + `"`)}, nil
+	}
+}
+
+func TestBatchPipeline(t *testing.T) {
+	addr1 := "0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae"
+	addr2 := "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": balanceRequester(map[string]string{addr1: "0x8ac7230489e80000"}),
+	}, Endpoint{URL: "ws://node"})
+
+	batch := c.Batch(context.Background())
+	ok := batch.GetBalance(addr1, "latest")
+	failed := batch.GetBalance(addr2, "latest")
+	invalid := batch.GetBalance("0x123", "latest")
+	assert.Equal(t, 3, batch.Len())
+
+	_, err := ok.Result()
+	assert.ErrorIs(t, err, errBatchNotSent)
+
+	assert.NoError(t, batch.Send())
+	assert.Error(t, batch.Send(), "批量请求不能重复发送")
+
+	balance, err := ok.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "10000000000000000000", balance.String())
+
+	_, err = failed.Result()
+	assert.ErrorContains(t, err, "header not found")
+
+	_, err = invalid.Result()
+	assert.ErrorContains(t, err, "invalid ethereum address")
+}
+
+func TestGetBalancesBigPartialFailure(t *testing.T) {
+	addr1 := "0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae"
+	addr2 := "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": balanceRequester(map[string]string{addr1: "0x1", addr2: "0x2"}),
+	}, Endpoint{URL: "ws://node"})
+
+	balances, err := c.GetBalancesBig(context.Background(), []string{addr1, addr2, addr1, "bad"}, "latest")
+	var batchErr *BatchError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, 3, batchErr.Total)
+	assert.Contains(t, batchErr.Errors, "bad")
+	assert.ErrorIs(t, err, ErrInvalidAddress, "应能匹配单个调用的错误")
+	assert.Equal(t, map[string]*big.Int{addr1: big.NewInt(1), addr2: big.NewInt(2)}, balances)
+
+	_, err = c.GetBalancesBig(context.Background(), []string{addr1, addr2}, "latest", 1)
+	assert.ErrorContains(t, err, "too many addresses")
+}
+
+func TestBatchHTTP(t *testing.T) {
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+		var requests []jsonrpc.Request
+		if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		assert.LessOrEqual(t, len(requests), 2, "超过 BatchSize 的调用应拆分为多个批量请求")
+
+		// 以与请求相反的顺序返回，验证按ID匹配结果
+		responses := make([]jsonrpc.RawResponse, 0, len(requests))
+		for i := len(requests) - 1; i >= 0; i-- {
+			var params []string
+			b, _ := json.Marshal(requests[i].Params)
+			json.Unmarshal(b, &params)
+			result := fmt.Sprintf(`"0x%s"`, params[0][len(params[0])-1:])
+			responses = append(responses, jsonrpc.RawResponse{JSONRPC: "2.0", ID: requests[i].ID, Result: []byte(result)})
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(context.Background(), srv.URL, &ClientOptions{BatchSize: 2})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	addresses := []string{
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000002",
+		"0x0000000000000000000000000000000000000003",
+		"0x0000000000000000000000000000000000000004",
+		"0x0000000000000000000000000000000000000005",
+	}
+	balances, err := c.GetBalancesBig(context.Background(), addresses, "latest")
+	assert.NoError(t, err)
+	for i, addr := range addresses {
+		assert.Equal(t, int64(i+1), balances[addr].Int64(), addr)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&posts))
+}
+
+func TestBatchHTTPUnsupported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch requests are not supported"}}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(context.Background(), srv.URL, nil)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	batch := c.Batch(context.Background())
+	code := batch.GetCode("0x0000000000000000000000000000000000000001", "latest")
+	assert.ErrorContains(t, batch.Send(), "batch requests are not supported")
+	_, err = code.Result()
+	assert.ErrorContains(t, err, "batch requests are not supported")
+}
@@ -18,7 +18,7 @@ import (
 //   - 节点连接错误
 //   - 请求执行错误
 func (c *Client) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_blockNumber", func(conn node.Client) (any, error) {
 		return conn.BlockNumber(ctx)
 	})
 	if err != nil {
@@ -39,7 +39,7 @@ func (c *Client) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
 //   - 无效的区块哈希格式
 //   - 节点连接错误
 func (c *Client) GetBlockTransactionCountByHash(ctx context.Context, blockHash string) (uint64, error) {
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getBlockTransactionCountByHash", func(conn node.Client) (any, error) {
 		return conn.GetBlockTransactionCountByHash(ctx, blockHash)
 	})
 	if err != nil {
@@ -65,13 +65,13 @@ func (c *Client) GetBlockTransactionCountByHash(ctx context.Context, blockHash s
 //   - 节点连接错误
 func (c *Client) GetBlockTransactionCountByNumber(ctx context.Context, numberOrTag string) (uint64, error) {
 	// 处理默认值并验证区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return 0, fmt.Errorf("invalid block number or tag: %s", numberOrTag)
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getBlockTransactionCountByNumber", func(conn node.Client) (any, error) {
 		return conn.GetBlockTransactionCountByNumber(ctx, *numOrTag)
 	})
 	if err != nil {
@@ -96,11 +96,11 @@ func (c *Client) GetBlockTransactionCountByNumber(ctx context.Context, numberOrT
 func (c *Client) GetBlockByHash(ctx context.Context, blockHash string, fullTx bool) (*eth.Block, error) {
 	// 验证区块哈希格式
 	if len(blockHash) < 2 || blockHash[:2] != "0x" {
-		return nil, fmt.Errorf("invalid block hash format: must be hex string starting with 0x")
+		return nil, fmt.Errorf("%w: block hash must be a hex string starting with 0x", ErrInvalidHash)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getBlockByHash", func(conn node.Client) (any, error) {
 		return conn.BlockByHash(ctx, blockHash, fullTx)
 	})
 	if err != nil {
@@ -129,13 +129,13 @@ func (c *Client) GetBlockByHash(ctx context.Context, blockHash string, fullTx bo
 //   - 区块不存在
 func (c *Client) GetBlockByNumber(ctx context.Context, numberOrTag string, fullTx bool) (*eth.Block, error) {
 	// 处理默认值并验证区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return nil, fmt.Errorf("invalid block number or tag: %s", numberOrTag)
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getBlockByNumber", func(conn node.Client) (any, error) {
 		return conn.BlockByNumber(ctx, *numOrTag, fullTx)
 	})
 	if err != nil {
@@ -160,11 +160,11 @@ func (c *Client) GetBlockByNumber(ctx context.Context, numberOrTag string, fullT
 func (c *Client) GetUncleByBlockHashAndIndex(ctx context.Context, blockHash string, index uint64) (*eth.Block, error) {
 	// 验证区块哈希格式
 	if len(blockHash) < 2 || blockHash[:2] != "0x" {
-		return nil, fmt.Errorf("invalid block hash format: must be hex string starting with 0x")
+		return nil, fmt.Errorf("%w: block hash must be a hex string starting with 0x", ErrInvalidHash)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getUncleByBlockHashAndIndex", func(conn node.Client) (any, error) {
 		return conn.GetUncleByBlockHashAndIndex(ctx, blockHash, index)
 	})
 	if err != nil {
@@ -193,13 +193,13 @@ func (c *Client) GetUncleByBlockHashAndIndex(ctx context.Context, blockHash stri
 //   - 叔块不存在
 func (c *Client) GetUncleByBlockNumberAndIndex(ctx context.Context, numberOrTag string, index uint64) (*eth.Block, error) {
 	// 处理默认值并验证区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return nil, fmt.Errorf("invalid block number or tag: %s", numberOrTag)
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getUncleByBlockNumberAndIndex", func(conn node.Client) (any, error) {
 		return conn.GetUncleByBlockNumberAndIndex(ctx, *numOrTag, index)
 	})
 	if err != nil {
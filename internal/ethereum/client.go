@@ -7,10 +7,13 @@ package ethereum
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"sync"
+	"math/big"
 
 	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
 	"github.com/justinwongcn/go-ethlibs/node"
 )
 
@@ -25,64 +28,233 @@ import (
 //   - *Client: 初始化后的以太坊客户端实例
 //   - error: 可能的错误
 func NewClient(ctx context.Context, nodeURL string, opts *ClientOptions) (*Client, error) {
-	client, err := node.NewClient(ctx, nodeURL)
-	if err != nil {
-		return nil, err
-	}
+	return NewMultiClient(ctx, []Endpoint{{URL: nodeURL}}, opts)
+}
 
+// NewMultiClient 创建一个连接多个节点端点的以太坊客户端
+//
+// Parameters:
+//   - ctx: context.Context 用于控制客户端生命周期的上下文
+//   - endpoints: []Endpoint 节点端点列表，请求优先路由到优先级最高的健康端点
+//   - opts: *ClientOptions 客户端配置选项，如果为 nil 则使用默认配置
+//
+// Returns:
+//   - *Client: 初始化后的以太坊客户端实例
+//   - error: 可能的错误：
+//   - 端点列表为空
+//   - 所有端点都无法连接
+//
+// 端点连续失败 MaxFailures 次后被标记为不健康，请求会自动转移到其他端点；
+// 不健康的端点按 HealthCheckInterval 间隔重新探测，恢复后重新参与路由。
+func NewMultiClient(ctx context.Context, endpoints []Endpoint, opts *ClientOptions) (*Client, error) {
 	if opts == nil {
 		opts = DefaultClientOptions()
 	}
 
+	// 修正无效的连接池配置
+	defaults := DefaultClientOptions()
+	maxConns := opts.MaxConns
+	if maxConns <= 0 {
+		maxConns = defaults.MaxConns
+	}
+	idleTimeout := opts.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = defaults.IdleTimeout
+	}
+	maxIdleConns := opts.MaxIdleConns
+	if maxIdleConns < 0 {
+		maxIdleConns = 0
+	}
+	healthCheckInterval := opts.HealthCheckInterval
+	if healthCheckInterval <= 0 {
+		healthCheckInterval = defaults.HealthCheckInterval
+	}
+	maxFailures := opts.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = defaults.MaxFailures
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaults.BatchSize
+	}
+	rateLimit := opts.RateLimit
+	retry := opts.Retry
+	if retry.InitialBackoff < 0 {
+		retry.InitialBackoff = 0
+	}
+	if retry.MaxBackoff < retry.InitialBackoff {
+		retry.MaxBackoff = retry.InitialBackoff
+	}
+
 	// 初始化连接池配置
+	clientCtx, cancel := context.WithCancel(ctx)
 	c := &Client{
-		nodeClient:   client,
-		nodeURL:      nodeURL,
-		maxConns:     opts.MaxConns,
-		idleTimeout:  opts.IdleTimeout,
-		healthCheck:  opts.HealthCheck,
-		maxIdleConns: opts.MaxIdleConns,
-	}
-
-	// 初始化连接池
-	c.connPool = &sync.Pool{
-		New: func() any {
-			// 创建新的节点客户端连接
-			newClient, err := node.NewClient(ctx, nodeURL)
-			if err != nil {
-				return nil
+		ctx:                 clientCtx,
+		cancel:              cancel,
+		maxConns:            maxConns,
+		idleTimeout:         idleTimeout,
+		healthCheck:         opts.HealthCheck,
+		maxIdleConns:        maxIdleConns,
+		healthCheckInterval: healthCheckInterval,
+		maxFailures:         maxFailures,
+		chainID:             opts.ChainID,
+		batchSize:           batchSize,
+		retry:               retry,
+		rateLimit:           rateLimit,
+		logger:              opts.Logger,
+	}
+
+	// 初始化各端点的连接池
+	eps, err := newEndpoints(c, endpoints)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c.endpoints = eps
+
+	// 为每个端点建立第一个连接以验证节点可用：无法连接的端点标记为不健康，由健康检查重新探测并校验链ID；
+	// 链ID不匹配的端点永久排除。所有端点都不可用时返回错误
+	var lastErr error
+	available := 0
+	for _, ep := range c.endpoints {
+		conn, err := ep.pool.get(ctx)
+		if err != nil {
+			ep.recordFailure(err, 1)
+			lastErr = err
+			continue
+		}
+		if err := c.verifyChainID(ctx, conn.Client); err != nil {
+			ep.pool.discard(conn)
+			ep.pool.put(conn)
+			if errors.Is(err, errChainIDMismatch) {
+				ep.markWrongChain(err)
+			} else {
+				ep.recordFailure(err, 1)
 			}
-			return newClient
-		},
+			lastErr = fmt.Errorf("endpoint %s: %w", ep.url, err)
+			continue
+		}
+		ep.markVerified()
+		ep.pool.put(conn)
+		available++
+	}
+	if available == 0 {
+		cancel()
+		return nil, lastErr
 	}
 
 	// 启动连接池管理协程
-	go c.managePool(ctx)
+	go c.managePool(clientCtx)
 
 	return c, nil
 }
 
+// errChainIDMismatch 节点的链ID与 ClientOptions.ChainID 不一致
+var errChainIDMismatch = errors.New("chain id mismatch")
+
+// verifyChainID 校验连接所属节点的链ID是否与配置一致
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - conn: node.Client 要校验的连接
+//
+// Returns:
+//   - error: 未配置链ID时返回 nil；查询失败或链ID不匹配（errChainIDMismatch）时返回错误
+func (c *Client) verifyChainID(ctx context.Context, conn node.Client) error {
+	if c.chainID == 0 {
+		return nil
+	}
+
+	id, err := conn.ChainId(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %v", err)
+	}
+	q, err := eth.NewQuantity(id)
+	if err != nil {
+		return fmt.Errorf("invalid chain id %q: %v", id, err)
+	}
+	if q.UInt64() != c.chainID {
+		return fmt.Errorf("%w: expected %d, got %d", errChainIDMismatch, c.chainID, q.UInt64())
+	}
+	return nil
+}
+
+// Close 关闭客户端，释放所有连接
+//
+// 正在执行的请求所使用的连接会在请求结束后关闭，之后的请求将返回错误。
+func (c *Client) Close() {
+	c.cancel()
+	for _, ep := range c.endpoints {
+		ep.pool.close()
+	}
+}
+
+// logWarn 记录后台任务中无法返回给调用方的错误，未配置 ClientOptions.Logger 时不记录
+func (c *Client) logWarn(msg string, args ...any) {
+	if c.logger != nil {
+		c.logger.Warn(msg, args...)
+	}
+}
+
 // withConnection 在连接池中执行操作的通用辅助函数
 //
 // Parameters:
 //   - ctx: context.Context 用于控制请求的上下文
+//   - method: string 操作发送的 JSON-RPC 方法，用于计算限流消耗的令牌
 //   - fn: func(node.Client) (any, error) 在连接上执行的操作函数，返回值会被类型断言为具体类型
 //
 // Returns:
 //   - any: 操作的返回值，需要由调用者进行类型断言
 //   - error: 可能的错误：
 //   - 获取连接失败
+//   - 等待限流令牌时上下文结束
 //   - 操作执行失败
-func (c *Client) withConnection(ctx context.Context, fn func(node.Client) (any, error)) (any, error) {
-	// 从连接池获取连接
-	conn, err := c.getConnection(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get connection: %v", err)
+//
+// 操作被视为幂等的读请求，限流、超时和连接错误按 ClientOptions.Retry 的策略重试。
+func (c *Client) withConnection(ctx context.Context, method string, fn func(node.Client) (any, error)) (any, error) {
+	return c.retryConnection(ctx, true, []string{method}, fn)
+}
+
+// tryEndpoints 在连接池中执行一次操作
+//
+// 请求依次尝试健康的端点，无法建立连接时立即转移到下一个端点；
+// 发送前在端点的令牌桶中等待令牌，端点以 Retry-After 应答时暂停该端点，暂停期间优先使用其他端点；
+// 操作失败时仅在错误由端点引起（连接断开、超时、限流等）时计入该端点的失败次数。
+func (c *Client) tryEndpoints(ctx context.Context, methods []string, fn func(node.Client) (any, error)) (any, error) {
+	lastErr := errors.New("no endpoint with verified chain id")
+	for _, ep := range c.pickEndpoints() {
+		if err := ep.limiter.wait(ctx, methods); err != nil {
+			return nil, err
+		}
+
+		// 从连接池获取连接
+		conn, err := ep.pool.get(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to get connection: %w", err)
+			}
+			ep.recordFailure(err, c.maxFailures)
+			lastErr = err
+			continue
+		}
+
+		// 执行操作
+		result, err := fn(conn.Client)
+		if d := retryAfter(err); d > 0 {
+			ep.limiter.pause(d)
+		}
+		if isEndpointError(ctx, err) {
+			// 连接可能已失效，不再复用
+			ep.pool.discard(conn)
+			ep.recordFailure(err, c.maxFailures)
+		} else {
+			ep.recordSuccess()
+		}
+		ep.pool.put(conn)
+		return result, wrapNodeError(err)
 	}
-	defer c.releaseConnection(conn)
 
-	// 执行操作
-	return fn(conn)
+	return nil, fmt.Errorf("failed to get connection: %w", lastErr)
 }
 
 // getDefaultNumberOrTag 处理区块号或标签的默认值
@@ -104,6 +276,80 @@ func getDefaultNumberOrTag(numberOrTag string) string {
 	return numberOrTag
 }
 
+// ChainID 获取节点的链ID
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//
+// Returns:
+//   - uint64: 链ID（如主网为1）
+//   - error: 可能的错误：
+//   - 节点连接错误
+//   - 节点返回的链ID格式无效
+func (c *Client) ChainID(ctx context.Context) (uint64, error) {
+	result, err := c.withConnection(ctx, "eth_chainId", func(conn node.Client) (any, error) {
+		return conn.ChainId(ctx)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	q, err := eth.NewQuantity(result.(string))
+	if err != nil {
+		return 0, fmt.Errorf("invalid chain id: %v", err)
+	}
+	return q.UInt64(), nil
+}
+
+// rawRequest 在连接池中发送任意 JSON-RPC 请求，返回未解码的结果
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - method: string JSON-RPC 方法名
+//   - params: ...any 方法参数，按顺序编码为 JSON 数组
+//
+// Returns:
+//   - json.RawMessage: 未解码的 result 字段
+//   - error: 可能的错误：
+//   - 参数编码失败
+//   - 节点连接错误
+//   - 节点返回的 JSON-RPC 错误
+func (c *Client) rawRequest(ctx context.Context, method string, params ...any) (json.RawMessage, error) {
+	request, err := jsonrpc.MakeRequest(1, method, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s params: %v", method, err)
+	}
+
+	result, err := c.withConnection(ctx, method, func(conn node.Client) (any, error) {
+		response, err := conn.Request(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		if response.Error != nil {
+			return nil, newRPCError(*response.Error)
+		}
+		return response.Result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(json.RawMessage), nil
+}
+
+// requestQuantity 发送返回值为 QUANTITY 的 JSON-RPC 请求，并解码为 *big.Int
+func (c *Client) requestQuantity(ctx context.Context, method string, params ...any) (*big.Int, error) {
+	result, err := c.rawRequest(ctx, method, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var q eth.Quantity
+	if err := json.Unmarshal(result, &q); err != nil {
+		return nil, fmt.Errorf("could not decode %s result: %v", method, err)
+	}
+	return new(big.Int).Set(q.Big()), nil
+}
+
 // GasPrice 获取当前 gas 价格
 //
 // Parameters:
@@ -111,15 +357,102 @@ func getDefaultNumberOrTag(numberOrTag string) string {
 //
 // Returns:
 //   - uint64: 当前 gas 价格（单位：wei）
-//   - error: 操作过程中可能发生的错误
+//   - error: 操作过程中可能发生的错误，价格超出 uint64 范围时返回错误，请改用 GasPriceBig
 func (c *Client) GasPrice(ctx context.Context) (uint64, error) {
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
-		return conn.GasPrice(ctx)
-	})
+	price, err := c.GasPriceBig(ctx)
 	if err != nil {
 		return 0, err
 	}
-	return result.(uint64), nil
+	return toUint64(price, "gas price")
+}
+
+// GasPriceBig 获取当前 gas 价格
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//
+// Returns:
+//   - *big.Int: 当前 gas 价格（单位：wei）
+//   - error: 操作过程中可能发生的错误
+func (c *Client) GasPriceBig(ctx context.Context) (*big.Int, error) {
+	return c.requestQuantity(ctx, "eth_gasPrice")
+}
+
+// callArgs eth_call 和 eth_estimateGas 的调用对象，未设置的字段不会发送给节点
+type callArgs struct {
+	From                 *eth.Address  `json:"from,omitempty"`
+	To                   *eth.Address  `json:"to,omitempty"`
+	Gas                  *eth.Quantity `json:"gas,omitempty"`
+	GasPrice             *eth.Quantity `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *eth.Quantity `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *eth.Quantity `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *eth.Quantity `json:"value,omitempty"`
+	Data                 *eth.Data     `json:"data,omitempty"`
+}
+
+// toCallArgs 校验 CallMsg 并转换为节点接受的调用对象
+//
+// Parameters:
+//   - requireTo: bool 是否要求提供接收方地址
+func (m *CallMsg) toCallArgs(requireTo bool) (*callArgs, error) {
+	args := &callArgs{}
+
+	// 验证接收方地址格式
+	if m.To != "" {
+		addr, err := eth.NewAddress(m.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to address: %w: %v", ErrInvalidAddress, err)
+		}
+		args.To = addr
+	} else if requireTo {
+		return nil, fmt.Errorf("invalid to address: %w: must not be empty", ErrInvalidAddress)
+	}
+
+	// 验证发送方地址格式（如果提供）
+	if m.From != "" {
+		addr, err := eth.NewAddress(m.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from address: %w: %v", ErrInvalidAddress, err)
+		}
+		args.From = addr
+	}
+
+	if m.Data != "" {
+		data, err := eth.NewData(m.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data: %v", err)
+		}
+		args.Data = data
+	}
+
+	if m.Gas > 0 {
+		q := eth.QuantityFromUInt64(m.Gas)
+		args.Gas = &q
+	}
+	quantity := func(name string, v *big.Int) (*eth.Quantity, error) {
+		if v == nil {
+			return nil, nil
+		}
+		if v.Sign() < 0 {
+			return nil, fmt.Errorf("invalid %s: must not be negative", name)
+		}
+		q := eth.QuantityFromBigInt(v)
+		return &q, nil
+	}
+	var err error
+	if args.GasPrice, err = quantity("gas price", m.GasPrice); err != nil {
+		return nil, err
+	}
+	if args.MaxFeePerGas, err = quantity("max fee per gas", m.MaxFeePerGas); err != nil {
+		return nil, err
+	}
+	if args.MaxPriorityFeePerGas, err = quantity("max priority fee per gas", m.MaxPriorityFeePerGas); err != nil {
+		return nil, err
+	}
+	if args.Value, err = quantity("value", m.Value); err != nil {
+		return nil, err
+	}
+	return args, nil
 }
 
 // Call 执行以太坊智能合约的只读调用
@@ -144,50 +477,58 @@ func (c *Client) GasPrice(ctx context.Context) (uint64, error) {
 //   - 无效的地址格式
 //   - 无效的区块号格式
 //   - 节点连接错误
+//
+// 金额超过 uint64 范围（约18.4 ether）时请使用 CallWithMsg。
 func (c *Client) Call(ctx context.Context, from, to string, gas, gasPrice, value uint64, data string, numberOrTag string) (string, error) {
-	// 验证接收方地址格式
-	toAddr, err := eth.NewAddress(to)
-	if err != nil {
-		return "", fmt.Errorf("invalid to address: %v", err)
-	}
-
-	// 验证发送方地址格式（如果提供）
-	var fromAddr *eth.Address
-	if from != "" {
-		addr, err := eth.NewAddress(from)
-		if err != nil {
-			return "", fmt.Errorf("invalid from address: %v", err)
-		}
-		fromAddr = addr
+	msg := CallMsg{From: from, To: to, Gas: gas, Data: data}
+	if gasPrice > 0 {
+		msg.GasPrice = new(big.Int).SetUint64(gasPrice)
 	}
-
-	// 处理默认值并验证区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return "", fmt.Errorf("invalid block number or tag: %s", numberOrTag)
+	if value > 0 {
+		msg.Value = new(big.Int).SetUint64(value)
 	}
+	return c.CallWithMsg(ctx, msg, numberOrTag)
+}
 
-	// 创建交易对象
-	tx := eth.Transaction{
-		To:       toAddr,
-		Gas:      *eth.MustQuantity(fmt.Sprintf("0x%x", gas)),
-		GasPrice: eth.MustQuantity(fmt.Sprintf("0x%x", gasPrice)),
-		Value:    *eth.MustQuantity(fmt.Sprintf("0x%x", value)),
-		Input:    eth.Input(data),
+// CallWithMsg 执行以太坊智能合约的只读调用
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - msg: CallMsg 调用参数，To 必需
+//   - numberOrTag: string 区块号或标签，可以是以下格式：
+//   - 十六进制字符串（如"0x1"）表示具体区块号
+//   - "latest" - 最新区块（默认）
+//   - "earliest" - 创世区块
+//   - "pending" - 待处理区块
+//
+// Returns:
+//   - string: 合约执行的返回值（十六进制格式）
+//   - error: 可能的错误：
+//   - 无效的地址、数据或金额
+//   - 无效的区块号格式
+//   - 节点连接错误
+func (c *Client) CallWithMsg(ctx context.Context, msg CallMsg, numberOrTag string) (string, error) {
+	args, err := msg.toCallArgs(true)
+	if err != nil {
+		return "", err
 	}
 
-	if fromAddr != nil {
-		tx.From = *fromAddr
+	// 处理默认值并验证区块号格式
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
 
-	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
-		return conn.Call(ctx, tx, *numOrTag)
-	})
+	result, err := c.rawRequest(ctx, "eth_call", args, numOrTag)
 	if err != nil {
 		return "", err
 	}
-	return result.(string), nil
+
+	var out string
+	if err := json.Unmarshal(result, &out); err != nil {
+		return "", fmt.Errorf("could not decode eth_call result: %v", err)
+	}
+	return out, nil
 }
 
 // EstimateGas 估算交易所需的gas数量
@@ -206,54 +547,39 @@ func (c *Client) Call(ctx context.Context, from, to string, gas, gasPrice, value
 //   - error: 可能的错误：
 //   - 无效的地址格式
 //   - 节点连接错误
+//
+// 金额超过 uint64 范围（约18.4 ether）时请使用 EstimateGasWithMsg。
 func (c *Client) EstimateGas(ctx context.Context, from, to string, gas, gasPrice, value uint64, data string) (uint64, error) {
-	var fromAddr, toAddr *eth.Address
-	var err error
-
-	if from != "" {
-		addr, err := eth.NewAddress(from)
-		if err != nil {
-			return 0, fmt.Errorf("invalid from address: %v", err)
-		}
-		fromAddr = addr
-	}
-
-	if to != "" {
-		addr, err := eth.NewAddress(to)
-		if err != nil {
-			return 0, fmt.Errorf("invalid to address: %v", err)
-		}
-		toAddr = addr
-	}
-
-	// 创建交易对象
-	tx := eth.Transaction{
-		Input: eth.Input(data),
-	}
-
-	// 设置可选参数
-	if fromAddr != nil {
-		tx.From = *fromAddr
-	}
-	if toAddr != nil {
-		tx.To = toAddr
-	}
-	if gas > 0 {
-		tx.Gas = *eth.MustQuantity(fmt.Sprintf("0x%x", gas))
-	}
+	msg := CallMsg{From: from, To: to, Gas: gas, Data: data}
 	if gasPrice > 0 {
-		tx.GasPrice = eth.MustQuantity(fmt.Sprintf("0x%x", gasPrice))
+		msg.GasPrice = new(big.Int).SetUint64(gasPrice)
 	}
 	if value > 0 {
-		tx.Value = *eth.MustQuantity(fmt.Sprintf("0x%x", value))
+		msg.Value = new(big.Int).SetUint64(value)
+	}
+	return c.EstimateGasWithMsg(ctx, msg)
+}
+
+// EstimateGasWithMsg 估算交易所需的gas数量
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - msg: CallMsg 交易参数，To 为空时估算合约部署
+//
+// Returns:
+//   - uint64: 预估的gas数量，注意：返回的估算值可能会显著高于实际使用量
+//   - error: 可能的错误：
+//   - 无效的地址、数据或金额
+//   - 节点连接错误
+func (c *Client) EstimateGasWithMsg(ctx context.Context, msg CallMsg) (uint64, error) {
+	args, err := msg.toCallArgs(false)
+	if err != nil {
+		return 0, err
 	}
 
-	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
-		return conn.EstimateGas(ctx, tx)
-	})
+	gas, err := c.requestQuantity(ctx, "eth_estimateGas", args)
 	if err != nil {
 		return 0, err
 	}
-	return result.(uint64), nil
+	return toUint64(gas, "gas estimate")
 }
@@ -1,14 +1,17 @@
 package ethereum
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
+	"encoding/json"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -119,3 +122,59 @@ func TestCreateRawTransactionWithCustomData(t *testing.T) {
 	// 验证自定义数据
 	assert.Equal(t, data, signedTx.Data(), "交易数据不匹配")
 }
+
+func TestGetBalanceBig(t *testing.T) {
+	// 100000 ETH，超出 uint64 范围
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+			assert.Equal(t, "eth_getBalance", r.Method)
+			return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(`"0x152d02c7e14af6800000"`)}, nil
+		},
+	}, Endpoint{URL: "ws://node"})
+
+	address := "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
+	balance, err := c.GetBalanceBig(context.Background(), address, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "100000", FormatEther(balance))
+
+	// uint64 版本应返回错误而不是截断
+	_, err = c.GetBalance(context.Background(), address, "")
+	assert.ErrorContains(t, err, "overflows uint64")
+}
+
+func TestCallWithMsg(t *testing.T) {
+	var params []any
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+			assert.Equal(t, "eth_call", r.Method)
+			b, err := json.Marshal(r.Params)
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(b, &params))
+			return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(`"0x01"`)}, nil
+		},
+	}, Endpoint{URL: "ws://node"})
+
+	value, _ := ParseEther("20")
+	result, err := c.CallWithMsg(context.Background(), CallMsg{
+		To:    "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d",
+		Value: value,
+		Data:  "0x70a08231",
+	}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x01", result)
+
+	// 未设置的字段不应发送给节点
+	assert.Equal(t, []any{
+		map[string]any{
+			"to":    "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d",
+			"value": "0x1158e460913d00000",
+			"data":  "0x70a08231",
+		},
+		"latest",
+	}, params)
+
+	_, err = c.CallWithMsg(context.Background(), CallMsg{To: "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"}, "invalid")
+	assert.ErrorContains(t, err, "invalid block number or tag")
+	_, err = c.CallWithMsg(context.Background(), CallMsg{}, "")
+	assert.ErrorContains(t, err, "invalid to address")
+}
@@ -0,0 +1,322 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/node"
+)
+
+// Endpoint 描述一个以太坊节点端点
+type Endpoint struct {
+	URL      string // 节点URL，支持 http(s)、ws(s) 和 IPC 路径
+	Priority int    // 优先级，数值越小越优先；优先级相同的端点之间轮询
+	// RateLimit 端点单独的限流配置，为 nil 时使用 ClientOptions.RateLimit
+	RateLimit *RateLimit
+}
+
+// EndpointStatus 端点的健康状态快照
+type EndpointStatus struct {
+	URL         string    // 节点URL
+	Priority    int       // 优先级
+	Healthy     bool      // 是否健康
+	WrongChain  bool      // 链ID与 ClientOptions.ChainID 不一致，永久不参与路由
+	Failures    int       // 连续失败次数
+	LastError   string    // 最近一次失败的错误信息
+	LastChecked time.Time // 最近一次状态变化或探测的时间
+	ActiveConns int       // 当前借出的连接数
+	IdleConns   int       // 当前空闲连接数
+}
+
+// endpoint 客户端内部的端点状态，每个端点拥有独立的连接池
+type endpoint struct {
+	url      string
+	priority int
+	pool     *connPool
+	limiter  *rateLimiter
+
+	mu          sync.Mutex
+	healthy     bool
+	verified    bool // 链ID已校验一致，或未配置 ChainID
+	wrongChain  bool // 链ID不一致，永久排除
+	failures    int
+	lastErr     error
+	lastChecked time.Time
+}
+
+// recordSuccess 记录一次成功请求，重置连续失败计数
+func (e *endpoint) recordSuccess() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failures = 0
+	if !e.healthy {
+		e.healthy = true
+		e.lastChecked = time.Now()
+	}
+}
+
+// recordFailure 记录一次端点故障
+//
+// Parameters:
+//   - err: error 故障原因
+//   - maxFailures: int 连续失败达到该次数后将端点标记为不健康
+//
+// 端点被标记为不健康时会关闭其所有空闲连接。
+func (e *endpoint) recordFailure(err error, maxFailures int) {
+	e.mu.Lock()
+	e.failures++
+	e.lastErr = err
+	e.lastChecked = time.Now()
+	markDown := e.healthy && e.failures >= maxFailures
+	if markDown {
+		e.healthy = false
+	}
+	e.mu.Unlock()
+
+	if markDown {
+		e.pool.evictAll()
+	}
+}
+
+// markVerified 记录端点的链ID已校验一致
+func (e *endpoint) markVerified() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.verified = true
+}
+
+// markWrongChain 将链ID不一致的端点永久排除，关闭其所有空闲连接
+func (e *endpoint) markWrongChain(err error) {
+	e.mu.Lock()
+	e.healthy = false
+	e.wrongChain = true
+	e.lastErr = err
+	e.lastChecked = time.Now()
+	e.mu.Unlock()
+
+	e.pool.evictAll()
+}
+
+// usable 返回端点能否参与路由：链ID不一致或尚未校验的端点不能使用
+func (e *endpoint) usable() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.verified && !e.wrongChain
+}
+
+// isHealthy 返回端点当前是否健康
+func (e *endpoint) isHealthy() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.healthy
+}
+
+// status 返回端点的状态快照
+func (e *endpoint) status() EndpointStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := EndpointStatus{
+		URL:         e.url,
+		Priority:    e.priority,
+		Healthy:     e.healthy,
+		WrongChain:  e.wrongChain,
+		Failures:    e.failures,
+		LastChecked: e.lastChecked,
+		ActiveConns: e.pool.activeCount(),
+		IdleConns:   e.pool.idleCount(),
+	}
+	if e.lastErr != nil {
+		s.LastError = e.lastErr.Error()
+	}
+	return s
+}
+
+// newEndpoints 根据配置创建端点列表，按优先级升序排列
+//
+// Parameters:
+//   - c: *Client 所属客户端，用于创建连接
+//   - endpoints: []Endpoint 端点配置
+//
+// Returns:
+//   - []*endpoint: 初始化后的端点列表，初始状态均为健康
+//   - error: 端点列表为空或包含空URL时返回错误
+func newEndpoints(c *Client, endpoints []Endpoint) ([]*endpoint, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("at least one endpoint is required")
+	}
+
+	eps := make([]*endpoint, 0, len(endpoints))
+	for _, cfg := range endpoints {
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("endpoint url cannot be empty")
+		}
+		limit := c.rateLimit
+		if cfg.RateLimit != nil {
+			limit = *cfg.RateLimit
+		}
+		ep := &endpoint{
+			url:      cfg.URL,
+			priority: cfg.Priority,
+			limiter:  newRateLimiter(limit),
+			healthy:  true,
+			verified: c.chainID == 0,
+		}
+		url := cfg.URL
+		ep.pool = newConnPool(func(ctx context.Context) (*pooledConn, error) {
+			return c.dialConnection(ctx, url)
+		}, c.maxConns, c.maxIdleConns, c.idleTimeout)
+		eps = append(eps, ep)
+	}
+
+	sort.SliceStable(eps, func(i, j int) bool {
+		return eps[i].priority < eps[j].priority
+	})
+	return eps, nil
+}
+
+// pickEndpoints 返回本次请求应依次尝试的端点
+//
+// 健康端点按优先级排在前面，同一优先级内按请求轮询起点，实现负载分担；以 Retry-After 暂停的健康端点排在其后，
+// 不健康的端点排在最后，作为所有健康端点都失败时的兜底；链ID不一致或尚未校验的端点不参与路由。
+func (c *Client) pickEndpoints() []*endpoint {
+	if len(c.endpoints) == 1 {
+		if !c.endpoints[0].usable() {
+			return nil
+		}
+		return c.endpoints
+	}
+
+	offset := int(atomic.AddUint64(&c.rr, 1))
+	now := time.Now()
+	healthy := make([]*endpoint, 0, len(c.endpoints))
+	var paused, unhealthy []*endpoint
+
+	// 按优先级分组，组内按轮询偏移旋转
+	for start := 0; start < len(c.endpoints); {
+		end := start
+		for end < len(c.endpoints) && c.endpoints[end].priority == c.endpoints[start].priority {
+			end++
+		}
+		group := c.endpoints[start:end]
+		for i := range group {
+			ep := group[(i+offset)%len(group)]
+			if !ep.usable() {
+				continue
+			}
+			switch {
+			case !ep.isHealthy():
+				unhealthy = append(unhealthy, ep)
+			case ep.limiter.isPaused(now):
+				paused = append(paused, ep)
+			default:
+				healthy = append(healthy, ep)
+			}
+		}
+		start = end
+	}
+
+	// 以 Retry-After 暂停的端点排在其他健康端点之后，避免请求在暂停期间等待
+	return append(append(healthy, paused...), unhealthy...)
+}
+
+// Endpoints 返回所有端点的健康状态，按优先级排列
+//
+// Returns:
+//   - []EndpointStatus: 端点状态快照
+func (c *Client) Endpoints() []EndpointStatus {
+	statuses := make([]EndpointStatus, 0, len(c.endpoints))
+	for _, ep := range c.endpoints {
+		statuses = append(statuses, ep.status())
+	}
+	return statuses
+}
+
+// probeEndpoints 重新探测不健康或链ID尚未校验的端点，探测成功后恢复为健康，链ID不一致的端点被永久排除
+//
+// Parameters:
+//   - ctx: context.Context 用于控制探测的上下文
+func (c *Client) probeEndpoints(ctx context.Context) {
+	for _, ep := range c.endpoints {
+		if ctx.Err() != nil {
+			return
+		}
+		ep.mu.Lock()
+		skip := ep.wrongChain || ep.healthy && ep.verified
+		ep.mu.Unlock()
+		if skip {
+			continue
+		}
+
+		err := c.probeEndpoint(ctx, ep)
+		if errors.Is(err, errChainIDMismatch) {
+			ep.markWrongChain(err)
+			continue
+		}
+		if err != nil {
+			ep.mu.Lock()
+			ep.lastErr = err
+			ep.lastChecked = time.Now()
+			ep.mu.Unlock()
+			continue
+		}
+		ep.recordSuccess()
+	}
+}
+
+// probeEndpoint 通过新连接查询区块号来探测端点是否恢复，链ID尚未校验时同时校验链ID
+func (c *Client) probeEndpoint(ctx context.Context, ep *endpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	conn, err := ep.pool.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.BlockNumber(ctx)
+	if err == nil && !ep.usable() {
+		if err = c.verifyChainID(ctx, conn.Client); err == nil {
+			ep.markVerified()
+		}
+	}
+	if err != nil {
+		ep.pool.discard(conn)
+	}
+	ep.pool.put(conn)
+	return err
+}
+
+// isEndpointError 判断错误是否由端点本身引起（连接断开、超时、限流等），
+// 而不是节点正常返回的业务错误（如合约回滚、参数错误、数据不存在）
+//
+// Parameters:
+//   - ctx: context.Context 请求上下文，调用者主动取消导致的错误不计入端点故障
+//   - err: error 请求返回的错误
+//
+// Returns:
+//   - bool: 是否应计入端点的连续失败次数
+func isEndpointError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, node.ErrBlockNotFound) || errors.Is(err, node.ErrTransactionNotFound) {
+		return false
+	}
+	// 节点以 JSON-RPC 错误对象应答，说明端点可用；限流错误除外。
+	// 日志查询结果过多时部分节点也以限流错误码应答，缩小范围即可，不是端点故障
+	if rpcErr := parseRPCError(err); rpcErr != nil {
+		return (rpcErr.Code == -32005 || rpcErr.Code == 429) && !isTooManyResultsMessage(rpcErr.Message)
+	}
+	if strings.Contains(err.Error(), "not found") {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/justinwongcn/go-ethlibs/node"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestClient 创建一个使用模拟端点的客户端，每个端点的请求由对应的 requesterFunc 处理
+func newTestClient(t *testing.T, requesters map[string]requesterFunc, endpoints ...Endpoint) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	c := &Client{
+		ctx:                 ctx,
+		cancel:              cancel,
+		maxConns:            4,
+		maxIdleConns:        2,
+		idleTimeout:         time.Minute,
+		healthCheckInterval: time.Minute,
+		maxFailures:         2,
+	}
+	eps, err := newEndpoints(c, endpoints)
+	assert.NoError(t, err)
+	for _, ep := range eps {
+		requester := requesters[ep.url]
+		ep.pool.dial = func(ctx context.Context) (*pooledConn, error) {
+			client, err := node.NewCustomClient(requester, nil)
+			if err != nil {
+				return nil, err
+			}
+			return &pooledConn{Client: client, close: func() {}}, nil
+		}
+	}
+	c.endpoints = eps
+	return c
+}
+
+// blockNumberRequester 返回固定区块号的模拟节点，并统计请求次数
+func blockNumberRequester(result string, calls *int32) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		atomic.AddInt32(calls, 1)
+		return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(`"` + result + `"`)}, nil
+	}
+}
+
+func TestWithConnectionFailover(t *testing.T) {
+	var primaryCalls, backupCalls int32
+	primary := func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		atomic.AddInt32(&primaryCalls, 1)
+		return nil, errors.New("connection reset by peer")
+	}
+
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://primary": primary,
+		"ws://backup":  blockNumberRequester("0x10", &backupCalls),
+	}, Endpoint{URL: "ws://backup", Priority: 1}, Endpoint{URL: "ws://primary", Priority: 0})
+
+	// 优先级最高的端点失败时返回错误，但不影响其他端点
+	for i := 0; i < 2; i++ {
+		_, err := c.GetLatestBlockNumber(context.Background())
+		assert.Error(t, err)
+	}
+	statuses := c.Endpoints()
+	assert.Equal(t, "ws://primary", statuses[0].URL)
+	assert.False(t, statuses[0].Healthy, "连续失败后端点应被标记为不健康")
+	assert.Equal(t, 2, statuses[0].Failures)
+
+	// 之后的请求路由到健康的备用端点
+	n, err := c.GetLatestBlockNumber(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), n)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&primaryCalls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&backupCalls))
+}
+
+func TestProbeEndpointsRecovers(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	var calls int32
+	flaky := func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		if down.Load() {
+			return nil, errors.New("dial tcp: connection refused")
+		}
+		return blockNumberRequester("0x1", &calls)(ctx, r)
+	}
+
+	c := newTestClient(t, map[string]requesterFunc{"ws://flaky": flaky}, Endpoint{URL: "ws://flaky"})
+	c.endpoints[0].recordFailure(errors.New("down"), 1)
+
+	c.probeEndpoints(context.Background())
+	assert.False(t, c.Endpoints()[0].Healthy)
+
+	down.Store(false)
+	c.probeEndpoints(context.Background())
+	assert.True(t, c.Endpoints()[0].Healthy, "探测成功后端点应恢复健康")
+}
+
+func TestIsEndpointError(t *testing.T) {
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assert.False(t, isEndpointError(ctx, nil))
+	assert.False(t, isEndpointError(canceled, errors.New("context canceled")))
+	assert.False(t, isEndpointError(ctx, errors.New(`{"code":-32000,"message":"execution reverted"}`)))
+	assert.False(t, isEndpointError(ctx, node.ErrBlockNotFound))
+	assert.True(t, isEndpointError(ctx, errors.New(`{"code":-32005,"message":"rate limit exceeded"}`)))
+	assert.True(t, isEndpointError(ctx, errors.New("could not make request: EOF")))
+	// 日志查询结果过多不是端点故障，但连接错误和 HTTP 错误即使包含类似的信息也计入
+	assert.False(t, isEndpointError(ctx, errors.New(`{"code":-32005,"message":"query returned more than 10000 results"}`)))
+	assert.True(t, isEndpointError(ctx, &httpStatusError{code: 503, body: []byte("too many connections")}))
+	assert.True(t, isEndpointError(ctx, errors.New("dial tcp: too many open files")))
+}
+
+// chainServer 返回指定链ID的 HTTP 节点，down 时以 503 应答，统计 eth_getBalance 请求次数
+func chainServer(t *testing.T, chainID string, down *atomic.Bool, balanceCalls *int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var req jsonrpc.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		result := `"0x10"`
+		switch req.Method {
+		case "eth_chainId":
+			result = `"` + chainID + `"`
+		case "eth_getBalance":
+			atomic.AddInt32(balanceCalls, 1)
+		}
+		json.NewEncoder(w).Encode(jsonrpc.RawResponse{JSONRPC: "2.0", ID: req.ID, Result: json.RawMessage(result)})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWrongChainEndpointExcluded(t *testing.T) {
+	var goodDown, wrongDown, lateDown atomic.Bool
+	var goodCalls, wrongCalls, lateCalls int32
+	good := chainServer(t, "0x1", &goodDown, &goodCalls)
+	wrong := chainServer(t, "0x5", &wrongDown, &wrongCalls)
+	late := chainServer(t, "0x5", &lateDown, &lateCalls)
+	lateDown.Store(true)
+
+	// wrong 启动时链ID不一致，late 启动时不可用，恢复后链ID不一致
+	c, err := NewMultiClient(context.Background(), []Endpoint{
+		{URL: good.URL, Priority: 0}, {URL: wrong.URL, Priority: 1}, {URL: late.URL, Priority: 1},
+	}, &ClientOptions{ChainID: 1, MaxFailures: 1, HealthCheckInterval: 10 * time.Millisecond, Retry: RetryPolicy{MaxAttempts: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	lateDown.Store(false)
+
+	// 经过多次健康检查后，链ID不一致的端点仍被排除
+	assert.Eventually(t, func() bool { return c.Endpoints()[2].WrongChain }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	statuses := c.Endpoints()
+	assert.True(t, statuses[1].WrongChain)
+	assert.False(t, statuses[1].Healthy)
+	assert.Contains(t, statuses[1].LastError, "chain id mismatch")
+
+	addr := "0x0000000000000000000000000000000000000001"
+	_, err = c.GetBalanceBig(context.Background(), addr, "latest")
+	assert.NoError(t, err)
+
+	// 唯一的正确端点不可用时请求失败，不会转移到链ID不一致的端点
+	goodDown.Store(true)
+	for range 3 {
+		_, err = c.GetBalanceBig(context.Background(), addr, "latest")
+		assert.Error(t, err)
+	}
+	_, err = c.SendRawTransaction(context.Background(), "0x01")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&goodCalls))
+	assert.Zero(t, atomic.LoadInt32(&wrongCalls))
+	assert.Zero(t, atomic.LoadInt32(&lateCalls))
+}
@@ -0,0 +1,113 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/justinwongcn/go-ethlibs/node"
+)
+
+// 客户端返回的错误类别，可以通过 errors.Is 判断
+var (
+	// ErrInvalidAddress 地址参数不是有效的以太坊地址
+	ErrInvalidAddress = errors.New("invalid ethereum address")
+	// ErrInvalidBlockTag 区块参数不是有效的区块号或标签
+	ErrInvalidBlockTag = errors.New("invalid block number or tag")
+	// ErrInvalidHash 区块或交易哈希参数格式无效
+	ErrInvalidHash = errors.New("invalid hash")
+	// ErrPoolExhausted 等待连接池的空闲连接时上下文结束
+	ErrPoolExhausted = errors.New("connection pool exhausted")
+	// ErrNotFound 节点找不到请求的区块或交易
+	ErrNotFound = errors.New("not found")
+)
+
+// RPCError 节点返回的 JSON-RPC 错误对象，可以通过 errors.As 获取
+type RPCError struct {
+	Code    int             `json:"code"`           // 错误码，如 -32000、3（执行回滚）
+	Message string          `json:"message"`        // 错误信息
+	Data    json.RawMessage `json:"data,omitempty"` // 附加数据，合约回滚时为十六进制编码的回滚数据
+}
+
+// Error 实现 error 接口
+func (e *RPCError) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("json-rpc error %d: %s (data: %s)", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// RevertData 返回合约回滚时的回滚数据
+//
+// Returns:
+//   - string: 十六进制编码的回滚数据（以0x开头），可用于解码 Error(string) 等回滚原因
+//   - bool: 错误中是否包含回滚数据
+func (e *RPCError) RevertData() (string, bool) {
+	var data string
+	if json.Unmarshal(e.Data, &data) != nil || !strings.HasPrefix(data, "0x") {
+		return "", false
+	}
+	return data, true
+}
+
+// notFoundError 包装节点库的“不存在”错误，错误信息不变，同时满足 errors.Is(err, ErrNotFound)
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string        { return e.err.Error() }
+func (e *notFoundError) Unwrap() error        { return e.err }
+func (e *notFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// newRPCError 解码 JSON-RPC 响应中的 error 字段
+func newRPCError(raw json.RawMessage) error {
+	var rpcErr RPCError
+	if err := json.Unmarshal(raw, &rpcErr); err != nil {
+		return errors.New(string(raw))
+	}
+	return &rpcErr
+}
+
+// parseRPCError 从节点库返回的错误中解析 JSON-RPC 错误对象，节点库将其编码为错误信息
+//
+// Returns:
+//   - *RPCError: 解析出的错误对象，错误信息中不包含 JSON-RPC 错误对象时返回 nil
+func parseRPCError(err error) *RPCError {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+
+	msg := err.Error()
+	start := strings.Index(msg, "{")
+	if start < 0 {
+		return nil
+	}
+	var parsed struct {
+		Code    *int            `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal([]byte(msg[start:]), &parsed) != nil || parsed.Code == nil {
+		return nil
+	}
+	return &RPCError{Code: *parsed.Code, Message: parsed.Message, Data: parsed.Data}
+}
+
+// wrapNodeError 将节点返回的错误转换为可以通过 errors.Is/As 判断的错误：
+//   - 区块或交易不存在的错误满足 errors.Is(err, ErrNotFound)
+//   - JSON-RPC 错误对象转换为 *RPCError
+func wrapNodeError(err error) error {
+	var rpcErr *RPCError
+	if err == nil || errors.As(err, &rpcErr) {
+		return err
+	}
+	if errors.Is(err, node.ErrBlockNotFound) || errors.Is(err, node.ErrTransactionNotFound) {
+		return &notFoundError{err: err}
+	}
+	if rpcErr := parseRPCError(err); rpcErr != nil {
+		return rpcErr
+	}
+	return err
+}
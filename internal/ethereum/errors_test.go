@@ -0,0 +1,73 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/justinwongcn/go-ethlibs/node"
+	"github.com/stretchr/testify/assert"
+)
+
+// revertData Error(string) 编码的回滚数据，原因为 "Not enough Ether provided."
+const revertData = "0x08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000001a4e6f7420656e6f7567682045746865722070726f76696465642e000000000000"
+
+func TestWrapNodeError(t *testing.T) {
+	err := wrapNodeError(node.ErrBlockNotFound)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, node.ErrBlockNotFound)
+	assert.Equal(t, node.ErrBlockNotFound.Error(), err.Error())
+
+	// 节点库将 JSON-RPC 错误对象编码为错误信息
+	err = wrapNodeError(errors.New(`{"code":3,"message":"execution reverted","data":"` + revertData + `"}`))
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, 3, rpcErr.Code)
+	assert.Equal(t, "execution reverted", rpcErr.Message)
+	data, ok := rpcErr.RevertData()
+	assert.True(t, ok)
+	assert.Equal(t, revertData, data)
+
+	rpcErr = &RPCError{Code: -32000, Message: "nonce too low"}
+	assert.Equal(t, "json-rpc error -32000: nonce too low", rpcErr.Error())
+	_, ok = rpcErr.RevertData()
+	assert.False(t, ok)
+
+	plain := errors.New("connection reset")
+	assert.Equal(t, plain, wrapNodeError(plain))
+	assert.Nil(t, wrapNodeError(nil))
+}
+
+func TestClientTypedErrors(t *testing.T) {
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+			switch r.Method {
+			case "eth_call":
+				rpcErr := json.RawMessage(`{"code":3,"message":"execution reverted","data":"` + revertData + `"}`)
+				return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+			default:
+				return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(`null`)}, nil
+			}
+		},
+	}, Endpoint{URL: "ws://node"})
+	ctx := context.Background()
+
+	_, err := c.CallWithMsg(ctx, CallMsg{To: "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d", Data: "0x70a08231"}, "latest")
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, 3, rpcErr.Code)
+
+	_, err = c.GetBlockByNumber(ctx, "0x1", false)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = c.GetTransactionByHash(ctx, "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = c.GetBalance(ctx, "bad", "latest")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = c.GetBlockByNumber(ctx, "pending-ish", false)
+	assert.ErrorIs(t, err, ErrInvalidBlockTag)
+	_, err = c.GetTransactionByHash(ctx, "88df")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+}
@@ -0,0 +1,251 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// 费用预估的默认参数
+const (
+	defaultFeeHistoryBlocks  = 20  // 参考的最近区块数
+	defaultBaseFeeMultiplier = 2.0 // maxFeePerGas 中基础费用的倍数，2倍可以承受连续6个满区块的基础费用上涨
+	maxFeeHistoryBlocks      = 1024
+)
+
+// defaultFeePercentiles 慢速、标准、快速三档使用的优先费百分位
+var defaultFeePercentiles = [3]float64{10, 50, 90}
+
+// FeeHistory eth_feeHistory 返回的历史费用数据
+type FeeHistory struct {
+	OldestBlock       uint64       // 返回范围内最早的区块号
+	BaseFeePerGas     []*big.Int   // 每个区块的基础费用，比区块数多一个，最后一个为下一个区块的基础费用
+	GasUsedRatio      []float64    // 每个区块的 gas 使用率（gasUsed/gasLimit）
+	Reward            [][]*big.Int // 每个区块按请求的百分位计算的优先费，未请求百分位时为 nil
+	BaseFeePerBlobGas []*big.Int   // 每个区块的 blob 基础费用，坎昆升级前的节点不返回
+	BlobGasUsedRatio  []float64    // 每个区块的 blob gas 使用率，坎昆升级前的节点不返回
+}
+
+// NextBaseFee 返回下一个区块的基础费用
+func (h *FeeHistory) NextBaseFee() *big.Int {
+	if len(h.BaseFeePerGas) == 0 {
+		return nil
+	}
+	return h.BaseFeePerGas[len(h.BaseFeePerGas)-1]
+}
+
+// feeHistoryJSON eth_feeHistory 的原始返回值
+type feeHistoryJSON struct {
+	OldestBlock       eth.Quantity     `json:"oldestBlock"`
+	BaseFeePerGas     []eth.Quantity   `json:"baseFeePerGas"`
+	GasUsedRatio      []float64        `json:"gasUsedRatio"`
+	Reward            [][]eth.Quantity `json:"reward"`
+	BaseFeePerBlobGas []eth.Quantity   `json:"baseFeePerBlobGas"`
+	BlobGasUsedRatio  []float64        `json:"blobGasUsedRatio"`
+}
+
+// quantitiesToBig 将 QUANTITY 数组转换为 *big.Int 数组
+func quantitiesToBig(qs []eth.Quantity) []*big.Int {
+	if qs == nil {
+		return nil
+	}
+	out := make([]*big.Int, len(qs))
+	for i := range qs {
+		out[i] = new(big.Int).Set(qs[i].Big())
+	}
+	return out
+}
+
+// FeeHistory 获取最近区块的基础费用和优先费分布
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - blockCount: uint64 查询的区块数，范围为1到1024，节点可能返回更少的区块
+//   - newestBlock: string 范围内最新的区块号或标签，为空时使用 "latest"
+//   - rewardPercentiles: []float64 优先费的百分位，范围为0到100且单调递增，为空时不返回 Reward
+//
+// Returns:
+//   - *FeeHistory: 历史费用数据
+//   - error: 可能的错误：
+//   - 无效的区块数、区块号或百分位
+//   - 节点不支持 EIP-1559
+//   - 节点连接错误
+func (c *Client) FeeHistory(ctx context.Context, blockCount uint64, newestBlock string, rewardPercentiles []float64) (*FeeHistory, error) {
+	if blockCount == 0 || blockCount > maxFeeHistoryBlocks {
+		return nil, fmt.Errorf("invalid block count %d: must be between 1 and %d", blockCount, maxFeeHistoryBlocks)
+	}
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 || (i > 0 && p < rewardPercentiles[i-1]) {
+			return nil, fmt.Errorf("invalid reward percentiles %v: must be increasing values between 0 and 100", rewardPercentiles)
+		}
+	}
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(newestBlock))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, newestBlock)
+	}
+	if rewardPercentiles == nil {
+		rewardPercentiles = []float64{}
+	}
+
+	result, err := c.rawRequest(ctx, "eth_feeHistory", eth.QuantityFromUInt64(blockCount), numOrTag, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+	var raw feeHistoryJSON
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, fmt.Errorf("could not decode eth_feeHistory result: %v", err)
+	}
+
+	history := &FeeHistory{
+		OldestBlock:       raw.OldestBlock.UInt64(),
+		BaseFeePerGas:     quantitiesToBig(raw.BaseFeePerGas),
+		GasUsedRatio:      raw.GasUsedRatio,
+		BaseFeePerBlobGas: quantitiesToBig(raw.BaseFeePerBlobGas),
+		BlobGasUsedRatio:  raw.BlobGasUsedRatio,
+	}
+	if raw.Reward != nil {
+		history.Reward = make([][]*big.Int, len(raw.Reward))
+		for i, rewards := range raw.Reward {
+			history.Reward[i] = quantitiesToBig(rewards)
+		}
+	}
+	return history, nil
+}
+
+// MaxPriorityFeePerGas 获取节点建议的优先费（小费）
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//
+// Returns:
+//   - *big.Int: 建议的 maxPriorityFeePerGas（单位：wei）
+//   - error: 节点不支持 EIP-1559 或连接错误时返回错误
+func (c *Client) MaxPriorityFeePerGas(ctx context.Context) (*big.Int, error) {
+	return c.requestQuantity(ctx, "eth_maxPriorityFeePerGas")
+}
+
+// FeeOracleOptions 费用预估的选项，零值字段使用默认值
+type FeeOracleOptions struct {
+	Blocks            uint64     // 参考的最近区块数，默认20
+	Percentiles       [3]float64 // 慢速、标准、快速三档的优先费百分位，默认为10、50、90
+	BaseFeeMultiplier float64    // maxFeePerGas 中下一个区块基础费用的倍数，默认2
+	MinPriorityFee    *big.Int   // 优先费的下限（单位：wei），为 nil 时不限制
+}
+
+// withDefaults 返回补全默认值后的选项
+func (o *FeeOracleOptions) withDefaults() FeeOracleOptions {
+	opts := FeeOracleOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Blocks == 0 {
+		opts.Blocks = defaultFeeHistoryBlocks
+	}
+	if opts.Percentiles == [3]float64{} {
+		opts.Percentiles = defaultFeePercentiles
+	}
+	if opts.BaseFeeMultiplier <= 0 {
+		opts.BaseFeeMultiplier = defaultBaseFeeMultiplier
+	}
+	return opts
+}
+
+// FeeEstimate 一档 EIP-1559 交易费用
+type FeeEstimate struct {
+	MaxFeePerGas         *big.Int // 愿意支付的最高单价（单位：wei），为基础费用的倍数加上优先费
+	MaxPriorityFeePerGas *big.Int // 优先费（单位：wei）
+}
+
+// FeeSuggestion 费用预估结果，类似 Etherscan 的 gas tracker
+type FeeSuggestion struct {
+	BlockNumber  uint64      // 参考范围内最新的区块号
+	BaseFee      *big.Int    // 下一个区块的基础费用（单位：wei）
+	GasUsedRatio []float64   // 参考区块的 gas 使用率，按区块顺序排列
+	Slow         FeeEstimate // 慢速：可能需要等待多个区块
+	Standard     FeeEstimate // 标准：通常在几个区块内打包
+	Fast         FeeEstimate // 快速：通常在下一个区块打包
+}
+
+// SuggestFees 根据最近区块的基础费用和优先费分布预估 EIP-1559 交易费用，具有以下特性：
+//   - 每档的优先费为最近区块对应百分位优先费的中位数，忽略没有交易的空区块
+//   - 参考区块都为空时使用节点的 eth_maxPriorityFeePerGas
+//   - 三档优先费保证单调不减
+//   - maxFeePerGas 为下一个区块基础费用的 BaseFeeMultiplier 倍加上优先费
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - opts: *FeeOracleOptions 预估选项，为 nil 时使用默认值
+//
+// Returns:
+//   - *FeeSuggestion: 三档费用建议
+//   - error: 可能的错误：
+//   - 无效的百分位
+//   - 节点不支持 EIP-1559
+//   - 节点连接错误
+func (c *Client) SuggestFees(ctx context.Context, opts *FeeOracleOptions) (*FeeSuggestion, error) {
+	o := opts.withDefaults()
+	history, err := c.FeeHistory(ctx, o.Blocks, "latest", o.Percentiles[:])
+	if err != nil {
+		return nil, err
+	}
+	baseFee := history.NextBaseFee()
+	if baseFee == nil || len(history.GasUsedRatio) == 0 {
+		return nil, fmt.Errorf("node returned empty fee history")
+	}
+
+	var tips [3]*big.Int
+	for tier := range tips {
+		var samples []*big.Int
+		for i, rewards := range history.Reward {
+			if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] > 0 && tier < len(rewards) {
+				samples = append(samples, rewards[tier])
+			}
+		}
+		tips[tier] = median(samples)
+	}
+	if tips[0] == nil {
+		tip, err := c.MaxPriorityFeePerGas(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tips = [3]*big.Int{tip, tip, tip}
+	}
+	for tier := range tips {
+		if o.MinPriorityFee != nil && tips[tier].Cmp(o.MinPriorityFee) < 0 {
+			tips[tier] = new(big.Int).Set(o.MinPriorityFee)
+		}
+		if tier > 0 && tips[tier].Cmp(tips[tier-1]) < 0 {
+			tips[tier] = new(big.Int).Set(tips[tier-1])
+		}
+	}
+
+	// 基础费用乘以倍数，按千分之一精度计算，避免浮点数表示大整数
+	scaledBase := new(big.Int).Mul(baseFee, big.NewInt(int64(o.BaseFeeMultiplier*1000)))
+	scaledBase.Quo(scaledBase, big.NewInt(1000))
+	// 每档使用独立的 *big.Int，调用方修改其中一档不影响其他档和 FeeHistory 的结果
+	estimate := func(tip *big.Int) FeeEstimate {
+		return FeeEstimate{MaxFeePerGas: new(big.Int).Add(scaledBase, tip), MaxPriorityFeePerGas: new(big.Int).Set(tip)}
+	}
+	return &FeeSuggestion{
+		BlockNumber:  history.OldestBlock + uint64(len(history.GasUsedRatio)) - 1,
+		BaseFee:      baseFee,
+		GasUsedRatio: history.GasUsedRatio,
+		Slow:         estimate(tips[0]),
+		Standard:     estimate(tips[1]),
+		Fast:         estimate(tips[2]),
+	}, nil
+}
+
+// median 返回中位数，偶数个时取较小的一个，没有样本时返回 nil
+func median(values []*big.Int) *big.Int {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.SortFunc(sorted, func(a, b *big.Int) int { return a.Cmp(b) })
+	return sorted[(len(sorted)-1)/2]
+}
@@ -0,0 +1,126 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// feeHistoryRequester 返回固定 eth_feeHistory 结果的模拟节点，params 记录最后一次请求的参数
+func feeHistoryRequester(history string, params *[]any) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		switch r.Method {
+		case "eth_feeHistory":
+			b, _ := json.Marshal(r.Params)
+			json.Unmarshal(b, params)
+			return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(history)}, nil
+		case "eth_maxPriorityFeePerGas":
+			return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"0x3b9aca00"`)}, nil
+		}
+		rpcErr := json.RawMessage(`{"code":-32601,"message":"method not found"}`)
+		return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+	}
+}
+
+func TestFeeHistory(t *testing.T) {
+	var params []any
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": feeHistoryRequester(`{
+		"oldestBlock": "0x10",
+		"baseFeePerGas": ["0x64", "0x6e", "0x78"],
+		"gasUsedRatio": [0.9, 0.95],
+		"reward": [["0x1", "0x2"], ["0x3", "0x4"]],
+		"baseFeePerBlobGas": ["0x1", "0x1", "0x1"],
+		"blobGasUsedRatio": [0, 0.5]
+	}`, &params)}, Endpoint{URL: "ws://node"})
+
+	history, err := c.FeeHistory(context.Background(), 2, "", []float64{25, 75})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"0x2", "latest", []any{25.0, 75.0}}, params)
+	assert.Equal(t, uint64(16), history.OldestBlock)
+	assert.Equal(t, []*big.Int{big.NewInt(100), big.NewInt(110), big.NewInt(120)}, history.BaseFeePerGas)
+	assert.Equal(t, big.NewInt(120), history.NextBaseFee())
+	assert.Equal(t, []float64{0.9, 0.95}, history.GasUsedRatio)
+	assert.Equal(t, [][]*big.Int{{big.NewInt(1), big.NewInt(2)}, {big.NewInt(3), big.NewInt(4)}}, history.Reward)
+	assert.Len(t, history.BaseFeePerBlobGas, 3)
+	assert.Equal(t, []float64{0, 0.5}, history.BlobGasUsedRatio)
+
+	// 未请求百分位时发送空数组
+	_, err = c.FeeHistory(context.Background(), 1, "0x10", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"0x1", "0x10", []any{}}, params)
+
+	_, err = c.FeeHistory(context.Background(), 0, "latest", nil)
+	assert.Error(t, err)
+	_, err = c.FeeHistory(context.Background(), 1025, "latest", nil)
+	assert.Error(t, err)
+	_, err = c.FeeHistory(context.Background(), 1, "latest", []float64{50, 10})
+	assert.Error(t, err)
+	_, err = c.FeeHistory(context.Background(), 1, "latest", []float64{101})
+	assert.Error(t, err)
+	_, err = c.FeeHistory(context.Background(), 1, "recent", nil)
+	assert.ErrorIs(t, err, ErrInvalidBlockTag)
+
+	tip, err := c.MaxPriorityFeePerGas(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "1000000000", tip.String())
+}
+
+func TestSuggestFees(t *testing.T) {
+	var params []any
+	// 第二个区块为空，其优先费不参与计算
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": feeHistoryRequester(`{
+		"oldestBlock": "0x10",
+		"baseFeePerGas": ["0x3e8", "0x3e8", "0x3e8", "0x3e8", "0x7d0"],
+		"gasUsedRatio": [0.5, 0, 0.6, 0.7],
+		"reward": [["0xa", "0x14", "0x1e"], ["0x0", "0x0", "0x0"], ["0xc", "0x10", "0x64"], ["0xb", "0x1e", "0x28"]]
+	}`, &params)}, Endpoint{URL: "ws://node"})
+
+	fees, err := c.SuggestFees(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"0x14", "latest", []any{10.0, 50.0, 90.0}}, params)
+	assert.Equal(t, uint64(19), fees.BlockNumber)
+	assert.Equal(t, "2000", fees.BaseFee.String())
+	assert.Equal(t, []float64{0.5, 0, 0.6, 0.7}, fees.GasUsedRatio)
+	assert.Equal(t, "11", fees.Slow.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "4011", fees.Slow.MaxFeePerGas.String())
+	assert.Equal(t, "20", fees.Standard.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "4020", fees.Standard.MaxFeePerGas.String())
+	assert.Equal(t, "40", fees.Fast.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "4040", fees.Fast.MaxFeePerGas.String())
+
+	// 自定义倍数和优先费下限，下限抬高慢速档后标准档不低于慢速档
+	fees, err = c.SuggestFees(context.Background(), &FeeOracleOptions{Blocks: 4, BaseFeeMultiplier: 1.25, MinPriorityFee: big.NewInt(25)})
+	assert.NoError(t, err)
+	assert.Equal(t, "0x4", params[0])
+	assert.Equal(t, "25", fees.Slow.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "2525", fees.Slow.MaxFeePerGas.String())
+	assert.Equal(t, "25", fees.Standard.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "40", fees.Fast.MaxPriorityFeePerGas.String())
+	// 修改一档的费用不影响其他档
+	fees.Slow.MaxPriorityFeePerGas.SetInt64(1)
+	assert.Equal(t, "25", fees.Standard.MaxPriorityFeePerGas.String())
+
+	// 参考区块都为空时使用节点建议的优先费
+	c = newTestClient(t, map[string]requesterFunc{"ws://node": feeHistoryRequester(`{
+		"oldestBlock": "0x10",
+		"baseFeePerGas": ["0x3e8", "0x3e8"],
+		"gasUsedRatio": [0],
+		"reward": [["0x0", "0x0", "0x0"]]
+	}`, &params)}, Endpoint{URL: "ws://node"})
+	fees, err = c.SuggestFees(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), fees.BlockNumber)
+	assert.Equal(t, "1000000000", fees.Slow.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "1000000000", fees.Fast.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "1000002000", fees.Fast.MaxFeePerGas.String())
+	fees.Slow.MaxPriorityFeePerGas.SetInt64(1)
+	assert.Equal(t, "1000000000", fees.Standard.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "1000000000", fees.Fast.MaxPriorityFeePerGas.String())
+
+	_, err = c.SuggestFees(context.Background(), &FeeOracleOptions{Percentiles: [3]float64{90, 50, 10}})
+	assert.Error(t, err)
+}
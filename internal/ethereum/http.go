@@ -0,0 +1,124 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/justinwongcn/go-ethlibs/node"
+)
+
+// rpcHTTPClient 发送 HTTP JSON-RPC 请求使用的客户端，所有 HTTP 端点共用，每个主机最多保留与全局相同数量的空闲连接
+var rpcHTTPClient = func() *http.Client {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.MaxIdleConnsPerHost = tr.MaxIdleConns
+	return &http.Client{Timeout: 120 * time.Second, Transport: tr}
+}()
+
+// isHTTPURL 判断节点URL是否为 HTTP 端点
+func isHTTPURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// httpNodeClient HTTP 端点的节点客户端
+//
+// 节点库的 HTTP 传输忽略状态码和响应头，限流（429）和网关错误（502、503）的响应体无法解码，
+// 调用方只能得到 JSON 解码错误。这里通过自定义的请求器发送请求，非200状态码返回 *httpStatusError，
+// 保留状态码和 Retry-After，由重试策略和限流器处理。
+type httpNodeClient struct {
+	node.Client
+	url string
+}
+
+// URL 返回节点URL，批量请求据此判断是否通过 HTTP 发送 JSON 数组
+func (c *httpNodeClient) URL() string { return c.url }
+
+// newHTTPNodeClient 创建 HTTP 端点的节点客户端，不建立连接
+func newHTTPNodeClient(url string) (node.Client, error) {
+	client, err := node.NewCustomClient(&httpRequester{url: url}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &httpNodeClient{Client: client, url: url}, nil
+}
+
+// httpRequester 通过 HTTP POST 发送单个 JSON-RPC 请求
+type httpRequester struct {
+	url string
+}
+
+// Request 发送请求并解码响应
+//
+// Returns:
+//   - *jsonrpc.RawResponse: 节点的响应，节点返回的 JSON-RPC 错误在响应的 Error 字段中
+//   - error: 可能的错误：
+//   - 网络错误
+//   - 非200状态码（*httpStatusError），响应体是 JSON-RPC 错误响应的非临时性状态码除外
+//   - 响应无法解码
+func (r *httpRequester) Request(ctx context.Context, request *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode %s request: %v", request.Method, err)
+	}
+
+	data, err := postJSON(ctx, r.url, body)
+	var response jsonrpc.RawResponse
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) && !statusErr.transient() {
+		// 部分节点对参数错误等 JSON-RPC 错误返回 4xx、5xx 状态码，按节点错误处理
+		if json.Unmarshal(data, &response) == nil && response.Error != nil {
+			return &response, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("could not decode %s response: %v", request.Method, err)
+	}
+	return &response, nil
+}
+
+// postJSON 向节点 POST JSON 请求体并读取响应体
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - url: string 节点URL
+//   - body: []byte JSON 请求体
+//
+// Returns:
+//   - []byte: 响应体，状态码不是200时同样返回
+//   - error: 网络错误，或状态码不是200时返回 *httpStatusError
+func postJSON(ctx context.Context, url string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := rpcHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return data, &httpStatusError{
+			code:       resp.StatusCode,
+			body:       bytes.TrimSpace(data),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return data, nil
+}
@@ -3,6 +3,9 @@ package ethereum
 import (
 	"context"
 	"fmt"
+	"iter"
+	"strings"
+	"sync/atomic"
 
 	"github.com/justinwongcn/go-ethlibs/eth"
 	"github.com/justinwongcn/go-ethlibs/node"
@@ -30,12 +33,290 @@ func (c *Client) GetLogs(ctx context.Context, filter *eth.LogFilter) ([]*eth.Log
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getLogs", func(conn node.Client) (any, error) {
 		return conn.Logs(ctx, *filter)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return result.([]*eth.Log), nil
+	// 节点返回的是值切片，转换为指针切片
+	logs := result.([]eth.Log)
+	out := make([]*eth.Log, len(logs))
+	for i := range logs {
+		out[i] = &logs[i]
+	}
+	return out, nil
+}
+
+// 分段查询日志的默认参数
+const (
+	defaultLogChunkSize    = 2000  // 初始每段的区块数，多数节点服务商限制在2000到10000个区块
+	defaultLogMaxChunkSize = 10000 // 结果较少时分段逐步扩大，最大不超过该区块数
+	defaultLogConcurrency  = 4     // 同时查询的分段数
+	logGrowThreshold       = 1000  // 一个分段的日志数少于该值时扩大后续分段
+)
+
+// LogRangeOptions 分段查询日志的选项，零值字段使用默认值
+type LogRangeOptions struct {
+	ChunkSize    uint64 // 初始每段的区块数，默认2000
+	MaxChunkSize uint64 // 每段最大区块数，默认10000
+	Concurrency  int    // 同时查询的分段数，默认4
+}
+
+// withDefaults 返回补全默认值后的选项
+func (o *LogRangeOptions) withDefaults() LogRangeOptions {
+	opts := LogRangeOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.ChunkSize == 0 {
+		opts.ChunkSize = defaultLogChunkSize
+	}
+	if opts.MaxChunkSize == 0 {
+		opts.MaxChunkSize = defaultLogMaxChunkSize
+	}
+	opts.MaxChunkSize = max(opts.MaxChunkSize, opts.ChunkSize)
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultLogConcurrency
+	}
+	return opts
+}
+
+// GetLogsRange 分段查询大区块范围内的日志
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - filter: eth.LogFilter 日志过滤器，FromBlock 必需，ToBlock 为空时查询到最新区块，不支持 BlockHash
+//   - opts: *LogRangeOptions 分段选项，为 nil 时使用默认值
+//
+// Returns:
+//   - []*eth.Log: 按区块顺序排列的所有匹配日志
+//   - error: 可能的错误：
+//   - 无效的区块范围
+//   - 单个区块的日志数仍超过节点限制
+//   - 节点连接错误
+//
+// 结果较多时请使用 IterLogs 逐条处理，避免一次性加载到内存。
+func (c *Client) GetLogsRange(ctx context.Context, filter eth.LogFilter, opts *LogRangeOptions) ([]*eth.Log, error) {
+	var logs []*eth.Log
+	for l, err := range c.IterLogs(ctx, filter, opts) {
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
+
+// IterLogs 返回分段查询日志的迭代器，具有以下特性：
+//   - 将 FromBlock..ToBlock 拆分为多个分段，在并发限制内同时查询
+//   - 节点返回结果过多或范围过大的错误时，将该分段对半拆分后重试，并缩小后续分段
+//   - 分段结果较少时逐步扩大后续分段，减少请求次数
+//   - 无论分段完成顺序如何，日志都按区块顺序产出
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - filter: eth.LogFilter 日志过滤器，FromBlock 必需，ToBlock 为空时查询到最新区块，不支持 BlockHash
+//   - opts: *LogRangeOptions 分段选项，为 nil 时使用默认值
+//
+// Returns:
+//   - iter.Seq2[*eth.Log, error]: 日志迭代器，出错时产出一次错误后结束；提前结束迭代会取消未完成的查询
+func (c *Client) IterLogs(ctx context.Context, filter eth.LogFilter, opts *LogRangeOptions) iter.Seq2[*eth.Log, error] {
+	return func(yield func(*eth.Log, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		from, to, err := c.resolveLogRange(ctx, filter)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		o := opts.withDefaults()
+		r := &logRange{c: c, filter: filter, maxChunk: o.MaxChunkSize}
+		r.chunk.Store(o.ChunkSize)
+
+		// pending 中的分段按区块顺序排列，最多同时有 Concurrency 个在查询
+		var pending []*logChunk
+		next, exhausted := from, from > to
+		for len(pending) > 0 || !exhausted {
+			for len(pending) < o.Concurrency && !exhausted {
+				end := to
+				if size := r.chunk.Load(); to-next >= size {
+					end = next + size - 1
+				}
+				chunk := &logChunk{from: next, to: end, done: make(chan struct{})}
+				go func() {
+					defer close(chunk.done)
+					chunk.logs, chunk.err = r.fetch(ctx, chunk.from, chunk.to)
+				}()
+				pending = append(pending, chunk)
+				if end == to {
+					exhausted = true
+				} else {
+					next = end + 1
+				}
+			}
+
+			head := pending[0]
+			pending = pending[1:]
+			<-head.done
+			if head.err != nil {
+				yield(nil, head.err)
+				return
+			}
+			for _, l := range head.logs {
+				if !yield(l, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// resolveLogRange 将过滤器的区块范围转换为区块号，标签 latest、safe、finalized、pending 解析为最新区块号
+func (c *Client) resolveLogRange(ctx context.Context, filter eth.LogFilter) (uint64, uint64, error) {
+	if filter.BlockHash != nil {
+		return 0, 0, fmt.Errorf("block hash filter is not supported for ranged log queries")
+	}
+	if filter.FromBlock == nil {
+		return 0, 0, fmt.Errorf("from block is required for ranged log queries")
+	}
+
+	var latest *uint64
+	resolve := func(b *eth.BlockNumberOrTag) (uint64, error) {
+		if q, ok := b.Quantity(); ok {
+			return q.UInt64(), nil
+		}
+		if tag, _ := b.Tag(); tag == eth.TagEarliest {
+			return 0, nil
+		}
+		if latest == nil {
+			n, err := c.GetLatestBlockNumber(ctx)
+			if err != nil {
+				return 0, err
+			}
+			latest = &n
+		}
+		return *latest, nil
+	}
+
+	from, err := resolve(filter.FromBlock)
+	if err != nil {
+		return 0, 0, err
+	}
+	toBlock := filter.ToBlock
+	if toBlock == nil {
+		toBlock = eth.MustBlockNumberOrTag(string(eth.TagLatest))
+	}
+	to, err := resolve(toBlock)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		return 0, 0, fmt.Errorf("invalid block range: from %d is after to %d", from, to)
+	}
+	return from, to, nil
+}
+
+// logChunk 一个查询分段及其结果
+type logChunk struct {
+	from, to uint64
+	done     chan struct{} // 查询完成后关闭
+	logs     []*eth.Log
+	err      error
+}
+
+// logRange 一次分段查询的共享状态
+type logRange struct {
+	c        *Client
+	filter   eth.LogFilter
+	chunk    atomic.Uint64 // 后续分段的区块数，随结果数量自适应调整
+	maxChunk uint64
+}
+
+// fetch 查询 [from, to] 范围内的日志，结果过多时对半拆分后分别查询
+func (r *logRange) fetch(ctx context.Context, from, to uint64) ([]*eth.Log, error) {
+	f := r.filter
+	f.FromBlock = eth.MustBlockNumberOrTag(eth.QuantityFromUInt64(from).String())
+	f.ToBlock = eth.MustBlockNumberOrTag(eth.QuantityFromUInt64(to).String())
+
+	logs, err := r.c.GetLogs(ctx, &f)
+	if err == nil {
+		r.grow(to-from+1, len(logs))
+		return logs, nil
+	}
+	if !isTooManyResults(err) {
+		return nil, fmt.Errorf("failed to get logs for blocks %d-%d: %w", from, to, err)
+	}
+	if from == to {
+		return nil, fmt.Errorf("block %d has more logs than the node allows in one response: %w", from, err)
+	}
+
+	r.shrink(to - from + 1)
+	mid := from + (to-from)/2
+	left, err := r.fetch(ctx, from, mid)
+	if err != nil {
+		return nil, err
+	}
+	right, err := r.fetch(ctx, mid+1, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// shrink 分段过大时将后续分段缩小为失败分段的一半
+func (r *logRange) shrink(span uint64) {
+	target := max(span/2, 1)
+	for {
+		size := r.chunk.Load()
+		if size <= target || r.chunk.CompareAndSwap(size, target) {
+			return
+		}
+	}
+}
+
+// grow 完整分段的结果较少时将后续分段扩大一倍
+func (r *logRange) grow(span uint64, results int) {
+	size := r.chunk.Load()
+	if span < size || results >= logGrowThreshold || size >= r.maxChunk {
+		return
+	}
+	r.chunk.CompareAndSwap(size, min(size*2, r.maxChunk))
+}
+
+// tooManyResultsMessages 各节点服务商表示 eth_getLogs 结果过多或区块范围过大的错误信息（小写）
+var tooManyResultsMessages = []string{
+	"query returned more than",    // geth、Infura、Polygon："query returned more than 10000 results"
+	"log response size exceeded",  // Alchemy："Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range"
+	"response size should not",    // "response size should not greater than 10000000 bytes"
+	"maximum block range",         // "exceed maximum block range: 5000"
+	"block range is too large",    // "block range is too large"
+	"block range is too wide",     // Ankr
+	"block range limit exceeded",  // Chainstack
+	"eth_getlogs is limited to a", // QuickNode："eth_getLogs is limited to a 10,000 range"
+}
+
+// isTooManyResults 判断 eth_getLogs 的错误是否表示结果过多或区块范围过大，需要缩小范围重试
+//
+// 只匹配节点返回的 JSON-RPC 错误对象，连接错误和 HTTP 状态码错误即使包含类似的信息也不匹配。
+func isTooManyResults(err error) bool {
+	if err == nil {
+		return false
+	}
+	rpcErr := parseRPCError(err)
+	return rpcErr != nil && isTooManyResultsMessage(rpcErr.Message)
+}
+
+// isTooManyResultsMessage 判断 JSON-RPC 错误信息是否表示日志查询结果过多
+func isTooManyResultsMessage(message string) bool {
+	msg := strings.ToLower(message)
+	for _, s := range tooManyResultsMessages {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
 }
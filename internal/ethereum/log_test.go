@@ -0,0 +1,119 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// rangeLogsRequester 每个区块返回一条日志的模拟节点，查询范围超过 maxRange 个区块时返回结果过多的错误
+func rangeLogsRequester(maxRange uint64, calls *int32) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		atomic.AddInt32(calls, 1)
+		var params []struct {
+			FromBlock string `json:"fromBlock"`
+			ToBlock   string `json:"toBlock"`
+		}
+		b, _ := json.Marshal(r.Params)
+		json.Unmarshal(b, &params)
+		from, _ := strconv.ParseUint(params[0].FromBlock[2:], 16, 64)
+		to, _ := strconv.ParseUint(params[0].ToBlock[2:], 16, 64)
+
+		if to-from+1 > maxRange {
+			rpcErr := json.RawMessage(`{"code":-32005,"message":"query returned more than 10000 results"}`)
+			return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+		}
+		logs := make([]map[string]any, 0, to-from+1)
+		for n := from; n <= to; n++ {
+			logs = append(logs, map[string]any{
+				"blockNumber": fmt.Sprintf("0x%x", n),
+				"logIndex":    "0x0",
+				"address":     "0x0000000000000000000000000000000000000001",
+				"topics":      []string{},
+				"data":        "0x",
+			})
+		}
+		result, _ := json.Marshal(logs)
+		return &jsonrpc.RawResponse{ID: r.ID, Result: result}, nil
+	}
+}
+
+func TestGetLogsRangeAdaptive(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": rangeLogsRequester(100, &calls)}, Endpoint{URL: "ws://node"})
+
+	filter := eth.LogFilter{
+		FromBlock: eth.MustBlockNumberOrTag("0x0"),
+		ToBlock:   eth.MustBlockNumberOrTag(eth.QuantityFromUInt64(999).String()),
+	}
+	logs, err := c.GetLogsRange(context.Background(), filter, &LogRangeOptions{ChunkSize: 400, Concurrency: 3})
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1000)
+	for i, l := range logs {
+		assert.Equal(t, uint64(i), l.BlockNumber.UInt64(), "日志应按区块顺序产出")
+	}
+	assert.True(t, c.Endpoints()[0].Healthy, "结果过多的错误不应计入端点故障")
+}
+
+func TestIterLogsStopsEarly(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": rangeLogsRequester(10, &calls)}, Endpoint{URL: "ws://node"})
+
+	filter := eth.LogFilter{
+		FromBlock: eth.MustBlockNumberOrTag("0x0"),
+		ToBlock:   eth.MustBlockNumberOrTag(eth.QuantityFromUInt64(9999).String()),
+	}
+	n := 0
+	for l, err := range c.IterLogs(context.Background(), filter, &LogRangeOptions{ChunkSize: 10, Concurrency: 2}) {
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(n), l.BlockNumber.UInt64())
+		if n++; n == 25 {
+			break
+		}
+	}
+	assert.Less(t, atomic.LoadInt32(&calls), int32(10), "提前结束迭代后不应继续查询")
+}
+
+func TestGetLogsRangeErrors(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": rangeLogsRequester(0, &calls)}, Endpoint{URL: "ws://node"})
+
+	_, err := c.GetLogsRange(context.Background(), eth.LogFilter{}, nil)
+	assert.ErrorContains(t, err, "from block is required")
+
+	_, err = c.GetLogsRange(context.Background(), eth.LogFilter{
+		FromBlock: eth.MustBlockNumberOrTag("0x10"),
+		ToBlock:   eth.MustBlockNumberOrTag("0x1"),
+	}, nil)
+	assert.ErrorContains(t, err, "invalid block range")
+
+	// 单个区块的结果仍然过多时无法再拆分
+	_, err = c.GetLogsRange(context.Background(), eth.LogFilter{
+		FromBlock: eth.MustBlockNumberOrTag("0x1"),
+		ToBlock:   eth.MustBlockNumberOrTag("0x4"),
+	}, nil)
+	assert.ErrorContains(t, err, "block 1 has more logs than the node allows")
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr, "应保留节点返回的错误")
+}
+
+func TestIsTooManyResults(t *testing.T) {
+	assert.True(t, isTooManyResults(errors.New(`{"code":-32005,"message":"query returned more than 10000 results"}`)))
+	assert.True(t, isTooManyResults(&RPCError{Code: -32602, Message: "Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range"}))
+	assert.True(t, isTooManyResults(fmt.Errorf("failed: %w", &RPCError{Code: -32000, Message: "exceed maximum block range: 5000"})))
+	assert.False(t, isTooManyResults(errors.New(`{"code":-32005,"message":"rate limit exceeded"}`)))
+	assert.False(t, isTooManyResults(&RPCError{Code: -32000, Message: "too many connections"}))
+	assert.False(t, isTooManyResults(&RPCError{Code: -32000, Message: "gas limited to 30000000"}))
+	// 不是节点返回的 JSON-RPC 错误对象
+	assert.False(t, isTooManyResults(errors.New("query returned more than 10000 results")))
+	assert.False(t, isTooManyResults(&httpStatusError{code: 503, body: []byte("too many connections")}))
+	assert.False(t, isTooManyResults(nil))
+}
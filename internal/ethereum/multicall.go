@@ -0,0 +1,229 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// Multicall3Address Multicall3 合约在各条链上的统一部署地址
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// Multicall 分组的默认限制
+const (
+	defaultMulticallMaxCalls    = 300        // 每个 aggregate3 调用最多包含的调用数
+	defaultMulticallMaxDataSize = 64 * 1024  // 每个 aggregate3 调用数据的最大字节数
+	defaultMulticallGasBudget   = 50_000_000 // GasLimit 为0时使用 geth 默认的 eth_call gas 上限（RPCGasCap）
+	multicallCallOverhead       = 5 * 32     // 每个调用在 aggregate3 编码中的固定开销：数组偏移量、target、allowFailure、callData 偏移量和长度
+)
+
+// multicallABIJSON Multicall3 aggregate3 方法的 ABI
+const multicallABIJSON = `[{"type":"function","name":"aggregate3","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`
+
+// multicallABI Multicall3 的 aggregate3 方法
+var multicallABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(multicallABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// MulticallCall Multicall 中的一个合约调用
+type MulticallCall struct {
+	Target       string // 被调用的合约地址
+	Data         string // 十六进制编码的调用数据，包含方法选择器
+	AllowFailure bool   // 调用失败时是否允许继续；为 false 时该调用失败会使所在分组整体回滚
+}
+
+// MulticallResult Multicall 中一个调用的结果
+type MulticallResult struct {
+	Success    bool   // 调用是否成功
+	ReturnData string // 十六进制编码的返回数据，调用失败时为回滚数据
+}
+
+// MulticallOptions Multicall 的分组选项，零值字段使用默认值
+type MulticallOptions struct {
+	Address     string // Multicall3 合约地址，默认为 Multicall3Address
+	MaxCalls    int    // 每个 aggregate3 调用最多包含的调用数，默认300
+	MaxDataSize int    // 每个 aggregate3 调用数据的最大字节数，默认64KiB
+	GasLimit    uint64 // 每个 aggregate3 调用的 gas 限制，为0时由节点决定
+	GasPerCall  uint64 // 单个调用的预估 gas，不为0时每组调用数不超过 GasLimit（为0时按5000万计算）/GasPerCall
+}
+
+// withDefaults 返回补全默认值后的选项
+func (o *MulticallOptions) withDefaults() MulticallOptions {
+	opts := MulticallOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Address == "" {
+		opts.Address = Multicall3Address
+	}
+	if opts.MaxCalls <= 0 {
+		opts.MaxCalls = defaultMulticallMaxCalls
+	}
+	if opts.MaxDataSize <= 0 {
+		opts.MaxDataSize = defaultMulticallMaxDataSize
+	}
+	if opts.GasPerCall > 0 {
+		budget := opts.GasLimit
+		if budget == 0 {
+			budget = defaultMulticallGasBudget
+		}
+		opts.MaxCalls = int(min(uint64(opts.MaxCalls), max(budget/opts.GasPerCall, 1)))
+	}
+	return opts
+}
+
+// multicall3Call aggregate3 的调用参数，字段与 ABI 中的 tuple 组件对应
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result aggregate3 的返回值
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Multicall 通过 Multicall3 的 aggregate3 批量执行只读调用，具有以下特性：
+//   - 按调用数、调用数据大小和 gas 限制将调用分组，每组作为一个 aggregate3 调用
+//   - 所有分组在同一个批量请求中发送，并且在同一个区块上执行
+//   - 区块标签（如 "latest"）先解析为区块号，保证结果来自一致的区块高度
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - calls: []MulticallCall 要执行的调用
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//   - opts: *MulticallOptions 分组选项，为 nil 时使用默认值
+//
+// Returns:
+//   - []MulticallResult: 与 calls 一一对应的结果
+//   - uint64: 执行调用的区块号
+//   - error: 可能的错误：
+//   - 无效的地址、调用数据或区块号格式
+//   - 指定地址上没有部署 Multicall3
+//   - AllowFailure 为 false 的调用失败（整个分组回滚）
+//   - 节点连接错误
+func (c *Client) Multicall(ctx context.Context, calls []MulticallCall, numberOrTag string, opts *MulticallOptions) ([]MulticallResult, uint64, error) {
+	o := opts.withDefaults()
+	if !common.IsHexAddress(o.Address) {
+		return nil, 0, fmt.Errorf("%w: multicall address %s", ErrInvalidAddress, o.Address)
+	}
+
+	encoded := make([]multicall3Call, len(calls))
+	for i, call := range calls {
+		if !common.IsHexAddress(call.Target) {
+			return nil, 0, fmt.Errorf("%w: call %d target %s", ErrInvalidAddress, i, call.Target)
+		}
+		data, err := hexutil.Decode(call.Data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid call %d data: %v", i, err)
+		}
+		encoded[i] = multicall3Call{Target: common.HexToAddress(call.Target), AllowFailure: call.AllowFailure, CallData: data}
+	}
+
+	block, err := c.resolveBlockNumber(ctx, numberOrTag)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(calls) == 0 {
+		return []MulticallResult{}, block, nil
+	}
+
+	chunks := splitMulticall(encoded, o)
+	batch := c.Batch(ctx)
+	pending := make([]*BatchResult[string], len(chunks))
+	blockTag := eth.QuantityFromUInt64(block).String()
+	for i, chunk := range chunks {
+		data, err := multicallABI.Pack("aggregate3", chunk)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to encode aggregate3 call: %v", err)
+		}
+		pending[i] = batch.Call(CallMsg{To: o.Address, Gas: o.GasLimit, Data: hexutil.Encode(data)}, blockTag)
+	}
+	if err := batch.Send(); err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]MulticallResult, 0, len(calls))
+	for i, chunk := range chunks {
+		result, err := pending[i].Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("aggregate3 call %d failed: %w", i, err)
+		}
+		decoded, err := decodeAggregate3(result, len(chunk))
+		if err != nil {
+			return nil, 0, fmt.Errorf("%v at %s", err, o.Address)
+		}
+		results = append(results, decoded...)
+	}
+	return results, block, nil
+}
+
+// splitMulticall 按调用数和编码后的数据大小将调用分组，单个调用超过大小限制时独占一组
+func splitMulticall(calls []multicall3Call, opts MulticallOptions) [][]multicall3Call {
+	var chunks [][]multicall3Call
+	start, size := 0, 0
+	for i, call := range calls {
+		callSize := multicallCallOverhead + (len(call.CallData)+31)/32*32
+		if i > start && (i-start >= opts.MaxCalls || size+callSize > opts.MaxDataSize) {
+			chunks = append(chunks, calls[start:i])
+			start, size = i, 0
+		}
+		size += callSize
+	}
+	return append(chunks, calls[start:])
+}
+
+// decodeAggregate3 解码 aggregate3 的返回值
+func decodeAggregate3(result string, expected int) ([]MulticallResult, error) {
+	data, err := hexutil.Decode(result)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("multicall3 returned no data, contract may not be deployed")
+	}
+	values, err := multicallABI.Unpack("aggregate3", data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode aggregate3 result: %v", err)
+	}
+	decoded := *abi.ConvertType(values[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(decoded) != expected {
+		return nil, fmt.Errorf("invalid aggregate3 result: expected %d results, got %d", expected, len(decoded))
+	}
+
+	results := make([]MulticallResult, len(decoded))
+	for i, r := range decoded {
+		results[i] = MulticallResult{Success: r.Success, ReturnData: hexutil.Encode(r.ReturnData)}
+	}
+	return results, nil
+}
+
+// resolveBlockNumber 将区块号或标签解析为区块号，标签通过查询对应的区块获得
+func (c *Client) resolveBlockNumber(ctx context.Context, numberOrTag string) (uint64, error) {
+	b, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
+	}
+	if q, ok := b.Quantity(); ok {
+		return q.UInt64(), nil
+	}
+	if tag, _ := b.Tag(); tag == eth.TagLatest {
+		return c.GetLatestBlockNumber(ctx)
+	}
+	block, err := c.GetBlockByNumber(ctx, numberOrTag, false)
+	if err != nil {
+		return 0, err
+	}
+	if block.Number == nil {
+		return 0, fmt.Errorf("block %s has no number", numberOrTag)
+	}
+	return block.Number.UInt64(), nil
+}
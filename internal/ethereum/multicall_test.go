@@ -0,0 +1,140 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// multicallRequester 模拟部署了 Multicall3 的节点：调用 testToken 成功并返回调用数据本身，调用其他地址失败；
+// 失败的调用不允许失败时整个 aggregate3 回滚。blocks 记录每个 aggregate3 执行的区块
+func multicallRequester(mu *sync.Mutex, blocks *[]string) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		if r.Method == "eth_blockNumber" {
+			return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"0x10"`)}, nil
+		}
+		var params []json.RawMessage
+		b, _ := json.Marshal(r.Params)
+		json.Unmarshal(b, &params)
+		var msg struct {
+			Data string `json:"data"`
+		}
+		var block string
+		json.Unmarshal(params[0], &msg)
+		json.Unmarshal(params[1], &block)
+		mu.Lock()
+		*blocks = append(*blocks, block)
+		mu.Unlock()
+
+		data, _ := hexutil.Decode(msg.Data)
+		values, err := multicallABI.Methods["aggregate3"].Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(values[0], new([]multicall3Call)).(*[]multicall3Call)
+		results := make([]multicall3Result, len(calls))
+		for i, call := range calls {
+			if call.Target == common.HexToAddress(testToken) {
+				results[i] = multicall3Result{Success: true, ReturnData: call.CallData}
+				continue
+			}
+			if !call.AllowFailure {
+				rpcErr := json.RawMessage(`{"code":3,"message":"execution reverted: Multicall3: call failed"}`)
+				return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+			}
+			results[i] = multicall3Result{ReturnData: []byte{0xde, 0xad}}
+		}
+		packed, _ := multicallABI.Methods["aggregate3"].Outputs.Pack(results)
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"` + hexutil.Encode(packed) + `"`)}, nil
+	}
+}
+
+func TestMulticall(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		blocks []string
+	)
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": multicallRequester(&mu, &blocks)}, Endpoint{URL: "ws://node"})
+	assert.Equal(t, "0x82ad56cb", hexutil.Encode(multicallABI.Methods["aggregate3"].ID))
+
+	calls := []MulticallCall{
+		{Target: testToken, Data: "0x70a08231"},
+		{Target: testOther, Data: "0x01", AllowFailure: true},
+		{Target: testToken, Data: "0x02"},
+		{Target: testToken, Data: "0x03"},
+		{Target: testToken, Data: "0x"},
+	}
+	results, block, err := c.Multicall(context.Background(), calls, "latest", &MulticallOptions{MaxCalls: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), block)
+	assert.Equal(t, []MulticallResult{
+		{Success: true, ReturnData: "0x70a08231"},
+		{Success: false, ReturnData: "0xdead"},
+		{Success: true, ReturnData: "0x02"},
+		{Success: true, ReturnData: "0x03"},
+		{Success: true, ReturnData: "0x"},
+	}, results)
+	// 5个调用分为3组，都在解析出的同一个区块上执行
+	assert.Equal(t, []string{"0x10", "0x10", "0x10"}, blocks)
+
+	// 不允许失败的调用失败时返回回滚错误
+	calls[1].AllowFailure = false
+	_, _, err = c.Multicall(context.Background(), calls, "0x5", nil)
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, "0x5", blocks[len(blocks)-1])
+
+	_, _, err = c.Multicall(context.Background(), []MulticallCall{{Target: "0x123", Data: "0x"}}, "latest", nil)
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	_, _, err = c.Multicall(context.Background(), []MulticallCall{{Target: testToken, Data: "xyz"}}, "latest", nil)
+	assert.Error(t, err)
+	_, _, err = c.Multicall(context.Background(), calls, "recent", nil)
+	assert.ErrorIs(t, err, ErrInvalidBlockTag)
+
+	results, _, err = c.Multicall(context.Background(), nil, "0x1", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestMulticallNotDeployed(t *testing.T) {
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+			return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"0x"`)}, nil
+		},
+	}, Endpoint{URL: "ws://node"})
+	_, _, err := c.Multicall(context.Background(), []MulticallCall{{Target: testToken, Data: "0x"}}, "0x1", nil)
+	assert.ErrorContains(t, err, "not be deployed")
+}
+
+func TestSplitMulticall(t *testing.T) {
+	calls := make([]multicall3Call, 10)
+	for i := range calls {
+		calls[i].CallData = make([]byte, 36) // 编码后占 160+64 字节
+	}
+
+	// 按数据大小分组
+	chunks := splitMulticall(calls, MulticallOptions{MaxCalls: 100, MaxDataSize: 3 * 224})
+	assert.Len(t, chunks, 4)
+	assert.Len(t, chunks[0], 3)
+	assert.Len(t, chunks[3], 1)
+
+	// 单个调用超过大小限制时独占一组
+	chunks = splitMulticall(calls[:2], MulticallOptions{MaxCalls: 100, MaxDataSize: 10})
+	assert.Len(t, chunks, 2)
+
+	// 按 gas 预算限制调用数
+	opts := (&MulticallOptions{GasLimit: 1_000_000, GasPerCall: 300_000}).withDefaults()
+	assert.Equal(t, 3, opts.MaxCalls)
+	opts = (&MulticallOptions{GasPerCall: 100_000}).withDefaults()
+	assert.Equal(t, defaultMulticallMaxCalls, opts.MaxCalls)
+	opts = (&MulticallOptions{GasLimit: 1, GasPerCall: 100_000}).withDefaults()
+	assert.Equal(t, 1, opts.MaxCalls)
+	assert.Len(t, splitMulticall(calls, opts), 10)
+}
@@ -0,0 +1,383 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// ERC-721、ERC-1155 和 ERC-165 方法选择器
+const (
+	erc721OwnerOf           = "0x6352211e" // ownerOf(uint256)
+	erc721TokenURI          = "0xc87b56dd" // tokenURI(uint256)
+	erc1155URI              = "0x0e89341c" // uri(uint256)
+	erc1155BalanceOf        = "0x00fdd58e" // balanceOf(address,uint256)
+	erc165SupportsInterface = "0x01ffc9a7" // supportsInterface(bytes4)
+)
+
+// ERC-1155 转账事件的 topic0
+const (
+	TransferSingleTopic = "0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62" // TransferSingle(address,address,address,uint256,uint256)
+	TransferBatchTopic  = "0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb" // TransferBatch(address,address,address,uint256[],uint256[])
+)
+
+// ERC-165 接口ID
+const (
+	InterfaceERC165             = "0x01ffc9a7"
+	InterfaceERC721             = "0x80ac58cd"
+	InterfaceERC721Metadata     = "0x5b5e139f"
+	InterfaceERC1155            = "0xd9b67a26"
+	InterfaceERC1155MetadataURI = "0x0e89341c"
+)
+
+// NFTStandard NFT 合约实现的标准
+type NFTStandard string
+
+const (
+	NFTStandardUnknown NFTStandard = ""        // 未通过 ERC-165 声明 ERC-721 或 ERC-1155
+	NFTStandardERC721  NFTStandard = "ERC721"  // ERC-721
+	NFTStandardERC1155 NFTStandard = "ERC1155" // ERC-1155
+)
+
+// NFTTransfer NFT 的一次转账，来自 ERC-721 的 Transfer 或 ERC-1155 的 TransferSingle、TransferBatch 事件日志
+type NFTTransfer struct {
+	LogPosition
+	Standard NFTStandard // 转账事件所属的标准
+	Token    string      // NFT 合约地址
+	Operator string      // ERC-1155 中执行转账的地址，ERC-721 为空
+	From     string      // 转出地址，铸造时为零地址
+	To       string      // 转入地址，销毁时通常为零地址
+	TokenID  *big.Int    // 代币ID
+	Value    *big.Int    // 转账数量，ERC-721 始终为1
+}
+
+// NFTOwnerOf 查询 ERC-721 代币的持有者
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - token: string NFT 合约地址
+//   - tokenID: *big.Int 代币ID
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - string: 持有者地址（小写）
+//   - error: 可能的错误：
+//   - 无效的地址、代币ID或区块号格式
+//   - 代币不存在（合约回滚，返回 *RPCError）
+//   - 节点连接错误
+func (c *Client) NFTOwnerOf(ctx context.Context, token string, tokenID *big.Int, numberOrTag string) (string, error) {
+	id, err := encodeUint256(tokenID)
+	if err != nil {
+		return "", err
+	}
+	result, err := c.CallWithMsg(ctx, CallMsg{To: token, Data: erc721OwnerOf + id}, numberOrTag)
+	if err != nil {
+		return "", err
+	}
+	owner, err := decodeUint256(result, "ownerOf")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("0x%040x", owner), nil
+}
+
+// NFTTokenURI 查询 NFT 的元数据 URI，具有以下特性：
+//   - 先调用 ERC-721 的 tokenURI，合约未实现时调用 ERC-1155 的 uri
+//   - ERC-1155 URI 中的 {id} 按标准替换为64位小写十六进制的代币ID
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - token: string NFT 合约地址
+//   - tokenID: *big.Int 代币ID
+//
+// Returns:
+//   - string: 元数据 URI，如 "ipfs://..."、"https://..." 或 "data:application/json;base64,..."
+//   - error: 可能的错误：
+//   - 无效的地址或代币ID
+//   - 合约未实现 tokenURI 和 uri，或代币不存在
+//   - 节点连接错误
+func (c *Client) NFTTokenURI(ctx context.Context, token string, tokenID *big.Int) (string, error) {
+	id, err := encodeUint256(tokenID)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.CallWithMsg(ctx, CallMsg{To: token, Data: erc721TokenURI + id}, "latest")
+	var rpcErr *RPCError
+	if err != nil && !errors.As(err, &rpcErr) {
+		return "", err
+	}
+	if err == nil && result != "0x" {
+		return decodeTokenString(result), nil
+	}
+
+	result, uriErr := c.CallWithMsg(ctx, CallMsg{To: token, Data: erc1155URI + id}, "latest")
+	if uriErr != nil {
+		// 两个方法都失败时优先返回 tokenURI 的错误，ERC-721 代币不存在时的回滚原因更有意义
+		if err != nil {
+			return "", err
+		}
+		return "", uriErr
+	}
+	return strings.ReplaceAll(decodeTokenString(result), "{id}", id), nil
+}
+
+// NFTBalanceOf 查询地址持有的 ERC-1155 代币数量
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - token: string NFT 合约地址
+//   - holder: string 持有者地址
+//   - tokenID: *big.Int 代币ID
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - *big.Int: 持有数量
+//   - error: 可能的错误：
+//   - 无效的地址、代币ID或区块号格式
+//   - 合约未实现 ERC-1155 的 balanceOf
+//   - 节点连接错误
+//
+// ERC-721 的 balanceOf(address) 与 ERC-20 相同，请使用 TokenBalance。
+func (c *Client) NFTBalanceOf(ctx context.Context, token, holder string, tokenID *big.Int, numberOrTag string) (*big.Int, error) {
+	data, err := encodeAddressCall(erc1155BalanceOf, holder)
+	if err != nil {
+		return nil, err
+	}
+	id, err := encodeUint256(tokenID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.CallWithMsg(ctx, CallMsg{To: token, Data: data + id}, numberOrTag)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint256(result, "balanceOf")
+}
+
+// SupportsInterface 按 ERC-165 检测合约是否实现了指定接口
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - contract: string 合约地址
+//   - interfaceID: string 4字节的接口ID，如 InterfaceERC721
+//
+// Returns:
+//   - bool: 合约实现了 ERC-165 并声明支持该接口时返回 true；未实现 ERC-165 的合约返回 false
+//   - error: 无效的地址或接口ID、节点连接错误时返回错误
+func (c *Client) SupportsInterface(ctx context.Context, contract, interfaceID string) (bool, error) {
+	supported, err := c.supportsInterfaces(ctx, contract, interfaceID)
+	if err != nil {
+		return false, err
+	}
+	return supported[0], nil
+}
+
+// DetectNFTStandard 通过 ERC-165 检测 NFT 合约实现的标准
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - contract: string 合约地址
+//
+// Returns:
+//   - NFTStandard: 合约实现的标准，同时声明两个标准时返回 NFTStandardERC721，都未声明时返回 NFTStandardUnknown
+//   - error: 无效的地址、节点连接错误时返回错误
+func (c *Client) DetectNFTStandard(ctx context.Context, contract string) (NFTStandard, error) {
+	supported, err := c.supportsInterfaces(ctx, contract, InterfaceERC721, InterfaceERC1155)
+	if err != nil {
+		return NFTStandardUnknown, err
+	}
+	switch {
+	case supported[0]:
+		return NFTStandardERC721, nil
+	case supported[1]:
+		return NFTStandardERC1155, nil
+	default:
+		return NFTStandardUnknown, nil
+	}
+}
+
+// supportsInterfaces 在一个批量请求中检测多个接口
+//
+// 按 ERC-165 的规定，合约必须对 0x01ffc9a7 返回 true、对 0xffffffff 返回 false，否则视为未实现 ERC-165，
+// 所有接口都返回 false
+func (c *Client) supportsInterfaces(ctx context.Context, contract string, interfaceIDs ...string) ([]bool, error) {
+	if _, err := eth.NewAddress(contract); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, contract)
+	}
+
+	batch := c.Batch(ctx)
+	call := func(id string) (*BatchResult[string], error) {
+		data, err := eth.NewData4(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interface id %q: %v", id, err)
+		}
+		// bytes4 参数左对齐，右侧补零
+		return batch.Call(CallMsg{To: contract, Data: erc165SupportsInterface + data.String()[2:] + strings.Repeat("0", 56)}, "latest"), nil
+	}
+	checks := make([]*BatchResult[string], 0, len(interfaceIDs)+2)
+	for _, id := range append([]string{InterfaceERC165, "0xffffffff"}, interfaceIDs...) {
+		r, err := call(id)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, r)
+	}
+	if err := batch.Send(); err != nil {
+		return nil, err
+	}
+
+	results := make([]bool, len(checks))
+	for i, check := range checks {
+		result, ok, err := optionalCallResult(check)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			v, err := decodeUint256(result, "supportsInterface")
+			results[i] = err == nil && v.Cmp(big.NewInt(1)) == 0
+		}
+	}
+	supported := results[2:]
+	if !results[0] || results[1] {
+		clear(supported)
+	}
+	return supported, nil
+}
+
+// NFTTransfers 查询 NFT 的转账记录，具有以下特性：
+//   - 解码 ERC-721 的 Transfer 和 ERC-1155 的 TransferSingle、TransferBatch 事件
+//   - TransferBatch 按代币ID展开为多条记录，共享同一个日志位置
+//   - 指定 holder 时分别查询转出和转入，合并后按区块和日志顺序排列
+//   - 忽略 ERC-20 的 Transfer 日志（tokenId 不是 indexed 参数）
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - token: string NFT 合约地址，为空时查询 holder 所有 NFT 的转账
+//   - holder: string 转出或转入地址，为空时查询 token 的所有转账
+//   - fromBlock: string 起始区块号或标签，为空时使用 "earliest"
+//   - toBlock: string 结束区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - []*NFTTransfer: 按区块和日志顺序排列的转账记录
+//   - error: 可能的错误：
+//   - token 和 holder 都为空
+//   - 无效的地址或区块号格式
+//   - 节点连接错误
+func (c *Client) NFTTransfers(ctx context.Context, token, holder string, fromBlock, toBlock string) ([]*NFTTransfer, error) {
+	filter, err := transferFilter(token, holder, fromBlock, toBlock, TransferTopic, TransferSingleTopic, TransferBatchTopic)
+	if err != nil {
+		return nil, err
+	}
+
+	// ERC-721 的 from、to 位于 topic1、topic2，ERC-1155 的 from、to 位于 topic2、topic3
+	filters := []eth.LogFilter{filter}
+	if holder != "" {
+		topic, err := addressTopic(holder)
+		if err != nil {
+			return nil, err
+		}
+		erc721, erc1155 := filter, filter
+		erc721.Topics = [][]eth.Topic{{TransferTopic}}
+		erc1155.Topics = [][]eth.Topic{{TransferSingleTopic, TransferBatchTopic}}
+		filters = []eth.LogFilter{withTopic(erc721, 1, topic), withTopic(filter, 2, topic), withTopic(erc1155, 3, topic)}
+	}
+
+	logs, err := c.transferLogs(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	var transfers []*NFTTransfer
+	for _, l := range logs {
+		transfers = append(transfers, parseNFTTransfers(l)...)
+	}
+	return transfers, nil
+}
+
+// parseNFTTransfers 将 NFT 转账日志转换为转账记录，日志格式不符合时返回 nil
+func parseNFTTransfers(l *eth.Log) []*NFTTransfer {
+	if len(l.Topics) != 4 {
+		return nil
+	}
+	base := NFTTransfer{LogPosition: logPosition(l), Token: strings.ToLower(l.Address.String())}
+	data := l.Data.Bytes()
+
+	switch strings.ToLower(l.Topics[0].String()) {
+	case TransferTopic:
+		base.Standard = NFTStandardERC721
+		base.From = topicAddress(l.Topics[1])
+		base.To = topicAddress(l.Topics[2])
+		base.TokenID = new(big.Int).SetBytes(l.Topics[3].Bytes())
+		base.Value = big.NewInt(1)
+		return []*NFTTransfer{&base}
+	case TransferSingleTopic:
+		if len(data) != 64 {
+			return nil
+		}
+		base.Standard = NFTStandardERC1155
+		base.Operator = topicAddress(l.Topics[1])
+		base.From = topicAddress(l.Topics[2])
+		base.To = topicAddress(l.Topics[3])
+		base.TokenID = new(big.Int).SetBytes(data[:32])
+		base.Value = new(big.Int).SetBytes(data[32:])
+		return []*NFTTransfer{&base}
+	case TransferBatchTopic:
+		ids, ok := decodeUint256Array(data, 0)
+		if !ok {
+			return nil
+		}
+		values, ok := decodeUint256Array(data, 1)
+		if !ok || len(values) != len(ids) {
+			return nil
+		}
+		base.Standard = NFTStandardERC1155
+		base.Operator = topicAddress(l.Topics[1])
+		base.From = topicAddress(l.Topics[2])
+		base.To = topicAddress(l.Topics[3])
+		transfers := make([]*NFTTransfer, len(ids))
+		for i := range ids {
+			transfer := base
+			transfer.TokenID, transfer.Value = ids[i], values[i]
+			transfers[i] = &transfer
+		}
+		return transfers
+	default:
+		return nil
+	}
+}
+
+// decodeUint256Array 解码 ABI 编码数据中第 index 个参数的 uint256[]，数据越界时返回 false
+func decodeUint256Array(data []byte, index int) ([]*big.Int, bool) {
+	head := index * 32
+	if len(data) < head+32 {
+		return nil, false
+	}
+	offset := new(big.Int).SetBytes(data[head : head+32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return nil, false
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[start-32 : start])
+	if !length.IsUint64() || length.Uint64() > (uint64(len(data))-start)/32 {
+		return nil, false
+	}
+
+	values := make([]*big.Int, length.Uint64())
+	for i := range values {
+		pos := start + uint64(i)*32
+		values[i] = new(big.Int).SetBytes(data[pos : pos+32])
+	}
+	return values, true
+}
+
+// encodeUint256 将非负整数编码为64位十六进制的 ABI 参数，不带 0x 前缀
+func encodeUint256(v *big.Int) (string, error) {
+	if v == nil || v.Sign() < 0 || v.BitLen() > 256 {
+		return "", fmt.Errorf("invalid uint256 value: %v", v)
+	}
+	return fmt.Sprintf("%064x", v), nil
+}
@@ -0,0 +1,186 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFTCalls(t *testing.T) {
+	calls := map[string]string{
+		erc721OwnerOf:    "0x" + topicOf(testHolder)[2:],
+		erc721TokenURI:   abiString("ipfs://bafy/42"),
+		erc1155BalanceOf: "0x" + word(5),
+	}
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": tokenCallRequester(calls)}, Endpoint{URL: "ws://node"})
+	ctx := context.Background()
+
+	owner, err := c.NFTOwnerOf(ctx, testToken, big.NewInt(42), "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, testHolder, owner)
+
+	uri, err := c.NFTTokenURI(ctx, testToken, big.NewInt(42))
+	assert.NoError(t, err)
+	assert.Equal(t, "ipfs://bafy/42", uri)
+
+	balance, err := c.NFTBalanceOf(ctx, testToken, testHolder, big.NewInt(42), "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), balance.Int64())
+
+	// ERC-1155 没有 tokenURI，使用 uri 并替换 {id}
+	delete(calls, erc721TokenURI)
+	calls[erc1155URI] = abiString("https://example.com/{id}.json")
+	uri, err = c.NFTTokenURI(ctx, testToken, big.NewInt(42))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/"+word(42)+".json", uri)
+
+	// 两个方法都回滚
+	delete(calls, erc1155URI)
+	_, err = c.NFTTokenURI(ctx, testToken, big.NewInt(42))
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+
+	_, err = c.NFTOwnerOf(ctx, testToken, big.NewInt(-1), "latest")
+	assert.Error(t, err)
+	_, err = c.NFTBalanceOf(ctx, testToken, "0x123", big.NewInt(1), "latest")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+// interfaceRequester 按接口ID响应 supportsInterface 的模拟节点，supported 为 nil 时所有调用回滚
+func interfaceRequester(supported map[string]bool) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		var params []map[string]any
+		b, _ := json.Marshal(r.Params)
+		json.Unmarshal(b, &params)
+		data, _ := params[0]["data"].(string)
+		if supported == nil || data[:10] != erc165SupportsInterface {
+			rpcErr := json.RawMessage(`{"code":3,"message":"execution reverted"}`)
+			return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+		}
+		result := word(0)
+		if supported["0x"+data[10:18]] {
+			result = word(1)
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"0x` + result + `"`)}, nil
+	}
+}
+
+func TestDetectNFTStandard(t *testing.T) {
+	tests := []struct {
+		name      string
+		supported map[string]bool
+		want      NFTStandard
+	}{
+		{"erc721", map[string]bool{InterfaceERC165: true, InterfaceERC721: true}, NFTStandardERC721},
+		{"erc1155", map[string]bool{InterfaceERC165: true, InterfaceERC1155: true}, NFTStandardERC1155},
+		{"erc165 only", map[string]bool{InterfaceERC165: true}, NFTStandardUnknown},
+		// 对所有接口都返回 true 的合约不符合 ERC-165
+		{"always true", map[string]bool{InterfaceERC165: true, "0xffffffff": true, InterfaceERC721: true}, NFTStandardUnknown},
+		{"no erc165", nil, NFTStandardUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, map[string]requesterFunc{"ws://node": interfaceRequester(tt.supported)}, Endpoint{URL: "ws://node"})
+			standard, err := c.DetectNFTStandard(context.Background(), testToken)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, standard)
+		})
+	}
+
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": interfaceRequester(map[string]bool{InterfaceERC165: true, InterfaceERC721Metadata: true}),
+	}, Endpoint{URL: "ws://node"})
+	ok, err := c.SupportsInterface(context.Background(), testToken, InterfaceERC721Metadata)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, err = c.SupportsInterface(context.Background(), testToken, "0x1234")
+	assert.Error(t, err)
+	_, err = c.SupportsInterface(context.Background(), "0x1234", InterfaceERC721)
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+func TestNFTTransfers(t *testing.T) {
+	log := func(block, index uint64, data string, topics ...string) map[string]any {
+		return map[string]any{
+			"blockNumber": fmt.Sprintf("0x%x", block), "blockHash": "0x" + word(block), "logIndex": fmt.Sprintf("0x%x", index),
+			"transactionHash": "0x" + word(block*10), "transactionIndex": "0x0",
+			"address": testToken, "topics": topics, "data": data,
+		}
+	}
+	zero := topicOf("0x0000000000000000000000000000000000000000")
+	logs := []map[string]any{
+		// ERC-721 铸造给 holder
+		log(1, 0, "0x", TransferTopic, zero, topicOf(testHolder), "0x"+word(42)),
+		// ERC-20 Transfer 被忽略
+		log(1, 1, "0x"+word(100), TransferTopic, topicOf(testHolder), topicOf(testOther)),
+		// ERC-1155 单个转出，operator 为 other
+		log(2, 0, "0x"+word(7)+word(3), TransferSingleTopic, topicOf(testOther), topicOf(testHolder), topicOf(testOther)),
+		// ERC-1155 批量转入
+		log(3, 0, "0x"+word(64)+word(160)+word(2)+word(1)+word(2)+word(2)+word(10)+word(20),
+			TransferBatchTopic, topicOf(testOther), topicOf(testOther), topicOf(testHolder)),
+		// 与 holder 无关
+		log(4, 0, "0x", TransferTopic, topicOf(testOther), topicOf(testOther), "0x"+word(1)),
+	}
+	var filters []map[string]any
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": filterLogsRequester(logs, "0x4", &filters)}, Endpoint{URL: "ws://node"})
+
+	transfers, err := c.NFTTransfers(context.Background(), "", testHolder, "0x1", "latest")
+	assert.NoError(t, err)
+	assert.Len(t, filters, 3)
+	assert.Nil(t, filters[0]["address"])
+	assert.Len(t, transfers, 4)
+
+	assert.Equal(t, &NFTTransfer{
+		LogPosition: LogPosition{BlockNumber: 1, BlockHash: "0x" + word(1), TxHash: "0x" + word(10)},
+		Standard:    NFTStandardERC721,
+		Token:       testToken,
+		From:        "0x0000000000000000000000000000000000000000",
+		To:          testHolder,
+		TokenID:     big.NewInt(42),
+		Value:       big.NewInt(1),
+	}, transfers[0])
+	assert.Equal(t, NFTStandardERC1155, transfers[1].Standard)
+	assert.Equal(t, testOther, transfers[1].Operator)
+	assert.Equal(t, testHolder, transfers[1].From)
+	assert.Equal(t, int64(7), transfers[1].TokenID.Int64())
+	assert.Equal(t, int64(3), transfers[1].Value.Int64())
+
+	// 批量转账按代币ID展开
+	assert.Equal(t, transfers[2].LogPosition, transfers[3].LogPosition)
+	assert.Equal(t, []int64{1, 10}, []int64{transfers[2].TokenID.Int64(), transfers[2].Value.Int64()})
+	assert.Equal(t, []int64{2, 20}, []int64{transfers[3].TokenID.Int64(), transfers[3].Value.Int64()})
+
+	// 不按 holder 过滤时包含所有 NFT 转账
+	filters = nil
+	transfers, err = c.NFTTransfers(context.Background(), testToken, "", "", "")
+	assert.NoError(t, err)
+	assert.Len(t, filters, 1)
+	assert.Len(t, transfers, 5)
+}
+
+func TestDecodeUint256Array(t *testing.T) {
+	values, ok := decodeUint256Array(hexBytes(t, word(32)+word(2)+word(5)+word(6)), 0)
+	assert.True(t, ok)
+	assert.Equal(t, []*big.Int{big.NewInt(5), big.NewInt(6)}, values)
+
+	_, ok = decodeUint256Array(hexBytes(t, word(32)+word(3)+word(5)+word(6)), 0)
+	assert.False(t, ok)
+	_, ok = decodeUint256Array(hexBytes(t, word(1000)), 0)
+	assert.False(t, ok)
+	_, ok = decodeUint256Array(hexBytes(t, word(32)), 1)
+	assert.False(t, ok)
+}
+
+// hexBytes 解码不带 0x 前缀的十六进制字符串
+func hexBytes(t *testing.T, s string) []byte {
+	b, err := decodeHexResult(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,427 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// maxNonceRetries 节点返回 nonce too low 时重新分配 nonce 的最大次数
+const maxNonceRetries = 3
+
+// 节点拒绝交易时的错误信息，不同客户端的措辞不同，按小写匹配
+var (
+	nonceTooLowMessages  = []string{"nonce too low", "oldnonce", "transaction already imported"}
+	alreadyKnownMessages = []string{"already known", "alreadyknown", "known transaction"}
+)
+
+// isNonceTooLow 判断节点是否因 nonce 已被使用而拒绝交易
+func isNonceTooLow(err error) bool {
+	return matchRPCMessage(err, nonceTooLowMessages)
+}
+
+// isAlreadyKnown 判断节点是否因交易已在交易池中而拒绝交易
+func isAlreadyKnown(err error) bool {
+	return matchRPCMessage(err, alreadyKnownMessages)
+}
+
+// matchRPCMessage 判断错误是否为包含指定信息之一的 JSON-RPC 错误
+func matchRPCMessage(err error, messages []string) bool {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	return slices.ContainsFunc(messages, func(m string) bool { return strings.Contains(msg, m) })
+}
+
+// SignFunc 使用指定的 nonce 构建并签名交易，返回十六进制编码的已签名交易
+type SignFunc func(ctx context.Context, nonce uint64) (string, error)
+
+// NonceManager 按地址在本地分配连续的 nonce，避免并发发送交易时重复读取 GetTransactionCount 导致冲突，具有以下特性：
+//   - 第一次分配时从节点读取 pending 状态的交易数，之后在本地递增
+//   - 签名或广播失败（节点明确拒绝）的 nonce 被释放，优先分配给下一笔交易，避免产生空缺
+//   - 节点返回 nonce too low 时与节点同步后重新分配，返回 already known 时视为发送成功
+//   - 记录已广播交易的哈希，用于检测被丢弃的交易造成的空缺
+type NonceManager struct {
+	c *Client
+
+	mu       sync.Mutex
+	accounts map[common.Address]*nonceAccount
+}
+
+// nonceAccount 一个地址的 nonce 状态
+type nonceAccount struct {
+	mu       sync.Mutex
+	loaded   bool              // 是否已从节点读取初始 nonce
+	next     uint64            // 下一个未分配过的 nonce
+	released []uint64          // 已释放的 nonce，按升序排列，优先分配
+	inflight map[uint64]bool   // 已分配、尚未广播或释放的 nonce
+	sent     map[uint64]string // 已广播交易的哈希
+}
+
+// NewNonceManager 创建 nonce 管理器，同一账户的所有发送方应共享一个管理器
+//
+// Returns:
+//   - *NonceManager: nonce 管理器
+func (c *Client) NewNonceManager() *NonceManager {
+	return &NonceManager{c: c, accounts: make(map[common.Address]*nonceAccount)}
+}
+
+// account 返回地址对应的 nonce 状态，不存在时创建
+func (m *NonceManager) account(address string) (*nonceAccount, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+	addr := common.HexToAddress(address)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.accounts[addr]
+	if !ok {
+		a = &nonceAccount{inflight: make(map[uint64]bool), sent: make(map[uint64]string)}
+		m.accounts[addr] = a
+	}
+	return a, nil
+}
+
+// Next 为地址分配下一个 nonce，分配的 nonce 必须通过 Release 释放或被广播
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - address: string 发送方地址
+//
+// Returns:
+//   - uint64: 分配的 nonce，优先使用已释放的 nonce
+//   - error: 无效的地址或读取初始 nonce 失败时返回错误
+func (m *NonceManager) Next(ctx context.Context, address string) (uint64, error) {
+	a, err := m.account(address)
+	if err != nil {
+		return 0, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.loaded {
+		pending, err := m.c.GetTransactionCount(ctx, address, "pending")
+		if err != nil {
+			return 0, err
+		}
+		a.next, a.loaded = pending, true
+	}
+	var nonce uint64
+	if len(a.released) > 0 {
+		nonce, a.released = a.released[0], a.released[1:]
+	} else {
+		nonce = a.next
+		a.next++
+	}
+	a.inflight[nonce] = true
+	return nonce, nil
+}
+
+// Release 释放分配后没有广播的 nonce，释放的 nonce 会优先分配给下一笔交易
+//
+// Parameters:
+//   - address: string 发送方地址
+//   - nonce: uint64 Next 分配的 nonce
+func (m *NonceManager) Release(address string, nonce uint64) {
+	a, err := m.account(address)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.release(nonce)
+}
+
+// release 释放 nonce，调用时必须持有 a.mu
+//
+// 已广播过交易的 nonce（如 FillGap 的替换交易被拒绝）仍被原交易占用，只结束占用而不重新分配。
+func (a *nonceAccount) release(nonce uint64) {
+	if !a.inflight[nonce] {
+		return
+	}
+	delete(a.inflight, nonce)
+	if _, ok := a.sent[nonce]; ok {
+		return
+	}
+	if nonce+1 == a.next {
+		// 释放的是最后一个 nonce 时直接回退，连同末尾已释放的 nonce 一起回收
+		a.next--
+		for len(a.released) > 0 && a.released[len(a.released)-1]+1 == a.next {
+			a.released = a.released[:len(a.released)-1]
+			a.next--
+		}
+		return
+	}
+	i, _ := slices.BinarySearch(a.released, nonce)
+	a.released = slices.Insert(a.released, i, nonce)
+}
+
+// markSent 记录已广播的交易
+func (m *NonceManager) markSent(address string, nonce uint64, hash string) {
+	a, err := m.account(address)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.inflight, nonce)
+	if i, ok := slices.BinarySearch(a.released, nonce); ok {
+		a.released = slices.Delete(a.released, i, i+1)
+	}
+	a.next = max(a.next, nonce+1)
+	a.sent[nonce] = hash
+}
+
+// Resync 从节点重新读取地址 pending 状态的交易数作为下一个 nonce，丢弃本地已释放的 nonce
+//
+// 交易被丢弃后调用 Resync 会复用这些 nonce；交易池中还有排队的交易时应使用 Gaps 和 FillGap 填补空缺。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - address: string 发送方地址
+//
+// Returns:
+//   - error: 无效的地址或节点连接错误
+func (m *NonceManager) Resync(ctx context.Context, address string) error {
+	return m.sync(ctx, address, true)
+}
+
+// sync 从节点读取 pending 状态的交易数，reset 为 false 时只向前推进本地 nonce
+func (m *NonceManager) sync(ctx context.Context, address string, reset bool) error {
+	a, err := m.account(address)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pending, err := m.c.GetTransactionCount(ctx, address, "pending")
+	if err != nil {
+		return err
+	}
+	if reset || !a.loaded {
+		a.next, a.released, a.loaded = pending, nil, true
+		// 节点交易池中没有的交易以节点为准，这些 nonce 可以重新分配
+		for n := range a.sent {
+			if n >= pending {
+				delete(a.sent, n)
+			}
+		}
+		return nil
+	}
+	a.next = max(a.next, pending)
+	a.released = slices.DeleteFunc(a.released, func(n uint64) bool { return n < pending })
+	return nil
+}
+
+// Send 分配 nonce、签名并广播交易
+//
+// 节点返回 nonce too low 时与节点同步并使用新的 nonce 重新签名，最多重试3次；
+// 返回 already known 时交易已在交易池中，视为发送成功；其他被节点拒绝的交易释放 nonce。
+// 连接错误时无法确定交易是否已广播，nonce 保持占用，可以通过 Gaps 检查。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - address: string 发送方地址，必须与签名者一致
+//   - sign: SignFunc 使用分配的 nonce 构建并签名交易
+//
+// Returns:
+//   - string: 交易哈希
+//   - uint64: 交易使用的 nonce
+//   - error: 可能的错误：
+//   - 无效的地址
+//   - 签名失败
+//   - 节点拒绝交易（*RPCError）
+//   - 节点连接错误
+func (m *NonceManager) Send(ctx context.Context, address string, sign SignFunc) (string, uint64, error) {
+	for attempt := 0; ; attempt++ {
+		nonce, err := m.Next(ctx, address)
+		if err != nil {
+			return "", 0, err
+		}
+		hash, err := m.send(ctx, address, nonce, sign)
+		if isNonceTooLow(err) && attempt < maxNonceRetries {
+			if err := m.sync(ctx, address, false); err != nil {
+				return "", 0, err
+			}
+			continue
+		}
+		return hash, nonce, err
+	}
+}
+
+// send 使用已分配的 nonce 签名并广播交易，根据结果更新 nonce 状态
+func (m *NonceManager) send(ctx context.Context, address string, nonce uint64, sign SignFunc) (string, error) {
+	raw, err := sign(ctx, nonce)
+	if err != nil {
+		m.Release(address, nonce)
+		return "", err
+	}
+	hash, err := rawTxHash(raw)
+	if err != nil {
+		m.Release(address, nonce)
+		return "", err
+	}
+
+	_, err = m.c.SendRawTransaction(ctx, raw)
+	var rpcErr *RPCError
+	switch {
+	case err == nil:
+		m.markSent(address, nonce, hash)
+		return hash, nil
+	case isAlreadyKnown(err):
+		// 交易已在交易池中，本地计数可能落后于节点
+		m.markSent(address, nonce, hash)
+		m.sync(ctx, address, false)
+		return hash, nil
+	case isNonceTooLow(err):
+		// nonce 已被其他交易使用，不再分配
+		m.markSent(address, nonce, "")
+		return "", err
+	case errors.As(err, &rpcErr):
+		m.Release(address, nonce)
+		return "", err
+	default:
+		m.markSent(address, nonce, hash)
+		return "", err
+	}
+}
+
+// rawTxHash 解码已签名的交易并计算交易哈希
+func rawTxHash(raw string) (string, error) {
+	data, err := hexutil.Decode(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid transaction data: %v", err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return "", fmt.Errorf("invalid transaction data: %v", err)
+	}
+	return tx.Hash().Hex(), nil
+}
+
+// Gaps 检测地址在已打包的 nonce 之后的空缺，空缺会阻塞更高 nonce 的交易被打包
+//
+// 空缺包括：本地已释放但尚未重新分配的 nonce、广播后节点找不到的交易（被丢弃或没有送达）。
+// 正在签名的 nonce 和其他发送方使用的 nonce 不算空缺。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - address: string 发送方地址
+//
+// Returns:
+//   - []uint64: 按升序排列的空缺 nonce，没有通过管理器发送过交易时为空
+//   - error: 无效的地址或节点连接错误
+func (m *NonceManager) Gaps(ctx context.Context, address string) ([]uint64, error) {
+	a, err := m.account(address)
+	if err != nil {
+		return nil, err
+	}
+	mined, err := m.c.GetTransactionCount(ctx, address, "latest")
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	next := a.next
+	released := slices.Clone(a.released)
+	inflight := make(map[uint64]bool, len(a.inflight))
+	for n := range a.inflight {
+		inflight[n] = true
+	}
+	sent := make(map[uint64]string, len(a.sent))
+	for n, hash := range a.sent {
+		if n < mined {
+			delete(a.sent, n) // 已打包，不再需要跟踪
+			continue
+		}
+		sent[n] = hash
+	}
+	a.mu.Unlock()
+
+	batch := m.c.Batch(ctx)
+	lookups := make(map[uint64]*BatchResult[*eth.Transaction])
+	var gaps []uint64
+	for n := mined; n < next; n++ {
+		hash, ok := sent[n]
+		switch {
+		case inflight[n]:
+			// 正在签名，尚未广播
+		case !ok:
+			// 没有记录的 nonce 由其他发送方使用，只有本地释放的才是空缺
+			if _, found := slices.BinarySearch(released, n); found {
+				gaps = append(gaps, n)
+			}
+		case hash == "":
+			// 广播时节点返回 nonce too low，已被其他交易使用
+		default:
+			lookups[n] = batch.GetTransactionByHash(hash)
+		}
+	}
+	if batch.Len() > 0 {
+		if err := batch.Send(); err != nil {
+			return nil, err
+		}
+	}
+	for n, lookup := range lookups {
+		if _, err := lookup.Result(); errors.Is(err, ErrNotFound) {
+			gaps = append(gaps, n)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	slices.Sort(gaps)
+	return gaps, nil
+}
+
+// FillGap 使用指定的 nonce 发送交易，用于填补被丢弃的交易造成的空缺或替换卡住的交易
+//
+// 通常 sign 构建一笔发给自己的零金额交易；替换交易池中已有的交易时，费用需要比原交易高至少10%。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - address: string 发送方地址
+//   - nonce: uint64 要填补的 nonce，必须已经分配过
+//   - sign: SignFunc 使用该 nonce 构建并签名交易
+//
+// Returns:
+//   - string: 交易哈希
+//   - error: 可能的错误：
+//   - 无效的地址或尚未分配的 nonce
+//   - nonce 已被打包的交易使用
+//   - 节点拒绝交易或连接错误
+func (m *NonceManager) FillGap(ctx context.Context, address string, nonce uint64, sign SignFunc) (string, error) {
+	a, err := m.account(address)
+	if err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	if !a.loaded || nonce >= a.next {
+		a.mu.Unlock()
+		return "", fmt.Errorf("nonce %d has not been allocated", nonce)
+	}
+	if a.inflight[nonce] {
+		a.mu.Unlock()
+		return "", fmt.Errorf("nonce %d is being used by another transaction", nonce)
+	}
+	// 已释放的 nonce 不再分配给其他交易
+	if i, ok := slices.BinarySearch(a.released, nonce); ok {
+		a.released = slices.Delete(a.released, i, i+1)
+	}
+	a.inflight[nonce] = true
+	a.mu.Unlock()
+
+	return m.send(ctx, address, nonce, sign)
+}
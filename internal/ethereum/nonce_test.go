@@ -0,0 +1,344 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// nonceNode 模拟交易池：pending 为节点认为的下一个 nonce，mined 为已打包的交易数，
+// reject 按 nonce 返回预设的错误信息，pool 记录交易池中的交易
+type nonceNode struct {
+	mu      sync.Mutex
+	pending uint64
+	mined   uint64
+	reject  map[uint64]string
+	pool    map[string]uint64
+}
+
+func newNonceNode(pending uint64) *nonceNode {
+	return &nonceNode{pending: pending, mined: pending, reject: make(map[uint64]string), pool: make(map[string]uint64)}
+}
+
+func (n *nonceNode) requester(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var params []json.RawMessage
+	b, _ := json.Marshal(r.Params)
+	json.Unmarshal(b, &params)
+	rpcError := func(message string) (*jsonrpc.RawResponse, error) {
+		rpcErr := json.RawMessage(fmt.Sprintf(`{"code":-32000,"message":%q}`, message))
+		return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+	}
+
+	switch r.Method {
+	case "eth_getTransactionCount":
+		var tag string
+		json.Unmarshal(params[1], &tag)
+		count := n.mined
+		if tag == "pending" {
+			count = n.pending
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(fmt.Sprintf(`"0x%x"`, count))}, nil
+	case "eth_sendRawTransaction":
+		var raw string
+		json.Unmarshal(params[0], &raw)
+		tx := new(types.Transaction)
+		tx.UnmarshalBinary(hexutil.MustDecode(raw))
+		if msg, ok := n.reject[tx.Nonce()]; ok {
+			delete(n.reject, tx.Nonce())
+			return rpcError(msg)
+		}
+		if tx.Nonce() < n.mined {
+			return rpcError("nonce too low")
+		}
+		if _, ok := n.pool[tx.Hash().Hex()]; ok {
+			return rpcError("already known")
+		}
+		n.pool[tx.Hash().Hex()] = tx.Nonce()
+		for _, ok := n.poolHas(n.pending); ok; _, ok = n.poolHas(n.pending) {
+			n.pending++
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"` + tx.Hash().Hex() + `"`)}, nil
+	case "eth_getTransactionByHash":
+		var hash string
+		json.Unmarshal(params[0], &hash)
+		nonce, ok := n.pool[hash]
+		if !ok {
+			return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`null`)}, nil
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(fmt.Sprintf(
+			`{"hash":%q,"nonce":"0x%x","from":"0x0000000000000000000000000000000000000001","gas":"0x5208","gasPrice":"0x1","input":"0x","value":"0x0","v":"0x1","r":"0x1","s":"0x1"}`, hash, nonce))}, nil
+	}
+	return rpcError("method not found")
+}
+
+// poolHas 返回交易池中使用指定 nonce 的交易哈希
+func (n *nonceNode) poolHas(nonce uint64) (string, bool) {
+	for hash, v := range n.pool {
+		if v == nonce {
+			return hash, true
+		}
+	}
+	return "", false
+}
+
+// drop 从交易池中删除使用指定 nonce 的交易
+func (n *nonceNode) drop(nonce uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	hash, _ := n.poolHas(nonce)
+	delete(n.pool, hash)
+	n.pending = min(n.pending, nonce)
+}
+
+// testSignFunc 返回签名零金额转账的 SignFunc，value 用于区分同一 nonce 的不同交易
+func testSignFunc(t *testing.T, value int64) SignFunc {
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress(testOther)
+	return func(ctx context.Context, nonce uint64) (string, error) {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.LegacyTx{
+			Nonce: nonce, To: &to, Value: big.NewInt(value), Gas: 21000, GasPrice: big.NewInt(1),
+		})
+		if err != nil {
+			return "", err
+		}
+		raw, _ := tx.MarshalBinary()
+		return hexutil.Encode(raw), nil
+	}
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	n := newNonceNode(7)
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+	m := c.NewNonceManager()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		nonces []uint64
+	)
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, nonce, err := m.Send(context.Background(), testHolder, testSignFunc(t, int64(i)))
+			assert.NoError(t, err)
+			mu.Lock()
+			nonces = append(nonces, nonce)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// 20笔交易使用连续且不重复的 nonce
+	assert.ElementsMatch(t, []uint64{7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26}, nonces)
+	assert.Equal(t, uint64(27), n.pending)
+
+	_, err := m.Next(context.Background(), "0x123")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+func TestNonceManagerErrors(t *testing.T) {
+	n := newNonceNode(3)
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+	m := c.NewNonceManager()
+	ctx := context.Background()
+
+	// 签名失败和被节点拒绝的 nonce 被释放并重新分配
+	_, _, err := m.Send(ctx, testHolder, func(ctx context.Context, nonce uint64) (string, error) {
+		return "", fmt.Errorf("signer unavailable")
+	})
+	assert.Error(t, err)
+	n.reject[3] = "insufficient funds for gas * price + value"
+	_, nonce, err := m.Send(ctx, testHolder, testSignFunc(t, 1))
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, uint64(3), nonce)
+	_, nonce, err = m.Send(ctx, testHolder, testSignFunc(t, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), nonce)
+
+	// 其他发送方使用了 nonce 4、5，节点返回 nonce too low 后同步并重新分配
+	n.mined, n.pending = 6, 6
+	_, nonce, err = m.Send(ctx, testHolder, testSignFunc(t, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), nonce)
+
+	// already known 视为发送成功
+	n.reject[7] = "already known"
+	hash, nonce, err := m.Send(ctx, testHolder, testSignFunc(t, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), nonce)
+	assert.NotEmpty(t, hash)
+
+	// 释放中间的 nonce 后优先分配，释放最后的 nonce 时回退
+	a, _ := m.Next(ctx, testHolder)
+	b, _ := m.Next(ctx, testHolder)
+	assert.Equal(t, []uint64{8, 9}, []uint64{a, b})
+	m.Release(testHolder, a)
+	next, _ := m.Next(ctx, testHolder)
+	assert.Equal(t, uint64(8), next)
+	m.Release(testHolder, next)
+	m.Release(testHolder, b)
+	next, _ = m.Next(ctx, testHolder)
+	assert.Equal(t, uint64(8), next)
+	m.Release(testHolder, next)
+
+	// Resync 以节点为准
+	n.pending = 20
+	assert.NoError(t, m.Resync(ctx, testHolder))
+	next, _ = m.Next(ctx, testHolder)
+	assert.Equal(t, uint64(20), next)
+}
+
+func TestNonceManagerGaps(t *testing.T) {
+	n := newNonceNode(0)
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+	m := c.NewNonceManager()
+	ctx := context.Background()
+
+	gaps, err := m.Gaps(ctx, testHolder)
+	assert.NoError(t, err)
+	assert.Empty(t, gaps)
+
+	for i := range 4 {
+		_, _, err := m.Send(ctx, testHolder, testSignFunc(t, int64(i)))
+		assert.NoError(t, err)
+	}
+	inflight, _ := m.Next(ctx, testHolder)
+	assert.Equal(t, uint64(4), inflight)
+
+	// nonce 1 的交易被丢弃，nonce 0 已打包
+	n.drop(1)
+	n.mined = 1
+	gaps, err = m.Gaps(ctx, testHolder)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1}, gaps)
+
+	// 填补空缺后节点的 pending nonce 恢复连续
+	_, err = m.FillGap(ctx, testHolder, 1, testSignFunc(t, 100))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), n.pending)
+	gaps, err = m.Gaps(ctx, testHolder)
+	assert.NoError(t, err)
+	assert.Empty(t, gaps)
+
+	// 正在使用、已打包和未分配的 nonce 不能填补
+	_, err = m.FillGap(ctx, testHolder, inflight, testSignFunc(t, 100))
+	assert.Error(t, err)
+	_, err = m.FillGap(ctx, testHolder, 0, testSignFunc(t, 100))
+	assert.Error(t, err)
+	_, err = m.FillGap(ctx, testHolder, 50, testSignFunc(t, 100))
+	assert.Error(t, err)
+
+	// 释放的 nonce 也是空缺
+	m.Release(testHolder, inflight)
+	a, _ := m.Next(ctx, testHolder)
+	b, _ := m.Next(ctx, testHolder)
+	m.Release(testHolder, a)
+	gaps, err = m.Gaps(ctx, testHolder)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{a}, gaps)
+	m.Release(testHolder, b)
+}
+
+func TestTxBuilderNonces(t *testing.T) {
+	n := newNonceNode(2)
+	signing := newSigningNode()
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		switch r.Method {
+		case "eth_getTransactionCount", "eth_sendRawTransaction", "eth_getTransactionByHash":
+			return n.requester(ctx, r)
+		}
+		return signing.requester(ctx, r)
+	}}, Endpoint{URL: "ws://node"})
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	m := c.NewNonceManager()
+	b := c.NewTxBuilder(signer, &TxBuilderOptions{Nonces: m})
+
+	tx, err := b.Send(context.Background(), TxRequest{To: testOther})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), tx.Nonce())
+	tx, err = b.Send(context.Background(), TxRequest{To: testOther})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), tx.Nonce())
+
+	// 丢弃的交易通过发给自己的零金额交易填补
+	n.drop(2)
+	gaps, err := m.Gaps(context.Background(), signer.Address())
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{2}, gaps)
+	tx, err = b.FillNonce(context.Background(), 2, TxRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), tx.Nonce())
+	assert.Equal(t, common.HexToAddress(signer.Address()), *tx.To())
+	assert.Equal(t, uint64(21000), tx.Gas())
+	assert.Equal(t, uint64(4), n.pending)
+}
+
+func TestNonceManagerFillGapRejected(t *testing.T) {
+	n := newNonceNode(7)
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+	m := c.NewNonceManager()
+	ctx := context.Background()
+
+	_, nonce, err := m.Send(ctx, testHolder, testSignFunc(t, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), nonce)
+
+	// 替换交易被拒绝，nonce 仍被交易池中的原交易占用，不能分配给新的交易
+	n.reject[7] = "replacement transaction underpriced"
+	_, err = m.FillGap(ctx, testHolder, 7, testSignFunc(t, 2))
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+	next, err := m.Next(ctx, testHolder)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), next)
+	gaps, err := m.Gaps(ctx, testHolder)
+	assert.NoError(t, err)
+	assert.Empty(t, gaps)
+
+	// 签名失败同样不释放已广播的 nonce
+	_, err = m.FillGap(ctx, testHolder, 7, func(ctx context.Context, nonce uint64) (string, error) {
+		return "", fmt.Errorf("signer unavailable")
+	})
+	assert.Error(t, err)
+
+	// 填补本地释放的 nonce 失败时，nonce 重新回到空缺中
+	m.Release(testHolder, next)
+	a, _ := m.Next(ctx, testHolder)
+	b, _ := m.Next(ctx, testHolder)
+	assert.Equal(t, []uint64{8, 9}, []uint64{a, b})
+	m.Release(testHolder, a)
+	n.reject[8] = "insufficient funds for gas * price + value"
+	_, err = m.FillGap(ctx, testHolder, 8, testSignFunc(t, 3))
+	assert.Error(t, err)
+	next, _ = m.Next(ctx, testHolder)
+	assert.Equal(t, uint64(8), next)
+
+	// Resync 后节点交易池中没有的 nonce 可以重新分配
+	m.Release(testHolder, next)
+	m.Release(testHolder, b)
+	n.drop(7)
+	assert.NoError(t, m.Resync(ctx, testHolder))
+	next, _ = m.Next(ctx, testHolder)
+	assert.Equal(t, uint64(7), next)
+	m.Release(testHolder, next)
+	next, _ = m.Next(ctx, testHolder)
+	assert.Equal(t, uint64(7), next)
+}
@@ -3,109 +3,329 @@ package ethereum
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
+	"sync"
 	"time"
 
 	"github.com/justinwongcn/go-ethlibs/node"
 )
 
-// managePool 管理连接池，定期清理空闲连接和进行健康检查
+// pooledConn 连接池中的一个节点连接
+//
+// 底层 node.Client 没有 Close 方法，websocket 连接的生命周期由创建时传入的上下文控制，
+// 因此每个连接都持有自己的 cancel 函数，调用 close 即可真正关闭底层连接。
+type pooledConn struct {
+	node.Client
+	close    context.CancelFunc // 关闭底层连接
+	lastUsed time.Time          // 最近一次归还到连接池的时间
+	broken   bool               // 健康检查失败的连接，归还时直接关闭而不是复用
+}
+
+// dialFunc 创建新连接的函数
+type dialFunc func(ctx context.Context) (*pooledConn, error)
+
+// connPool 有界连接池，具有以下特性：
+//   - 同时存在的连接数不超过 maxConns，连接耗尽时调用者阻塞等待，直到有连接释放或上下文结束
+//   - 最多保留 maxIdle 个空闲连接，超出的连接在归还时直接关闭
+//   - 空闲时间超过 idleTimeout 的连接由 evictIdle 关闭
+type connPool struct {
+	dial        dialFunc
+	maxConns    int
+	maxIdle     int
+	idleTimeout time.Duration
+
+	sem    chan struct{} // 容量为 maxConns，每个被借出的连接占用一个令牌
+	mu     sync.Mutex
+	idle   []*pooledConn // 空闲连接，按归还时间升序排列
+	closed bool
+}
+
+// newConnPool 创建一个有界连接池
 //
 // Parameters:
-//   - ctx: context.Context 用于控制连接池生命周期的上下文
+//   - dial: dialFunc 创建新连接的函数
+//   - maxConns: int 最大连接数
+//   - maxIdle: int 最大空闲连接数
+//   - idleTimeout: time.Duration 空闲连接的超时时间
 //
-// 该方法会启动一个后台协程，按照 idleTimeout 间隔定期：
-//   - 检查连接池状态
-//   - 执行连接健康检查（如果启用）
-func (c *Client) managePool(ctx context.Context) {
-	ticker := time.NewTicker(c.idleTimeout)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			// 执行健康检查
-			if c.healthCheck {
-				c.checkConnections(ctx)
-			}
-		}
+// Returns:
+//   - *connPool: 初始化后的连接池
+func newConnPool(dial dialFunc, maxConns, maxIdle int, idleTimeout time.Duration) *connPool {
+	return &connPool{
+		dial:        dial,
+		maxConns:    maxConns,
+		maxIdle:     maxIdle,
+		idleTimeout: idleTimeout,
+		sem:         make(chan struct{}, maxConns),
 	}
 }
 
-// getConnection 从连接池获取一个连接
+// get 从连接池借出一个连接
 //
 // Parameters:
-//   - ctx: context.Context 用于控制请求的上下文
+//   - ctx: context.Context 用于控制等待和建立连接的上下文
 //
 // Returns:
-//   - node.Client: 从连接池获取的客户端连接
+//   - *pooledConn: 借出的连接，使用完毕后必须调用 put 归还
 //   - error: 可能的错误：
-//   - 连接池已满
+//   - 等待空闲连接时上下文结束
+//   - 连接池已关闭
 //   - 无法创建新连接
-func (c *Client) getConnection(_ context.Context) (node.Client, error) {
-	// 检查是否达到最大连接数
-	if atomic.LoadInt32(&c.connCount) >= int32(c.maxConns) {
-		return nil, fmt.Errorf("connection pool is full (max: %d)", c.maxConns)
+func (p *connPool) get(ctx context.Context) (*pooledConn, error) {
+	// 等待令牌，保证同时借出的连接数不超过 maxConns
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w (max: %d): %w", ErrPoolExhausted, p.maxConns, ctx.Err())
 	}
 
-	// 从连接池获取连接
-	conn := c.connPool.Get()
-	if conn == nil {
-		return nil, fmt.Errorf("failed to get connection from pool")
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.sem
+		return nil, fmt.Errorf("connection pool is closed")
 	}
 
-	// 增加连接计数
-	atomic.AddInt32(&c.connCount, 1)
+	// 优先复用最近归还的空闲连接，较旧的连接留给 evictIdle 清理
+	now := time.Now()
+	for n := len(p.idle); n > 0; n = len(p.idle) {
+		conn := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		if p.expired(conn, now) {
+			conn.close()
+			continue
+		}
+		p.mu.Unlock()
+		return conn, nil
+	}
+	p.mu.Unlock()
 
-	return conn.(node.Client), nil
+	// 没有可用的空闲连接，创建新连接
+	conn, err := p.dial(ctx)
+	if err != nil {
+		<-p.sem
+		return nil, err
+	}
+	return conn, nil
 }
 
-// releaseConnection 释放连接回连接池
+// put 将连接归还到连接池
 //
 // Parameters:
-//   - conn: node.Client 要释放回连接池的客户端连接
+//   - conn: *pooledConn 通过 get 借出的连接
 //
-// 该方法会：
-//   - 减少活跃连接计数
-//   - 将连接放回连接池以供复用
-func (c *Client) releaseConnection(conn node.Client) {
-	if conn == nil {
+// 如果连接池已关闭、连接已损坏或空闲连接数已达 maxIdle，连接会被直接关闭。
+func (p *connPool) put(conn *pooledConn) {
+	// 先放回空闲列表再释放令牌，保证被唤醒的等待者能拿到这个连接
+	defer func() { <-p.sem }()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || conn.broken || len(p.idle) >= p.maxIdle {
+		conn.close()
 		return
 	}
 
-	// 减少连接计数
-	atomic.AddInt32(&c.connCount, -1)
+	conn.lastUsed = time.Now()
+	p.idle = append(p.idle, conn)
+}
 
-	// 将连接放回连接池
-	c.connPool.Put(conn)
+// evictIdle 关闭空闲时间超过 idleTimeout 的连接
+//
+// Returns:
+//   - int: 被关闭的连接数
+func (p *connPool) evictIdle() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	n := 0
+	// 空闲列表按归还时间升序排列，过期的连接都在前部
+	for n < len(p.idle) && p.expired(p.idle[n], now) {
+		p.idle[n].close()
+		n++
+	}
+	p.idle = append(p.idle[:0], p.idle[n:]...)
+	return n
 }
 
-// checkConnections 检查连接池中的连接健康状态
+// checkIdle 对所有空闲连接执行健康检查，关闭检查失败的连接
 //
 // Parameters:
 //   - ctx: context.Context 用于控制健康检查的上下文
+//   - ping: func(context.Context, node.Client) error 检查单个连接的函数
 //
-// 该方法会：
-//   - 获取一个连接进行测试
-//   - 执行区块号查询来验证连接是否正常
-//   - 如果连接异常，创建新的连接替换
-func (c *Client) checkConnections(ctx context.Context) {
-	// 获取一个连接进行健康检查
-	conn, err := c.getConnection(ctx)
-	if err != nil {
-		return
+// node.Client 支持并发使用，因此检查期间连接仍可被借出；
+// 如果失败的连接已被借出，会在归还时关闭。
+func (p *connPool) checkIdle(ctx context.Context, ping func(context.Context, node.Client) error) {
+	p.mu.Lock()
+	conns := append([]*pooledConn(nil), p.idle...)
+	p.mu.Unlock()
+
+	for _, conn := range conns {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := ping(ctx, conn.Client); err != nil {
+			p.discard(conn)
+		}
 	}
-	defer c.releaseConnection(conn)
+}
+
+// discard 标记连接已损坏，如果连接仍在空闲列表中则立即关闭
+func (p *connPool) discard(conn *pooledConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	conn.broken = true
+	for i, c := range p.idle {
+		if c == conn {
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			conn.close()
+			return
+		}
+	}
+}
+
+// evictAll 关闭所有空闲连接
+func (p *connPool) evictAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, conn := range p.idle {
+		conn.close()
+	}
+	p.idle = nil
+}
+
+// idleCount 返回当前空闲连接数
+func (p *connPool) idleCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.idle)
+}
 
-	// 执行一个简单的请求来检查连接是否正常
-	_, err = conn.BlockNumber(ctx)
+// activeCount 返回当前借出的连接数
+func (p *connPool) activeCount() int {
+	return len(p.sem)
+}
+
+// close 关闭连接池及所有空闲连接，借出中的连接会在归还时关闭
+func (p *connPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	for _, conn := range p.idle {
+		conn.close()
+	}
+	p.idle = nil
+}
+
+// expired 判断空闲连接是否已超时，调用者需持有 p.mu
+func (p *connPool) expired(conn *pooledConn, now time.Time) bool {
+	return p.idleTimeout > 0 && now.Sub(conn.lastUsed) > p.idleTimeout
+}
+
+// managePool 管理连接池，定期清理空闲连接和进行健康检查
+//
+// Parameters:
+//   - ctx: context.Context 用于控制连接池生命周期的上下文
+//
+// 该方法会启动一个后台协程：
+//   - 按照 idleTimeout 间隔关闭空闲超时的连接
+//   - 按照 healthCheckInterval 间隔执行连接健康检查（如果启用），并重新探测不健康的端点
+//
+// 上下文结束时关闭所有端点的连接池。
+func (c *Client) managePool(ctx context.Context) {
+	evictTicker := time.NewTicker(c.idleTimeout)
+	defer evictTicker.Stop()
+	healthTicker := time.NewTicker(c.healthCheckInterval)
+	defer healthTicker.Stop()
+
+	defer func() {
+		for _, ep := range c.endpoints {
+			ep.pool.close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-evictTicker.C:
+			// 清理空闲超时的连接
+			for _, ep := range c.endpoints {
+				ep.pool.evictIdle()
+			}
+		case <-healthTicker.C:
+			// 执行健康检查
+			if c.healthCheck {
+				c.checkConnections(ctx)
+			}
+			// 重新探测不健康的端点
+			c.probeEndpoints(ctx)
+		}
+	}
+}
+
+// dialConnection 创建一个新的节点连接
+//
+// Parameters:
+//   - ctx: context.Context 用于控制建立连接的上下文
+//   - nodeURL: string 节点URL
+//
+// Returns:
+//   - *pooledConn: 新建的连接，其生命周期受客户端上下文控制
+//   - error: 无法连接到节点时返回错误
+func (c *Client) dialConnection(ctx context.Context, nodeURL string) (*pooledConn, error) {
+	// HTTP 端点没有长连接，使用能识别状态码的请求器
+	if isHTTPURL(nodeURL) {
+		client, err := newHTTPNodeClient(nodeURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial %s: %w", nodeURL, err)
+		}
+		return &pooledConn{Client: client, close: func() {}}, nil
+	}
+
+	// 连接的生命周期独立于单次请求，派生自客户端上下文，以便单独关闭
+	connCtx, cancel := context.WithCancel(c.ctx)
+	stop := context.AfterFunc(ctx, cancel)
+
+	client, err := node.NewClient(connCtx, nodeURL)
+	if !stop() && err == nil {
+		// 请求上下文在建立连接期间结束，连接已被关闭
+		err = ctx.Err()
+	}
 	if err != nil {
-		// 连接异常，创建新的连接
-		newConn, err := node.NewClient(ctx, c.nodeURL)
-		if err == nil {
-			c.connPool.Put(newConn)
+		cancel()
+		return nil, fmt.Errorf("failed to dial %s: %w", nodeURL, err)
+	}
+
+	return &pooledConn{Client: client, close: cancel}, nil
+}
+
+// checkConnections 检查所有健康端点连接池中的连接状态
+//
+// Parameters:
+//   - ctx: context.Context 用于控制健康检查的上下文
+//
+// 该方法会对每个空闲连接执行区块号查询，关闭查询失败的连接并计入端点失败次数，
+// 后续请求会按需创建新的连接替换。
+func (c *Client) checkConnections(ctx context.Context) {
+	for _, ep := range c.endpoints {
+		if !ep.isHealthy() {
+			continue
 		}
+		ep.pool.checkIdle(ctx, func(ctx context.Context, conn node.Client) error {
+			ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			_, err := conn.BlockNumber(ctx)
+			if err != nil {
+				ep.recordFailure(err, c.maxFailures)
+			}
+			return err
+		})
 	}
 }
@@ -0,0 +1,126 @@
+package ethereum
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/justinwongcn/go-ethlibs/node"
+	"github.com/stretchr/testify/assert"
+)
+
+// requesterFunc 将函数适配为 node.Requester，用于在测试中模拟节点
+type requesterFunc func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error)
+
+func (f requesterFunc) Request(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+	return f(ctx, r)
+}
+
+// newTestPool 创建一个使用模拟连接的连接池，返回已创建和已关闭的连接计数
+func newTestPool(maxConns, maxIdle int, idleTimeout time.Duration) (*connPool, *int32, *int32) {
+	var dialed, closed int32
+	dial := func(ctx context.Context) (*pooledConn, error) {
+		client, err := node.NewCustomClient(requesterFunc(func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+			return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(`"0x1"`)}, nil
+		}), nil)
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt32(&dialed, 1)
+		return &pooledConn{Client: client, close: func() { atomic.AddInt32(&closed, 1) }}, nil
+	}
+	return newConnPool(dial, maxConns, maxIdle, idleTimeout), &dialed, &closed
+}
+
+func TestConnPoolBlocksUntilRelease(t *testing.T) {
+	pool, dialed, _ := newTestPool(1, 1, time.Minute)
+
+	conn, err := pool.get(context.Background())
+	assert.NoError(t, err)
+
+	// 连接耗尽时应阻塞直到上下文超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = pool.get(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// 连接归还后等待者应拿到同一个连接
+	done := make(chan *pooledConn)
+	go func() {
+		c, err := pool.get(context.Background())
+		assert.NoError(t, err)
+		done <- c
+	}()
+	time.Sleep(10 * time.Millisecond)
+	pool.put(conn)
+
+	select {
+	case got := <-done:
+		assert.Same(t, conn, got, "应复用已归还的连接")
+	case <-time.After(time.Second):
+		t.Fatal("等待连接超时")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(dialed))
+}
+
+func TestConnPoolMaxIdle(t *testing.T) {
+	pool, dialed, closed := newTestPool(3, 1, time.Minute)
+
+	conns := make([]*pooledConn, 3)
+	for i := range conns {
+		conn, err := pool.get(context.Background())
+		assert.NoError(t, err)
+		conns[i] = conn
+	}
+	for _, conn := range conns {
+		pool.put(conn)
+	}
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(dialed))
+	assert.Equal(t, int32(2), atomic.LoadInt32(closed), "超出 maxIdle 的连接应被关闭")
+	assert.Equal(t, 1, pool.idleCount())
+	assert.Equal(t, 0, pool.activeCount())
+}
+
+func TestConnPoolEvictIdle(t *testing.T) {
+	pool, _, closed := newTestPool(2, 2, 10*time.Millisecond)
+
+	conn, err := pool.get(context.Background())
+	assert.NoError(t, err)
+	pool.put(conn)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, pool.evictIdle())
+	assert.Equal(t, int32(1), atomic.LoadInt32(closed))
+	assert.Equal(t, 0, pool.idleCount())
+}
+
+func TestConnPoolCheckIdle(t *testing.T) {
+	pool, _, closed := newTestPool(2, 2, time.Minute)
+
+	conn, err := pool.get(context.Background())
+	assert.NoError(t, err)
+	pool.put(conn)
+
+	pool.checkIdle(context.Background(), func(ctx context.Context, c node.Client) error {
+		return context.Canceled
+	})
+	assert.Equal(t, int32(1), atomic.LoadInt32(closed), "健康检查失败的连接应被关闭")
+	assert.Equal(t, 0, pool.idleCount())
+}
+
+func TestConnPoolClose(t *testing.T) {
+	pool, _, closed := newTestPool(2, 2, time.Minute)
+
+	conn, err := pool.get(context.Background())
+	assert.NoError(t, err)
+	pool.close()
+
+	pool.put(conn)
+	assert.Equal(t, int32(1), atomic.LoadInt32(closed), "连接池关闭后归还的连接应被关闭")
+
+	_, err = pool.get(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit 端点的客户端限流配置，使用令牌桶算法：
+//   - 令牌按 RequestsPerSecond 的速率补充，桶中最多保存 Burst 个令牌
+//   - 每个请求按方法消耗 MethodWeights 中配置的令牌数，批量请求消耗其中所有调用的令牌之和
+//   - 令牌不足时请求排队等待，等待期间上下文结束则放弃请求并归还令牌
+//   - 端点以 429 应答并给出 Retry-After 时，该端点暂停发送请求直到指定时间
+type RateLimit struct {
+	RequestsPerSecond float64        // 每秒补充的令牌数，为0时不限流（仍遵守 Retry-After）
+	Burst             int            // 令牌桶容量，即允许的突发请求数，为0时等于 RequestsPerSecond（至少为1）
+	MethodWeights     map[string]int // 各 JSON-RPC 方法消耗的令牌数，未列出的方法消耗1个；可按节点服务商的计算单元定价设置
+}
+
+// rateLimiter 单个端点的令牌桶，令牌可以被预支为负数，后来的请求按预支的顺序等待
+type rateLimiter struct {
+	rate    float64
+	burst   float64
+	weights map[string]int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time // 最近一次补充令牌的时间
+	paused time.Time // 端点要求暂停发送请求的截止时间（来自 Retry-After）
+}
+
+// newRateLimiter 根据限流配置创建令牌桶，初始时桶是满的
+func newRateLimiter(cfg RateLimit) *rateLimiter {
+	l := &rateLimiter{weights: cfg.MethodWeights, last: time.Now()}
+	if cfg.RequestsPerSecond > 0 {
+		l.rate = cfg.RequestsPerSecond
+		l.burst = float64(cfg.Burst)
+		if l.burst <= 0 {
+			l.burst = math.Max(1, math.Floor(cfg.RequestsPerSecond))
+		}
+		l.tokens = l.burst
+	}
+	return l
+}
+
+// cost 返回一组调用消耗的令牌数
+func (l *rateLimiter) cost(methods []string) float64 {
+	total := 0
+	for _, method := range methods {
+		if w, ok := l.weights[method]; ok && w >= 0 {
+			total += w
+		} else {
+			total++
+		}
+	}
+	return float64(total)
+}
+
+// refill 按经过的时间补充令牌，调用方需持有锁
+func (l *rateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+// wait 为一组调用预支令牌，令牌不足或端点暂停期间阻塞等待
+//
+// Parameters:
+//   - ctx: context.Context 控制等待的上下文
+//   - methods: []string 将要发送的 JSON-RPC 方法
+//
+// Returns:
+//   - error: 上下文在等待期间结束，或上下文的截止时间早于可以发送的时间时返回错误，此时预支的令牌被归还
+func (l *rateLimiter) wait(ctx context.Context, methods []string) error {
+	now := time.Now()
+	l.mu.Lock()
+	var cost float64
+	var delay time.Duration
+	if l.rate > 0 {
+		cost = l.cost(methods)
+		l.refill(now)
+		l.tokens -= cost
+		if l.tokens < 0 {
+			delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		}
+	}
+	if d := l.paused.Sub(now); d > delay {
+		delay = d
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		l.cancel(cost)
+		return fmt.Errorf("rate limit wait of %s exceeds context deadline: %w", delay.Round(time.Millisecond), context.DeadlineExceeded)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel(cost)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancel 归还未使用的令牌
+func (l *rateLimiter) cancel(cost float64) {
+	if cost == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.tokens = math.Min(l.burst, l.tokens+cost)
+}
+
+// isPaused 判断端点是否处于 Retry-After 要求的暂停期间
+func (l *rateLimiter) isPaused(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return now.Before(l.paused)
+}
+
+// pause 暂停发送请求 d 时长，已有更晚的暂停截止时间时保持不变
+func (l *rateLimiter) pause(d time.Duration) {
+	until := time.Now().Add(d)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.paused) {
+		l.paused = until
+	}
+}
+
+// maxRetryAfter Retry-After 的上限，避免错误的响应头让端点长时间停止服务
+const maxRetryAfter = 5 * time.Minute
+
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数和 HTTP 日期两种格式
+//
+// Returns:
+//   - time.Duration: 需要等待的时间，不超过 maxRetryAfter；响应头缺失或无效时为0
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	}
+	return min(max(d, 0), maxRetryAfter)
+}
+
+// retryAfter 返回端点在限流错误中要求的等待时间，没有要求时为0
+func retryAfter(err error) time.Duration {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.retryAfter
+	}
+	return 0
+}
@@ -0,0 +1,182 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	l := newRateLimiter(RateLimit{RequestsPerSecond: 50, Burst: 2, MethodWeights: map[string]int{"eth_getLogs": 3}})
+	ctx := context.Background()
+
+	// 桶中的令牌允许突发请求
+	start := time.Now()
+	assert.NoError(t, l.wait(ctx, []string{"eth_blockNumber"}))
+	assert.NoError(t, l.wait(ctx, []string{"eth_blockNumber"}))
+	assert.Less(t, time.Since(start), 10*time.Millisecond)
+
+	// 令牌用完后按速率等待，权重为3的方法需要等待3个令牌补充（60毫秒）
+	assert.NoError(t, l.wait(ctx, []string{"eth_getLogs"}))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// 未配置速率时不限流
+	l = newRateLimiter(RateLimit{})
+	for range 100 {
+		assert.NoError(t, l.wait(ctx, []string{"eth_call"}))
+	}
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	l := newRateLimiter(RateLimit{RequestsPerSecond: 1, Burst: 1})
+	assert.NoError(t, l.wait(context.Background(), []string{"eth_call"}))
+
+	// 上下文的截止时间早于可以发送的时间时立即返回，并归还令牌
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, l.wait(ctx, []string{"eth_call"}), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 10*time.Millisecond)
+	assert.InDelta(t, 0, l.tokens, 0.1)
+
+	// 等待期间上下文被取消
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	assert.ErrorIs(t, l.wait(ctx, []string{"eth_call"}), context.Canceled)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("Mon, 01 Jan 2024 00:00:30 GMT", now))
+	assert.Equal(t, maxRetryAfter, parseRetryAfter("86400", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func TestEndpointPausedByRetryAfter(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return nil, &httpStatusError{code: http.StatusTooManyRequests, retryAfter: 50 * time.Millisecond}
+			}
+			return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"0x10"`)}, nil
+		},
+	}, Endpoint{URL: "ws://node"})
+	c.maxFailures = 10
+	c.retry = RetryPolicy{MaxAttempts: 2}
+
+	// 重试没有退避时间，但端点在 Retry-After 期间暂停
+	start := time.Now()
+	n, err := c.GetLatestBlockNumber(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), n)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestPausedEndpointFailover(t *testing.T) {
+	var primaryCalls, backupCalls int32
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://primary": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+			atomic.AddInt32(&primaryCalls, 1)
+			return nil, &httpStatusError{code: http.StatusTooManyRequests, retryAfter: time.Minute}
+		},
+		"ws://backup": blockNumberRequester("0x20", &backupCalls),
+	}, Endpoint{URL: "ws://primary", Priority: 0}, Endpoint{URL: "ws://backup", Priority: 1})
+	c.maxFailures = 10
+
+	_, err := c.GetLatestBlockNumber(context.Background())
+	assert.Equal(t, ErrorClassRateLimited, ClassifyError(err))
+
+	// 主端点暂停期间请求直接转移到备用端点，不等待 Retry-After
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for range 3 {
+		n, err := c.GetLatestBlockNumber(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(32), n)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&primaryCalls))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&backupCalls))
+}
+
+func TestBatchHTTPRetryAfter(t *testing.T) {
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&posts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		var requests []jsonrpc.Request
+		json.NewDecoder(r.Body).Decode(&requests)
+		responses := make([]jsonrpc.RawResponse, len(requests))
+		for i, req := range requests {
+			responses[i] = jsonrpc.RawResponse{JSONRPC: "2.0", ID: req.ID, Result: []byte(`"0x1"`)}
+		}
+		json.NewEncoder(w).Encode(responses)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(context.Background(), srv.URL, &ClientOptions{
+		Retry: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	start := time.Now()
+	balances, err := c.GetBalancesBig(context.Background(), []string{"0x0000000000000000000000000000000000000001"}, "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), balances["0x0000000000000000000000000000000000000001"].Int64())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&posts))
+}
+
+func TestHTTPRetryAfter(t *testing.T) {
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if atomic.AddInt32(&posts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(jsonrpc.RawResponse{JSONRPC: "2.0", ID: req.ID, Result: []byte(`"0x10"`)})
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(context.Background(), srv.URL, &ClientOptions{
+		Retry: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	// 单个请求的 429 同样暂停端点，重试在 Retry-After 之后发送
+	start := time.Now()
+	n, err := c.GetLatestBlockNumber(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), n)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&posts))
+
+	// 不重试时返回带状态码的错误，而不是 JSON 解码错误
+	c.retry = RetryPolicy{MaxAttempts: 1}
+	atomic.StoreInt32(&posts, 0)
+	_, err = c.GetLatestBlockNumber(context.Background())
+	var statusErr *httpStatusError
+	if assert.ErrorAs(t, err, &statusErr) {
+		assert.Equal(t, http.StatusTooManyRequests, statusErr.code)
+		assert.Equal(t, time.Second, statusErr.retryAfter)
+	}
+	assert.Equal(t, ErrorClassRateLimited, ClassifyError(err))
+}
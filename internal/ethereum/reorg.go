@@ -0,0 +1,275 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// 链头跟踪的默认参数
+const (
+	defaultHeadWindow       = 128             // 保留的最近区块数，即可以处理的最大重组深度
+	defaultHeadPollInterval = 4 * time.Second // 轮询最新区块的间隔
+)
+
+// ErrNoCommonAncestor 新链头无法与已跟踪的区块连接（重组深度超过 Window 或空缺过大），
+// 需要 Reset 后从新链头重新开始跟踪，可以通过 errors.Is 判断
+var ErrNoCommonAncestor = errors.New("no common ancestor")
+
+// HeadEventType 链头事件的类型
+type HeadEventType int
+
+const (
+	// HeadApplied 区块被加入规范链
+	HeadApplied HeadEventType = iota
+	// HeadReverted 之前加入的区块因链重组被移出规范链，消费者需要撤销基于该区块的处理
+	HeadReverted
+)
+
+// String 返回事件类型的名称
+func (t HeadEventType) String() string {
+	switch t {
+	case HeadApplied:
+		return "applied"
+	case HeadReverted:
+		return "reverted"
+	default:
+		return fmt.Sprintf("HeadEventType(%d)", int(t))
+	}
+}
+
+// HeadEvent 链头变化事件
+type HeadEvent struct {
+	Type  HeadEventType // 事件类型
+	Block *eth.Block    // 被加入或移出规范链的区块，不包含交易列表
+}
+
+// HeadTrackerOptions 链头跟踪的配置选项，零值字段使用默认值
+type HeadTrackerOptions struct {
+	Window       int           // 保留的最近区块数，重组深度超过该值时无法找到共同祖先，默认128
+	PollInterval time.Duration // WatchHeads 轮询最新区块的间隔，默认4秒
+}
+
+// withDefaults 返回补全默认值后的选项
+func (o *HeadTrackerOptions) withDefaults() HeadTrackerOptions {
+	opts := HeadTrackerOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Window <= 0 {
+		opts.Window = defaultHeadWindow
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultHeadPollInterval
+	}
+	return opts
+}
+
+// HeadTracker 跟踪规范链的最近区块，具有以下特性：
+//   - 保存最近 Window 个连续区块的哈希
+//   - 新区块的 ParentHash 与已保存的区块不一致时，沿父区块回溯到共同祖先
+//   - 先按从高到低的顺序产生 reverted 事件，再按从低到高的顺序产生 applied 事件
+//   - 新区块与已保存的链头之间有空缺时，补齐中间的区块
+type HeadTracker struct {
+	c      *Client
+	window int
+
+	mu    sync.Mutex
+	chain []*eth.Block // 按区块号升序排列的连续区块
+}
+
+// NewHeadTracker 创建链头跟踪器
+//
+// Parameters:
+//   - opts: *HeadTrackerOptions 配置选项，为 nil 时使用默认值
+//
+// Returns:
+//   - *HeadTracker: 跟踪器实例，第一次 Update 的区块作为起点
+func (c *Client) NewHeadTracker(opts *HeadTrackerOptions) *HeadTracker {
+	return &HeadTracker{c: c, window: opts.withDefaults().Window}
+}
+
+// Head 返回当前跟踪的链头，尚未跟踪任何区块时返回 nil
+func (t *HeadTracker) Head() *eth.Block {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.chain) == 0 {
+		return nil
+	}
+	return t.chain[len(t.chain)-1]
+}
+
+// Reset 清空已跟踪的区块，下一次 Update 的区块作为新的起点
+func (t *HeadTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.chain = nil
+}
+
+// Poll 获取最新区块并更新链头
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//
+// Returns:
+//   - []HeadEvent: 本次更新产生的事件，链头未变化时为空
+//   - error: 可能的错误：
+//   - 节点连接错误
+//   - Update 返回的错误，无法与已跟踪的区块连接时可以通过 errors.Is 判断 ErrNoCommonAncestor
+func (t *HeadTracker) Poll(ctx context.Context) ([]HeadEvent, error) {
+	head, err := t.c.GetBlockByNumber(ctx, "latest", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	return t.Update(ctx, head)
+}
+
+// Update 处理一个新的链头区块
+//
+// Parameters:
+//   - ctx: context.Context 用于获取父区块的上下文
+//   - head: *eth.Block 新的链头，可以来自轮询或 SubscribeNewHeads
+//
+// Returns:
+//   - []HeadEvent: 本次更新产生的事件，区块已在规范链上时为空
+//   - error: 可能的错误：
+//   - 区块缺少区块号或哈希
+//   - ErrNoCommonAncestor: 重组深度超过 Window，或与链头的空缺超过 256 个区块，需要 Reset 后重新开始
+//   - 获取父区块失败，包装节点返回的错误
+//
+// 返回错误时跟踪状态保持不变。
+func (t *HeadTracker) Update(ctx context.Context, head *eth.Block) ([]HeadEvent, error) {
+	if head == nil || head.Number == nil || head.Hash == nil {
+		return nil, fmt.Errorf("head block must include number and hash")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.chain) == 0 {
+		t.chain = []*eth.Block{head}
+		return []HeadEvent{{Type: HeadApplied, Block: head}}, nil
+	}
+
+	number := head.Number.UInt64()
+	if known := t.at(number); known != nil && known.Hash.String() == head.Hash.String() {
+		return nil, nil
+	}
+	tip := t.chain[len(t.chain)-1].Number.UInt64()
+	if number > tip && number-tip > maxHeadBackfill {
+		return nil, fmt.Errorf("%w: head %d is %d blocks ahead of tracked head %d", ErrNoCommonAncestor, number, number-tip, tip)
+	}
+
+	// 沿父区块回溯，直到父区块与已保存的区块一致
+	branch := []*eth.Block{head}
+	for {
+		first := branch[0]
+		n := first.Number.UInt64()
+		if parent := t.at(n - 1); parent != nil && parent.Hash.String() == first.ParentHash.String() {
+			break
+		}
+		if n == 0 || n-1 < t.chain[0].Number.UInt64() {
+			return nil, fmt.Errorf("%w within the last %d blocks", ErrNoCommonAncestor, t.window)
+		}
+
+		parent, err := t.c.GetBlockByHash(ctx, first.ParentHash.String(), false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent block %s: %w", first.ParentHash.String(), err)
+		}
+		if parent == nil || parent.Number == nil || parent.Hash == nil || parent.Number.UInt64() != n-1 {
+			return nil, fmt.Errorf("invalid parent block %s of block %d", first.ParentHash.String(), n)
+		}
+		branch = append([]*eth.Block{parent}, branch...)
+	}
+
+	ancestor := branch[0].Number.UInt64() - 1
+	keep := int(ancestor-t.chain[0].Number.UInt64()) + 1
+
+	events := make([]HeadEvent, 0, len(t.chain)-keep+len(branch))
+	for i := len(t.chain) - 1; i >= keep; i-- {
+		events = append(events, HeadEvent{Type: HeadReverted, Block: t.chain[i]})
+	}
+	for _, b := range branch {
+		events = append(events, HeadEvent{Type: HeadApplied, Block: b})
+	}
+
+	t.chain = append(t.chain[:keep:keep], branch...)
+	if len(t.chain) > t.window {
+		t.chain = t.chain[len(t.chain)-t.window:]
+	}
+	return events, nil
+}
+
+// at 返回已保存的指定区块号的区块，调用方需持有锁
+func (t *HeadTracker) at(number uint64) *eth.Block {
+	base := t.chain[0].Number.UInt64()
+	if number < base || number-base >= uint64(len(t.chain)) {
+		return nil
+	}
+	return t.chain[number-base]
+}
+
+// WatchHeads 轮询最新区块并发送链头事件
+//
+// Parameters:
+//   - ctx: context.Context 控制轮询的生命周期，结束后通道被关闭
+//   - opts: *HeadTrackerOptions 配置选项，为 nil 时使用默认值
+//
+// Returns:
+//   - <-chan HeadEvent: 链头事件；发生链重组时先发送被撤销区块的 reverted 事件，再发送新区块的 applied 事件
+//   - error: 第一次获取最新区块失败时返回错误
+//
+// 获取区块失败时在下一个轮询周期重试；无法找到共同祖先时从最新区块重新开始跟踪，这两种情况通过 ClientOptions.Logger 记录。
+func (c *Client) WatchHeads(ctx context.Context, opts *HeadTrackerOptions) (<-chan HeadEvent, error) {
+	o := opts.withDefaults()
+	tracker := c.NewHeadTracker(&o)
+	events, err := tracker.Poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan HeadEvent, subscriptionBuffer)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(o.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			for _, ev := range events {
+				if !send(ctx, out, ev) {
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			head, err := c.GetBlockByNumber(ctx, "latest", false)
+			if err != nil {
+				events = nil
+				if ctx.Err() == nil {
+					c.logWarn("failed to poll latest block", "err", err)
+				}
+				continue
+			}
+			events, err = tracker.Update(ctx, head)
+			switch {
+			case errors.Is(err, ErrNoCommonAncestor):
+				c.logWarn("head tracker lost the canonical chain, restarting from the latest block", "err", err)
+				tracker.Reset()
+				events, _ = tracker.Update(ctx, head)
+			case err != nil && ctx.Err() == nil:
+				c.logWarn("failed to update head tracker", "err", err)
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,137 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testChain 模拟的链，区块哈希由区块号和分叉标识生成
+type testChain struct {
+	mu     sync.Mutex
+	blocks map[string]string // 区块哈希到区块 JSON
+	latest string            // 最新区块的哈希
+}
+
+// chainHash 返回分叉 fork 上区块 number 的哈希，fork 为0表示原始链
+func chainHash(number uint64, fork int) string {
+	return fmt.Sprintf("0x%032x%032x", fork, number)
+}
+
+// add 在分叉 fork 上添加区块 from..to，from 的父区块位于 parentFork 上
+func (c *testChain) add(from, to uint64, fork, parentFork int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n := from; n <= to; n++ {
+		parent := chainHash(n-1, fork)
+		if n == from {
+			parent = chainHash(n-1, parentFork)
+		}
+		c.blocks[chainHash(n, fork)] = fmt.Sprintf(`{"number":"0x%x","hash":%q,"parentHash":%q}`, n, chainHash(n, fork), parent)
+		c.latest = chainHash(n, fork)
+	}
+}
+
+func (c *testChain) requester(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch r.Method {
+	case "eth_getBlockByHash":
+		return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(c.blocks[firstParam(r).(string)])}, nil
+	case "eth_getBlockByNumber":
+		return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(c.blocks[c.latest])}, nil
+	}
+	return nil, fmt.Errorf("unexpected request %s", r.Method)
+}
+
+// eventsString 将事件格式化为 "applied 5/1" 的形式，便于比较
+func eventsString(events []HeadEvent) []string {
+	out := make([]string, len(events))
+	for i, ev := range events {
+		var fork, number uint64
+		fmt.Sscanf(ev.Block.Hash.String()[2:], "%032x%032x", &fork, &number)
+		out[i] = fmt.Sprintf("%s %d/%d", ev.Type, number, fork)
+	}
+	return out
+}
+
+func TestHeadTrackerReorg(t *testing.T) {
+	chain := &testChain{blocks: map[string]string{}}
+	chain.add(1, 5, 0, 0)
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": chain.requester}, Endpoint{URL: "ws://node"})
+	tracker := c.NewHeadTracker(&HeadTrackerOptions{Window: 4})
+	ctx := context.Background()
+
+	events, err := tracker.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"applied 5/0"}, eventsString(events))
+
+	// 链头未变化
+	events, err = tracker.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	// 空缺的区块通过父区块补齐
+	chain.add(6, 8, 0, 0)
+	events, err = tracker.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"applied 6/0", "applied 7/0", "applied 8/0"}, eventsString(events))
+
+	// 从区块6开始的分叉替换了区块7和8，新链更长
+	chain.add(7, 9, 1, 0)
+	events, err = tracker.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reverted 8/0", "reverted 7/0", "applied 7/1", "applied 8/1", "applied 9/1"}, eventsString(events))
+	assert.Equal(t, chainHash(9, 1), tracker.Head().Hash.String())
+
+	// 同高度的区块被替换
+	chain.add(9, 9, 2, 1)
+	events, err = tracker.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reverted 9/1", "applied 9/2"}, eventsString(events))
+
+	// 重组深度超过窗口时无法找到共同祖先，状态保持不变
+	chain.add(3, 10, 3, 0)
+	_, err = tracker.Poll(ctx)
+	assert.ErrorContains(t, err, "no common ancestor within the last 4 blocks")
+	assert.ErrorIs(t, err, ErrNoCommonAncestor)
+	assert.Equal(t, chainHash(9, 2), tracker.Head().Hash.String())
+}
+
+func TestWatchHeads(t *testing.T) {
+	chain := &testChain{blocks: map[string]string{}}
+	chain.add(1, 3, 0, 0)
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": chain.requester}, Endpoint{URL: "ws://node"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := c.WatchHeads(ctx, &HeadTrackerOptions{PollInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+
+	receive := func(n int) []HeadEvent {
+		var got []HeadEvent
+		for len(got) < n {
+			select {
+			case ev := <-events:
+				got = append(got, ev)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out, received %v", eventsString(got))
+			}
+		}
+		return got
+	}
+
+	assert.Equal(t, []string{"applied 3/0"}, eventsString(receive(1)))
+	chain.add(4, 4, 0, 0)
+	assert.Equal(t, []string{"applied 4/0"}, eventsString(receive(1)))
+	chain.add(4, 5, 1, 0)
+	assert.Equal(t, []string{"reverted 4/0", "applied 4/1", "applied 5/1"}, eventsString(receive(3)))
+
+	cancel()
+	for range events {
+	}
+}
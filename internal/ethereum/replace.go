@@ -0,0 +1,168 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// minReplacementBump 替换交易的费用相对原交易的最低提高比例（百分比），与 geth 交易池的 PriceBump 默认值一致
+const minReplacementBump = 10
+
+// SpeedUp 以更高的费用重新签名并发送未打包的交易，nonce、接收方、金额、数据和 gas 限制保持不变
+//
+// 新交易的费用取以下两者中较高的：原交易费用提高 bumpPercent（不低于10%，满足节点的替换规则），
+// 以及当前网络的快速档费用（传统交易为 eth_gasPrice）。原交易和新交易只有一笔会被打包，
+// 可以使用 WaitForAnyReceipt 同时跟踪两笔交易。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - hash: string 要加速的交易哈希，必须由当前 Signer 发送
+//   - bumpPercent: uint64 费用提高的百分比，小于10时按10计算
+//
+// Returns:
+//   - *types.Transaction: 已发送的替换交易
+//   - error: 可能的错误：
+//   - 交易不存在、已打包或不是由当前 Signer 发送
+//   - 交易类型不支持替换（如 blob 交易）
+//   - 节点拒绝替换交易（如 replacement transaction underpriced）
+//   - 节点连接错误
+func (b *TxBuilder) SpeedUp(ctx context.Context, hash string, bumpPercent uint64) (*types.Transaction, error) {
+	orig, req, err := b.replaceable(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return b.replace(ctx, orig, req, bumpPercent)
+}
+
+// Cancel 发送一笔相同 nonce、发给自己的零金额交易替换未打包的交易，费用规则与 SpeedUp 相同
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - hash: string 要取消的交易哈希，必须由当前 Signer 发送
+//
+// Returns:
+//   - *types.Transaction: 已发送的取消交易，被打包后原交易不会再被打包
+//   - error: 与 SpeedUp 相同
+func (b *TxBuilder) Cancel(ctx context.Context, hash string) (*types.Transaction, error) {
+	orig, req, err := b.replaceable(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	req.To, req.Value, req.Data, req.Gas, req.AccessList = b.From(), nil, "", transferGas, nil
+	return b.replace(ctx, orig, req, minReplacementBump)
+}
+
+// replaceable 查询原交易并转换为相同参数的交易请求，校验交易未打包且由当前 Signer 发送
+func (b *TxBuilder) replaceable(ctx context.Context, hash string) (*eth.Transaction, TxRequest, error) {
+	orig, err := b.c.GetTransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, TxRequest{}, err
+	}
+	if orig.BlockNumber != nil {
+		return nil, TxRequest{}, fmt.Errorf("transaction %s is already mined in block %d", hash, orig.BlockNumber.UInt64())
+	}
+	if common.HexToAddress(orig.From.String()) != common.HexToAddress(b.From()) {
+		return nil, TxRequest{}, fmt.Errorf("transaction %s was sent by %s, not by signer %s", hash, orig.From.String(), b.From())
+	}
+
+	nonce := orig.Nonce.UInt64()
+	req := TxRequest{
+		Value: orig.Value.Big(),
+		Data:  orig.Input.String(),
+		Nonce: &nonce,
+		Gas:   orig.Gas.UInt64(),
+	}
+	if orig.To != nil {
+		req.To = orig.To.String()
+	}
+	var txType uint64
+	if orig.Type != nil {
+		txType = orig.Type.UInt64()
+	}
+	switch txType {
+	case types.LegacyTxType:
+		req.Type = TxTypeLegacy
+	case types.AccessListTxType:
+		req.Type = TxTypeAccessList
+	case types.DynamicFeeTxType:
+		req.Type = TxTypeDynamicFee
+	default:
+		// blob 交易的 sidecar 无法从节点获取，并且交易池不允许其他类型的交易替换 blob 交易
+		return nil, TxRequest{}, fmt.Errorf("transaction %s of type %d cannot be replaced", hash, txType)
+	}
+	if orig.AccessList != nil && req.Type != TxTypeLegacy {
+		req.AccessList = make(types.AccessList, len(*orig.AccessList))
+		for i, entry := range *orig.AccessList {
+			keys := make([]common.Hash, len(entry.StorageKeys))
+			for j, key := range entry.StorageKeys {
+				keys[j] = common.HexToHash(key.String())
+			}
+			req.AccessList[i] = types.AccessTuple{Address: common.HexToAddress(entry.Address.String()), StorageKeys: keys}
+		}
+	}
+	return orig, req, nil
+}
+
+// replace 按替换规则设置费用，签名并发送替换交易
+func (b *TxBuilder) replace(ctx context.Context, orig *eth.Transaction, req TxRequest, bumpPercent uint64) (*types.Transaction, error) {
+	bump := max(bumpPercent, minReplacementBump)
+	if req.Type == TxTypeDynamicFee {
+		fees, err := b.c.SuggestFees(ctx, b.opts.FeeOracle)
+		if err != nil {
+			return nil, err
+		}
+		tip := maxBig(bumpFee(quantityOrZero(orig.MaxPriorityFeePerGas), bump), fees.Fast.MaxPriorityFeePerGas)
+		req.MaxPriorityFeePerGas = tip
+		req.MaxFeePerGas = maxBig(bumpFee(quantityOrZero(orig.MaxFeePerGas), bump), fees.Fast.MaxFeePerGas, tip)
+	} else {
+		gasPrice, err := b.c.GasPriceBig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.GasPrice = maxBig(bumpFee(quantityOrZero(orig.GasPrice), bump), gasPrice)
+	}
+
+	tx, err := b.Sign(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.SendTx(ctx, tx); err != nil {
+		return nil, err
+	}
+	if b.opts.Nonces != nil {
+		// nonce 管理器跟踪最新的版本，用于检测空缺
+		b.opts.Nonces.markSent(b.From(), tx.Nonce(), tx.Hash().Hex())
+	}
+	return tx, nil
+}
+
+// bumpFee 将费用提高指定百分比并向上取整
+func bumpFee(fee *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Quo(bumped, big.NewInt(100))
+}
+
+// quantityOrZero 将可选的 QUANTITY 转换为 *big.Int，缺失时为0
+func quantityOrZero(q *eth.Quantity) *big.Int {
+	if q == nil {
+		return new(big.Int)
+	}
+	return q.Big()
+}
+
+// maxBig 返回最大值
+func maxBig(first *big.Int, rest ...*big.Int) *big.Int {
+	m := first
+	for _, v := range rest {
+		if v.Cmp(m) > 0 {
+			m = v
+		}
+	}
+	return new(big.Int).Set(m)
+}
@@ -0,0 +1,121 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const replaceTxHash = "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+
+func TestSpeedUpAndCancel(t *testing.T) {
+	n := newSigningNode()
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	m := c.NewNonceManager()
+	b := c.NewTxBuilder(signer, &TxBuilderOptions{Nonces: m})
+	ctx := context.Background()
+	pending := func(fees string) {
+		n.results["eth_getTransactionByHash"] = fmt.Sprintf(
+			`{"hash":%q,"type":"0x2","from":%q,"to":%q,"nonce":"0x7","gas":"0xc350","value":"0x3e8","input":"0xa9059cbb",%s,"accessList":[{"address":%q,"storageKeys":["0x%064x"]}]}`,
+			replaceTxHash, signer.Address(), testToken, fees, testToken, 1)
+	}
+
+	// 原交易的费用提高后高于当前网络的快速档费用（优先费30，maxFeePerGas 4030）
+	pending(`"maxPriorityFeePerGas":"0x64","maxFeePerGas":"0x1388"`)
+	tx, err := b.SpeedUp(ctx, replaceTxHash, 25)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+	assert.Equal(t, uint64(7), tx.Nonce())
+	assert.Equal(t, "125", tx.GasTipCap().String())
+	assert.Equal(t, "6250", tx.GasFeeCap().String())
+	assert.Equal(t, uint64(50000), tx.Gas())
+	assert.Equal(t, "1000", tx.Value().String())
+	assert.Equal(t, common.HexToAddress(testToken), *tx.To())
+	assert.Equal(t, []byte{0xa9, 0x05, 0x9c, 0xbb}, tx.Data())
+	assert.Len(t, tx.AccessList(), 1)
+	assert.Equal(t, tx.Hash(), n.sent[len(n.sent)-1].Hash())
+	assert.Empty(t, n.estimates)
+
+	// 低于10%的提高比例按10%计算并向上取整，当前网络费用更高时使用网络费用
+	pending(`"maxPriorityFeePerGas":"0x7","maxFeePerGas":"0x3e8"`)
+	tx, err = b.SpeedUp(ctx, replaceTxHash, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "30", tx.GasTipCap().String())
+	assert.Equal(t, "4030", tx.GasFeeCap().String())
+
+	// 取消交易发给自己，零金额、无数据，nonce 管理器记录最新的版本
+	tx, err = b.Cancel(ctx, replaceTxHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), tx.Nonce())
+	assert.Equal(t, common.HexToAddress(signer.Address()), *tx.To())
+	assert.Equal(t, "0", tx.Value().String())
+	assert.Empty(t, tx.Data())
+	assert.Equal(t, uint64(21000), tx.Gas())
+	assert.Empty(t, tx.AccessList())
+	account, _ := m.account(signer.Address())
+	assert.Equal(t, tx.Hash().Hex(), account.sent[7])
+
+	// 传统交易按 gasPrice 提高
+	n.results["eth_getTransactionByHash"] = fmt.Sprintf(
+		`{"hash":%q,"type":"0x0","from":%q,"to":%q,"nonce":"0x7","gas":"0x5208","value":"0x0","input":"0x","gasPrice":"0x3b9aca00"}`,
+		replaceTxHash, signer.Address(), testOther)
+	tx, err = b.Cancel(ctx, replaceTxHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.LegacyTxType), tx.Type())
+	assert.Equal(t, "1100000000", tx.GasPrice().String())
+
+	// 已打包、其他账户发送和 blob 交易不能替换
+	for _, result := range []string{
+		fmt.Sprintf(`{"hash":%q,"type":"0x0","from":%q,"nonce":"0x7","gas":"0x5208","value":"0x0","input":"0x","gasPrice":"0x1","blockNumber":"0x10"}`, replaceTxHash, signer.Address()),
+		fmt.Sprintf(`{"hash":%q,"type":"0x0","from":%q,"nonce":"0x7","gas":"0x5208","value":"0x0","input":"0x","gasPrice":"0x1"}`, replaceTxHash, testOther),
+		fmt.Sprintf(`{"hash":%q,"type":"0x3","from":%q,"nonce":"0x7","gas":"0x5208","value":"0x0","input":"0x","maxFeePerGas":"0x1","maxPriorityFeePerGas":"0x1"}`, replaceTxHash, signer.Address()),
+		`null`,
+	} {
+		n.results["eth_getTransactionByHash"] = result
+		_, err := b.SpeedUp(ctx, replaceTxHash, 10)
+		assert.Error(t, err, result)
+	}
+}
+
+func TestWaitForAnyReceipt(t *testing.T) {
+	replaced := false
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		var params []string
+		b, _ := json.Marshal(r.Params)
+		json.Unmarshal(b, &params)
+		result := "null"
+		switch r.Method {
+		case "eth_blockNumber":
+			result = `"0xc"`
+		case "eth_getTransactionCount":
+			result = `"0x8"`
+		case "eth_getTransactionReceipt":
+			// 只有替换交易被打包
+			if params[0] == replaceTxHash && !replaced {
+				result = fmt.Sprintf(`{"transactionHash":%q,"blockHash":"0x%064x","blockNumber":"0xa","status":"0x1"}`, replaceTxHash, 0xa)
+			}
+		case "eth_getTransactionByHash":
+			result = fmt.Sprintf(`{"hash":%q,"from":%q,"nonce":"0x7"}`, params[0], watchFrom)
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(result)}, nil
+	}}, Endpoint{URL: "ws://node"})
+
+	receipt, err := c.WaitForAnyReceipt(context.Background(), []string{watchTxHash, replaceTxHash}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, replaceTxHash, receipt.TransactionHash.String())
+
+	// nonce 被两笔交易之外的交易使用
+	replaced = true
+	_, err = c.WaitForAnyReceipt(context.Background(), []string{watchTxHash, replaceTxHash}, 1)
+	assert.ErrorContains(t, err, "all 2 transactions were dropped or replaced")
+
+	_, err = c.WaitForAnyReceipt(context.Background(), nil, 1)
+	assert.Error(t, err)
+}
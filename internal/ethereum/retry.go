@@ -0,0 +1,244 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/node"
+)
+
+// 重试策略的默认参数，与 config.yaml 中的默认值一致
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 200 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+)
+
+// ErrorClass 请求错误的类别，决定错误是否值得重试
+type ErrorClass int
+
+const (
+	// ErrorClassPermanent 重试也无法成功的错误，如合约回滚、参数错误、数据不存在
+	ErrorClassPermanent ErrorClass = iota
+	// ErrorClassRateLimited 节点限流
+	ErrorClassRateLimited
+	// ErrorClassTimeout 请求超时
+	ErrorClassTimeout
+	// ErrorClassConnection 连接被重置、关闭或无法建立
+	ErrorClassConnection
+)
+
+// String 返回错误类别的名称
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassPermanent:
+		return "permanent"
+	case ErrorClassRateLimited:
+		return "rate-limited"
+	case ErrorClassTimeout:
+		return "timeout"
+	case ErrorClassConnection:
+		return "connection"
+	default:
+		return fmt.Sprintf("ErrorClass(%d)", int(c))
+	}
+}
+
+// Retryable 返回该类别的错误是否值得重试
+func (c ErrorClass) Retryable() bool {
+	return c == ErrorClassRateLimited || c == ErrorClassTimeout || c == ErrorClassConnection
+}
+
+// RetryPolicy 请求失败后的重试策略，具有以下特性：
+//   - 只重试幂等的读请求，SendRawTransaction 只在 RetrySendRawTransaction 为 true 时重试
+//   - 只重试限流、超时和连接错误，合约回滚、参数错误等节点业务错误立即返回
+//   - 重试间隔从 InitialBackoff 开始指数增长，不超过 MaxBackoff，并加入随机抖动
+//   - 每次重试重新选择端点，失败的端点被标记为不健康后请求会转移到其他端点
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大尝试次数（包含第一次请求），小于等于1时不重试
+	InitialBackoff time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxBackoff     time.Duration // 重试等待时间的上限
+
+	// Classify 判断错误的类别，为 nil 时使用 ClassifyError
+	Classify func(err error) ErrorClass
+	// RetrySendRawTransaction 是否重试 SendRawTransaction；重复广播同一笔已签名交易不会重复执行，
+	// 但第一次请求可能已被节点接受，重试时节点可能返回 "already known" 等错误
+	RetrySendRawTransaction bool
+}
+
+// classify 使用策略配置的分类函数判断错误类别
+func (p RetryPolicy) classify(err error) ErrorClass {
+	if p.Classify != nil {
+		return p.Classify(err)
+	}
+	return ClassifyError(err)
+}
+
+// backoff 返回第 attempt 次请求失败后、下一次请求前的等待时间
+//
+// 等待时间为 InitialBackoff * 2^(attempt-1)，不超过 MaxBackoff，实际值在其一半到全部之间随机，
+// 避免大量客户端在节点恢复时同时重试。
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// nonIdempotentMethods 重复发送可能产生副作用的 JSON-RPC 方法，默认不重试
+var nonIdempotentMethods = map[string]bool{
+	"eth_sendRawTransaction": true,
+	"eth_sendTransaction":    true,
+}
+
+// ClassifyError 判断请求错误的类别
+//
+// Parameters:
+//   - err: error 请求返回的错误
+//
+// Returns:
+//   - ErrorClass: 错误类别：
+//   - 节点限流（错误码 -32005、429，HTTP 状态码 429）为 ErrorClassRateLimited
+//   - 请求超时、网关超时为 ErrorClassTimeout
+//   - 连接被重置、关闭、拒绝，或 HTTP 502/503 为 ErrorClassConnection
+//   - 其他错误，包括合约回滚、参数错误、数据不存在，为 ErrorClassPermanent
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassPermanent
+	}
+	if errors.Is(err, ErrNotFound) || errors.Is(err, node.ErrBlockNotFound) || errors.Is(err, node.ErrTransactionNotFound) ||
+		errors.Is(err, ErrInvalidAddress) || errors.Is(err, ErrInvalidBlockTag) || errors.Is(err, ErrInvalidHash) {
+		return ErrorClassPermanent
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.code {
+		case http.StatusTooManyRequests:
+			return ErrorClassRateLimited
+		case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+			return ErrorClassTimeout
+		case http.StatusBadGateway, http.StatusServiceUnavailable:
+			return ErrorClassConnection
+		}
+		return ErrorClassPermanent
+	}
+
+	// 节点以 JSON-RPC 错误对象应答时只有限流和节点内部超时值得重试
+	if rpcErr := parseRPCError(err); rpcErr != nil {
+		msg := strings.ToLower(rpcErr.Message)
+		switch {
+		case isTooManyResultsMessage(msg):
+			// 日志查询结果过多需要缩小查询范围，原样重试没有意义；部分节点对此也使用限流错误码 -32005
+			return ErrorClassPermanent
+		case rpcErr.Code == -32005 || rpcErr.Code == 429 || isRateLimitMessage(msg):
+			return ErrorClassRateLimited
+		case strings.Contains(msg, "timeout") || strings.Contains(msg, "timed out"):
+			return ErrorClassTimeout
+		}
+		return ErrorClassPermanent
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTimeout
+	}
+	var opErr *net.OpError
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &opErr) {
+		return ErrorClassConnection
+	}
+
+	// 节点库包装后的错误不一定保留原始错误链，按错误信息兜底
+	msg := strings.ToLower(err.Error())
+	switch {
+	case isRateLimitMessage(msg):
+		return ErrorClassRateLimited
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "timed out"):
+		return ErrorClassTimeout
+	case strings.Contains(msg, "connection reset"), strings.Contains(msg, "connection refused"),
+		strings.Contains(msg, "broken pipe"), strings.Contains(msg, "use of closed network connection"),
+		strings.Contains(msg, "transport context finished"), strings.Contains(msg, "websocket: close"):
+		// 连接关闭后节点库以 "transport context finished" 结束等待中的请求
+		return ErrorClassConnection
+	}
+	return ErrorClassPermanent
+}
+
+// isRateLimitMessage 判断小写的错误信息是否表示限流
+func isRateLimitMessage(msg string) bool {
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests") ||
+		strings.Contains(msg, "status 429")
+}
+
+// httpStatusError HTTP 端点返回了非 200 状态码
+type httpStatusError struct {
+	code       int
+	body       []byte
+	retryAfter time.Duration // Retry-After 响应头要求的等待时间
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("request returned status %d: %s", e.code, e.body)
+}
+
+// transient 判断状态码是否表示端点暂时不可用（限流、超时、网关错误），此类响应体通常不是 JSON-RPC 响应
+func (e *httpStatusError) transient() bool {
+	switch e.code {
+	case http.StatusTooManyRequests, http.StatusRequestTimeout, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryConnection 在连接池中执行操作，操作失败且错误可重试时按重试策略等待后重试
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求和重试等待的上下文
+//   - idempotent: bool 操作是否可以安全地重复执行，为 false 时只执行一次
+//   - methods: []string 操作发送的 JSON-RPC 方法，用于计算限流消耗的令牌
+//   - fn: func(node.Client) (any, error) 在连接上执行的操作函数
+//
+// Returns:
+//   - any: 操作的返回值
+//   - error: 最后一次尝试的错误；等待重试期间上下文结束时同样返回最后一次尝试的错误
+func (c *Client) retryConnection(ctx context.Context, idempotent bool, methods []string, fn func(node.Client) (any, error)) (any, error) {
+	attempts := 1
+	if idempotent {
+		attempts = max(c.retry.MaxAttempts, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := c.tryEndpoints(ctx, methods, fn)
+		if err == nil || attempt >= attempts || ctx.Err() != nil || !c.retry.classify(err).Retryable() {
+			return result, err
+		}
+
+		timer := time.NewTimer(c.retry.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/justinwongcn/go-ethlibs/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{nil, ErrorClassPermanent},
+		{errors.New(`{"code":-32005,"message":"limit exceeded"}`), ErrorClassRateLimited},
+		{errors.New(`{"code":-32000,"message":"Too Many Requests"}`), ErrorClassRateLimited},
+		{&httpStatusError{code: 429}, ErrorClassRateLimited},
+		{errors.New(`{"code":-32000,"message":"request timed out"}`), ErrorClassTimeout},
+		{context.DeadlineExceeded, ErrorClassTimeout},
+		{&net.OpError{Op: "read", Err: syscall.ECONNRESET}, ErrorClassConnection},
+		{fmt.Errorf("error reading message: %w", io.ErrUnexpectedEOF), ErrorClassConnection},
+		{errors.New("transport context finished waiting for response: context canceled"), ErrorClassConnection},
+		{&httpStatusError{code: 503}, ErrorClassConnection},
+		{&httpStatusError{code: 503, body: []byte("too many connections")}, ErrorClassConnection},
+		{&httpStatusError{code: 502, body: []byte("query returned more than 10000 results")}, ErrorClassConnection},
+		{errors.New(`{"code":3,"message":"execution reverted","data":"0x"}`), ErrorClassPermanent},
+		{errors.New(`{"code":-32602,"message":"invalid argument 0"}`), ErrorClassPermanent},
+		{errors.New(`{"code":-32005,"message":"query returned more than 10000 results"}`), ErrorClassPermanent},
+		{wrapNodeError(node.ErrTransactionNotFound), ErrorClassPermanent},
+		{fmt.Errorf("%w: bad", ErrInvalidAddress), ErrorClassPermanent},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond} {
+		for range 20 {
+			d := p.backoff(attempt)
+			assert.GreaterOrEqual(t, d, want/2)
+			assert.LessOrEqual(t, d, want)
+		}
+	}
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.backoff(1))
+}
+
+// flakyRequester 前 failures 次请求返回 err，之后返回 result
+func flakyRequester(failures int32, err error, result string, calls *int32) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		if atomic.AddInt32(calls, 1) <= failures {
+			return nil, err
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"` + result + `"`)}, nil
+	}
+}
+
+func TestWithConnectionRetry(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": flakyRequester(2, io.ErrUnexpectedEOF, "0x10", &calls),
+	}, Endpoint{URL: "ws://node"})
+	c.maxFailures = 10
+	c.retry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	n, err := c.GetLatestBlockNumber(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), n)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// 尝试次数用完后返回最后一次的错误
+	atomic.StoreInt32(&calls, 0)
+	c.retry.MaxAttempts = 2
+	_, err = c.GetLatestBlockNumber(context.Background())
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWithConnectionNoRetry(t *testing.T) {
+	// 合约回滚不重试
+	var calls int32
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+			atomic.AddInt32(&calls, 1)
+			rpcErr := json.RawMessage(`{"code":3,"message":"execution reverted"}`)
+			return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+		},
+	}, Endpoint{URL: "ws://node"})
+	c.retry = RetryPolicy{MaxAttempts: 3}
+	_, err := c.CallWithMsg(context.Background(), CallMsg{To: "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"}, "")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls)
+
+	// 发送交易默认不重试，显式开启后重试
+	calls = 0
+	c = newTestClient(t, map[string]requesterFunc{
+		"ws://node": flakyRequester(1, io.ErrUnexpectedEOF, "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b", &calls),
+	}, Endpoint{URL: "ws://node"})
+	c.maxFailures = 10
+	c.retry = RetryPolicy{MaxAttempts: 3}
+	_, err = c.SendRawTransaction(context.Background(), "0x02")
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	c.retry.RetrySendRawTransaction = true
+	atomic.StoreInt32(&calls, 0)
+	hash, err := c.SendRawTransaction(context.Background(), "0x02")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b", hash)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWithConnectionRetryContextDone(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": flakyRequester(100, &httpStatusError{code: 429}, "0x10", &calls),
+	}, Endpoint{URL: "ws://node"})
+	c.maxFailures = 100
+	c.retry = RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.GetLatestBlockNumber(ctx)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestHTTPStatusRetry(t *testing.T) {
+	var posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonrpc.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case req.Method == "eth_call":
+			// 节点以 400 状态码返回 JSON-RPC 错误
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":3,"message":"execution reverted"}}`, req.ID.Num)
+		case atomic.AddInt32(&posts, 1) <= 2:
+			http.Error(w, "<html>503 Service Temporarily Unavailable</html>", http.StatusServiceUnavailable)
+		default:
+			json.NewEncoder(w).Encode(jsonrpc.RawResponse{JSONRPC: "2.0", ID: req.ID, Result: []byte(`"0x10"`)})
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(context.Background(), srv.URL, &ClientOptions{
+		MaxFailures: 10,
+		Retry:       RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	// 503 是连接类错误，按重试策略退避后重试
+	n, err := c.GetLatestBlockNumber(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), n)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&posts))
+
+	// 非临时性状态码的 JSON-RPC 错误按节点错误处理，不重试
+	_, err = c.CallWithMsg(context.Background(), CallMsg{To: "0x0000000000000000000000000000000000000001"}, "latest")
+	var rpcErr *RPCError
+	if assert.ErrorAs(t, err, &rpcErr) {
+		assert.Equal(t, "execution reverted", rpcErr.Message)
+	}
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+}
@@ -0,0 +1,83 @@
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer 交易签名者，可以由本地私钥、keystore 文件或远程签名服务实现
+type Signer interface {
+	// Address 返回签名者的地址，作为交易的发送方
+	Address() string
+	// SignTx 使用指定链ID签名交易，返回带签名的新交易
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// PrivateKeySigner 使用内存中的私钥签名交易
+type PrivateKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewPrivateKeySigner 从十六进制私钥创建签名者
+//
+// Parameters:
+//   - hexKey: string 32字节的十六进制私钥，可以带 0x 前缀
+//
+// Returns:
+//   - *PrivateKeySigner: 签名者
+//   - error: 私钥格式无效时返回错误
+func NewPrivateKeySigner(hexKey string) (*PrivateKeySigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return newPrivateKeySigner(key), nil
+}
+
+// NewKeystoreSigner 解密 keystore（Web3 Secret Storage）文件并创建签名者
+//
+// Parameters:
+//   - path: string keystore 文件路径
+//   - passphrase: string 解密密码
+//
+// Returns:
+//   - *PrivateKeySigner: 签名者，解密后的私钥保存在内存中
+//   - error: 可能的错误：
+//   - 文件读取失败
+//   - 密码错误或文件格式无效
+func NewKeystoreSigner(path, passphrase string) (*PrivateKeySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %v", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore file %s: %v", path, err)
+	}
+	return newPrivateKeySigner(key.PrivateKey), nil
+}
+
+// newPrivateKeySigner 根据私钥计算地址并创建签名者
+func newPrivateKeySigner(key *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Address 返回私钥对应的地址（EIP-55 校验和格式）
+func (s *PrivateKeySigner) Address() string {
+	return s.address.Hex()
+}
+
+// SignTx 使用支持所有交易类型的最新签名规则签名交易
+func (s *PrivateKeySigner) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
@@ -0,0 +1,370 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/justinwongcn/go-ethlibs/node"
+)
+
+// 订阅断开后重新订阅的退避时间
+const (
+	resubscribeMinBackoff = time.Second
+	resubscribeMaxBackoff = 30 * time.Second
+)
+
+// maxHeadBackfill 新区块订阅断开后最多补齐的区块数，断开时间过长时只补齐最近的区块
+const maxHeadBackfill = 256
+
+// subscriptionBuffer 订阅结果通道的缓冲大小
+const subscriptionBuffer = 16
+
+// subscriptionHandler 描述一种订阅，由 keepSubscribed 在断线重连时回调
+type subscriptionHandler struct {
+	// params eth_subscribe 的参数，如 "newHeads" 或 "logs" 和过滤条件
+	params []any
+	// resubscribed 重新订阅成功后调用，用于补齐断开期间遗漏的数据，返回错误时会断开并再次重试
+	resubscribed func(ctx context.Context) error
+	// notify 处理一条订阅通知，ctx 结束时返回错误
+	notify func(ctx context.Context, result json.RawMessage) error
+}
+
+// SubscribeNewHeads 订阅新区块头
+//
+// Parameters:
+//   - ctx: context.Context 控制订阅的生命周期，结束后通道被关闭
+//
+// Returns:
+//   - <-chan *eth.Block: 新区块头，按到达顺序发送；区块头不包含交易列表
+//   - error: 可能的错误：
+//   - 没有支持订阅的端点（需要 websocket 或 IPC）
+//   - 节点拒绝订阅
+//
+// 连接断开后会自动重新订阅，并通过 eth_getBlockByNumber 补齐断开期间遗漏的区块，
+// 保证相邻区块号连续（最多补齐 256 个区块）。发生链重组时会再次收到相同高度的区块。
+func (c *Client) SubscribeNewHeads(ctx context.Context) (<-chan *eth.Block, error) {
+	out := make(chan *eth.Block, subscriptionBuffer)
+	var last uint64 // 最近发送的区块号，0 表示尚未发送
+
+	handler := subscriptionHandler{
+		params:       []any{"newHeads"},
+		resubscribed: func(ctx context.Context) error { return nil },
+		notify: func(ctx context.Context, result json.RawMessage) error {
+			var head eth.Block
+			if err := json.Unmarshal(result, &head); err != nil {
+				c.logWarn("could not decode newHeads notification", "err", err)
+				return nil
+			}
+			if head.Number == nil {
+				return nil
+			}
+			number := head.Number.UInt64()
+
+			// 区块号不连续说明订阅断开过，补齐中间的区块
+			if last != 0 && number > last+1 {
+				blocks, err := c.backfillHeads(ctx, last+1, number-1)
+				if err != nil {
+					c.logWarn("failed to backfill blocks", "from", last+1, "to", number-1, "err", err)
+				}
+				for _, block := range blocks {
+					if !send(ctx, out, block) {
+						return ctx.Err()
+					}
+				}
+			}
+
+			if !send(ctx, out, &head) {
+				return ctx.Err()
+			}
+			last = number
+			return nil
+		},
+	}
+
+	if err := c.startSubscription(ctx, handler, func() { close(out) }); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// backfillHeads 通过批量请求获取 [from, to] 范围内的区块，超过 maxHeadBackfill 个时只获取最近的区块
+func (c *Client) backfillHeads(ctx context.Context, from, to uint64) ([]*eth.Block, error) {
+	if to-from+1 > maxHeadBackfill {
+		c.logWarn("missed too many blocks while resubscribing, only backfilling the latest", "missed", to-from+1, "backfilled", maxHeadBackfill)
+		from = to - maxHeadBackfill + 1
+	}
+
+	batch := c.Batch(ctx)
+	results := make([]*BatchResult[*eth.Block], 0, to-from+1)
+	for n := from; n <= to; n++ {
+		results = append(results, batch.GetBlockByNumber(eth.QuantityFromUInt64(n).String(), false))
+	}
+	if err := batch.Send(); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*eth.Block, 0, len(results))
+	for _, r := range results {
+		block, err := r.Result()
+		if err != nil {
+			return blocks, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// SubscribeLogs 订阅符合过滤条件的事件日志
+//
+// Parameters:
+//   - ctx: context.Context 控制订阅的生命周期，结束后通道被关闭
+//   - filter: eth.LogFilter 过滤条件，只使用 Address 和 Topics，FromBlock、ToBlock 和 BlockHash 会被忽略
+//
+// Returns:
+//   - <-chan *eth.Log: 匹配的日志；链重组时被撤销的日志会以 Removed 为 true 再次发送
+//   - error: 可能的错误：
+//   - 没有支持订阅的端点（需要 websocket 或 IPC）
+//   - 节点拒绝订阅
+//
+// 连接断开后会自动重新订阅，并通过 GetLogsRange 补齐断开期间遗漏的日志。
+func (c *Client) SubscribeLogs(ctx context.Context, filter eth.LogFilter) (<-chan *eth.Log, error) {
+	criteria := eth.LogFilter{Address: filter.Address, Topics: filter.Topics}
+	out := make(chan *eth.Log, subscriptionBuffer)
+
+	var (
+		cursor    uint64          // 已处理到的区块号，断线后从该区块开始补齐
+		delivered map[uint64]bool // cursor 区块中已发送日志的 logIndex，补齐时跳过
+		skipUpTo  uint64          // 补齐已覆盖的最高区块，重新订阅后收到的这些区块的日志不再重复发送
+	)
+	deliver := func(ctx context.Context, l *eth.Log) bool {
+		if !send(ctx, out, l) {
+			return false
+		}
+		if l.BlockNumber != nil && !l.Removed {
+			number := l.BlockNumber.UInt64()
+			if number > cursor {
+				cursor = number
+				delivered = make(map[uint64]bool)
+			}
+			if number == cursor && l.LogIndex != nil {
+				delivered[l.LogIndex.UInt64()] = true
+			}
+		}
+		return true
+	}
+
+	handler := subscriptionHandler{
+		params: []any{"logs", criteria},
+		resubscribed: func(ctx context.Context) error {
+			latest, err := c.GetLatestBlockNumber(ctx)
+			if err != nil {
+				return err
+			}
+			if latest < cursor {
+				return nil
+			}
+
+			backfill := criteria
+			backfill.FromBlock = eth.MustBlockNumberOrTag(eth.QuantityFromUInt64(cursor).String())
+			backfill.ToBlock = eth.MustBlockNumberOrTag(eth.QuantityFromUInt64(latest).String())
+			logs, err := c.GetLogsRange(ctx, backfill, nil)
+			if err != nil {
+				return fmt.Errorf("failed to backfill logs %d-%d: %v", cursor, latest, err)
+			}
+
+			start := cursor
+			for _, l := range logs {
+				if l.BlockNumber != nil && l.BlockNumber.UInt64() == start && l.LogIndex != nil && delivered[l.LogIndex.UInt64()] {
+					continue
+				}
+				if !deliver(ctx, l) {
+					return ctx.Err()
+				}
+			}
+			skipUpTo = latest
+			if cursor <= latest {
+				cursor = latest + 1
+				delivered = make(map[uint64]bool)
+			}
+			return nil
+		},
+		notify: func(ctx context.Context, result json.RawMessage) error {
+			var l eth.Log
+			if err := json.Unmarshal(result, &l); err != nil {
+				c.logWarn("could not decode logs notification", "err", err)
+				return nil
+			}
+			if !l.Removed && l.BlockNumber != nil && l.BlockNumber.UInt64() <= skipUpTo {
+				return nil
+			}
+			if !deliver(ctx, &l) {
+				return ctx.Err()
+			}
+			return nil
+		},
+	}
+
+	// 订阅之后再记录起始区块，之前的区块不需要补齐
+	start := func(ctx context.Context) error {
+		latest, err := c.GetLatestBlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		cursor = latest + 1
+		delivered = make(map[uint64]bool)
+		return nil
+	}
+	if err := c.startSubscription(ctx, handler, func() { close(out) }, start); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// send 将值发送到订阅通道，ctx 结束时返回 false
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// startSubscription 建立订阅并启动后台协程接收通知和断线重连
+//
+// Parameters:
+//   - ctx: context.Context 控制订阅的生命周期
+//   - h: subscriptionHandler 订阅的参数和回调
+//   - done: func() 订阅结束时调用，用于关闭结果通道
+//   - setup: ...func(context.Context) error 可选，首次订阅成功后、处理通知之前调用
+//
+// Returns:
+//   - error: 首次订阅失败时返回错误，此时不会启动后台协程
+func (c *Client) startSubscription(ctx context.Context, h subscriptionHandler, done func(), setup ...func(context.Context) error) error {
+	sub, conn, err := c.subscribe(ctx, h.params)
+	if err != nil {
+		return err
+	}
+	for _, fn := range setup {
+		if err := fn(ctx); err != nil {
+			conn.close()
+			return err
+		}
+	}
+
+	go func() {
+		defer done()
+		c.keepSubscribed(ctx, h, sub, conn)
+	}()
+	return nil
+}
+
+// keepSubscribed 接收订阅通知，连接断开后按指数退避重新订阅，直到 ctx 或客户端结束
+func (c *Client) keepSubscribed(ctx context.Context, h subscriptionHandler, sub node.Subscription, conn *pooledConn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer stop()
+
+	for {
+	receive:
+		for {
+			select {
+			case <-ctx.Done():
+				break receive
+			case n, ok := <-sub.Ch():
+				if !ok {
+					break receive
+				}
+				var params node.SubscriptionParams
+				if err := json.Unmarshal(n.Params, &params); err != nil {
+					c.logWarn("could not decode subscription notification", "err", err)
+					continue
+				}
+				if err := h.notify(ctx, params.Result); err != nil {
+					break receive
+				}
+			}
+		}
+		conn.close()
+		if ctx.Err() != nil {
+			return
+		}
+
+		// 连接断开，重新订阅并补齐遗漏的数据
+		backoff := resubscribeMinBackoff
+		for {
+			c.logWarn("subscription dropped, resubscribing", "subscription", h.params[0], "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, resubscribeMaxBackoff)
+
+			var err error
+			sub, conn, err = c.subscribe(ctx, h.params)
+			if err != nil {
+				c.logWarn("resubscribe failed", "subscription", h.params[0], "err", err)
+				continue
+			}
+			if err := h.resubscribed(ctx); err != nil {
+				c.logWarn("resubscribe failed", "subscription", h.params[0], "err", err)
+				conn.close()
+				continue
+			}
+			break
+		}
+	}
+}
+
+// subscribe 在支持双向通信的端点上建立 eth_subscribe 订阅
+//
+// 订阅会长期占用连接，因此使用独立的连接而不是从连接池借用，订阅结束时关闭该连接。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制建立订阅的上下文
+//   - params: []any eth_subscribe 的参数
+//
+// Returns:
+//   - node.Subscription: 订阅，连接断开时其通道被关闭
+//   - *pooledConn: 订阅独占的连接
+//   - error: 所有端点都无法订阅时返回最后一个错误
+func (c *Client) subscribe(ctx context.Context, params []any) (node.Subscription, *pooledConn, error) {
+	request, err := jsonrpc.MakeRequest(1, "eth_subscribe", params...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode eth_subscribe params: %v", err)
+	}
+	request.ID = jsonrpc.StringID(fmt.Sprint(params[0]))
+
+	lastErr := fmt.Errorf("no endpoint supports subscriptions, use a websocket or IPC endpoint")
+	for _, ep := range c.pickEndpoints() {
+		conn, err := ep.pool.dial(ctx)
+		if err != nil {
+			ep.recordFailure(err, c.maxFailures)
+			lastErr = err
+			continue
+		}
+		if !conn.IsBidirectional() {
+			conn.close()
+			continue
+		}
+
+		sub, err := conn.Subscribe(ctx, request)
+		if err != nil {
+			conn.close()
+			if isEndpointError(ctx, err) {
+				ep.recordFailure(err, c.maxFailures)
+			}
+			lastErr = fmt.Errorf("failed to subscribe to %v on %s: %v", params[0], ep.url, err)
+			continue
+		}
+		ep.recordSuccess()
+		return sub, conn, nil
+	}
+	return nil, nil, lastErr
+}
@@ -0,0 +1,171 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/justinwongcn/go-ethlibs/node"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSubscription 模拟的订阅，关闭通道即模拟连接断开
+type fakeSubscription struct {
+	ch chan *jsonrpc.Notification
+}
+
+func (s *fakeSubscription) Response() *jsonrpc.RawResponse        { return nil }
+func (s *fakeSubscription) ID() string                            { return "0x1" }
+func (s *fakeSubscription) Ch() <-chan *jsonrpc.Notification      { return s.ch }
+func (s *fakeSubscription) Unsubscribe(ctx context.Context) error { return nil }
+
+// notify 向订阅推送一条通知
+func (s *fakeSubscription) notify(result string) {
+	s.ch <- &jsonrpc.Notification{
+		Method: "eth_subscription",
+		Params: json.RawMessage(`{"subscription":"0x1","result":` + result + `}`),
+	}
+}
+
+// fakeSubscriber 每次订阅依次返回 subs 中的下一个订阅
+type fakeSubscriber struct {
+	subs chan *fakeSubscription
+}
+
+func (f *fakeSubscriber) Subscribe(ctx context.Context, r *jsonrpc.Request) (node.Subscription, error) {
+	select {
+	case sub := <-f.subs:
+		return sub, nil
+	default:
+		return nil, fmt.Errorf("connection refused")
+	}
+}
+
+// newSubscriptionTestClient 创建支持订阅的模拟客户端，返回的订阅按顺序供每次（重新）订阅使用
+func newSubscriptionTestClient(t *testing.T, requester requesterFunc, n int) (*Client, []*fakeSubscription) {
+	subscriber := &fakeSubscriber{subs: make(chan *fakeSubscription, n)}
+	subs := make([]*fakeSubscription, n)
+	for i := range subs {
+		subs[i] = &fakeSubscription{ch: make(chan *jsonrpc.Notification)}
+		subscriber.subs <- subs[i]
+	}
+
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": requester}, Endpoint{URL: "ws://node"})
+	c.endpoints[0].pool.dial = func(ctx context.Context) (*pooledConn, error) {
+		client, err := node.NewCustomClient(requester, subscriber)
+		if err != nil {
+			return nil, err
+		}
+		return &pooledConn{Client: client, close: func() {}}, nil
+	}
+	return c, subs
+}
+
+// firstParam 返回请求的第一个参数
+func firstParam(r *jsonrpc.Request) any {
+	var params []any
+	b, _ := json.Marshal(r.Params)
+	json.Unmarshal(b, &params)
+	if len(params) == 0 {
+		return nil
+	}
+	return params[0]
+}
+
+func TestSubscribeNewHeadsBackfill(t *testing.T) {
+	requester := func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		if r.Method != "eth_getBlockByNumber" {
+			return nil, fmt.Errorf("unexpected request %s", r.Method)
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(fmt.Sprintf(`{"number":%q}`, firstParam(r)))}, nil
+	}
+	c, subs := newSubscriptionTestClient(t, requester, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	heads, err := c.SubscribeNewHeads(ctx)
+	assert.NoError(t, err)
+
+	subs[0].notify(`{"number":"0x10"}`)
+	subs[0].notify(`{"number":"0x11"}`)
+	close(subs[0].ch)
+	// 重新订阅后收到的第一个区块与断开前不连续，中间的区块通过批量请求补齐
+	go subs[1].notify(`{"number":"0x14"}`)
+
+	var numbers []uint64
+	for len(numbers) < 5 {
+		select {
+		case head := <-heads:
+			numbers = append(numbers, head.Number.UInt64())
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out, received %v", numbers)
+		}
+	}
+	assert.Equal(t, []uint64{0x10, 0x11, 0x12, 0x13, 0x14}, numbers)
+
+	cancel()
+	for range heads {
+	}
+}
+
+func TestSubscribeLogsBackfill(t *testing.T) {
+	logJSON := func(block, index uint64) string {
+		return fmt.Sprintf(`{"blockNumber":"0x%x","logIndex":"0x%x","address":"0x0000000000000000000000000000000000000001","topics":[],"data":"0x"}`, block, index)
+	}
+
+	latest := []string{"0x10", "0x14"}
+	requester := func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		switch r.Method {
+		case "eth_blockNumber":
+			result := latest[0]
+			latest = latest[1:]
+			return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(`"` + result + `"`)}, nil
+		case "eth_getLogs":
+			filter := firstParam(r).(map[string]any)
+			assert.Equal(t, "0x12", filter["fromBlock"], "从最后处理的区块开始补齐")
+			assert.Equal(t, "0x14", filter["toBlock"])
+			result := "[" + logJSON(0x12, 0) + "," + logJSON(0x12, 1) + "," + logJSON(0x13, 0) + "," + logJSON(0x14, 0) + "]"
+			return &jsonrpc.RawResponse{ID: r.ID, Result: []byte(result)}, nil
+		}
+		return nil, fmt.Errorf("unexpected request %s", r.Method)
+	}
+	c, subs := newSubscriptionTestClient(t, requester, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	logs, err := c.SubscribeLogs(ctx, eth.LogFilter{Address: []eth.Address{*eth.MustAddress("0x0000000000000000000000000000000000000001")}})
+	assert.NoError(t, err)
+
+	subs[0].notify(logJSON(0x12, 0))
+	close(subs[0].ch)
+	go func() {
+		// 补齐范围内的日志不会重复发送
+		subs[1].notify(logJSON(0x14, 0))
+		subs[1].notify(logJSON(0x15, 0))
+	}()
+
+	var got []string
+	for len(got) < 5 {
+		select {
+		case l := <-logs:
+			got = append(got, fmt.Sprintf("%d/%d", l.BlockNumber.UInt64(), l.LogIndex.UInt64()))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out, received %v", got)
+		}
+	}
+	assert.Equal(t, []string{"18/0", "18/1", "19/0", "20/0", "21/0"}, got)
+
+	cancel()
+	for range logs {
+	}
+}
+
+func TestSubscribeRequiresBidirectionalEndpoint(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, map[string]requesterFunc{"https://node": blockNumberRequester("0x1", &calls)}, Endpoint{URL: "https://node"})
+
+	_, err := c.SubscribeNewHeads(context.Background())
+	assert.ErrorContains(t, err, "no endpoint supports subscriptions")
+}
@@ -0,0 +1,394 @@
+package ethereum
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// ERC-20 方法选择器，即方法签名 keccak256 哈希的前4字节
+const (
+	erc20BalanceOf   = "0x70a08231" // balanceOf(address)
+	erc20Name        = "0x06fdde03" // name()
+	erc20Symbol      = "0x95d89b41" // symbol()
+	erc20Decimals    = "0x313ce567" // decimals()
+	erc20TotalSupply = "0x18160ddd" // totalSupply()
+)
+
+// TransferTopic Transfer(address,address,uint256) 事件的 topic0，ERC-20 和 ERC-721 共用
+const TransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// TokenMetadata ERC-20 代币的基本信息
+type TokenMetadata struct {
+	Address     string   // 代币合约地址
+	Name        string   // 代币名称，合约未实现 name() 时为空
+	Symbol      string   // 代币符号，合约未实现 symbol() 时为空
+	Decimals    uint8    // 小数位数，合约未实现 decimals() 时为0
+	TotalSupply *big.Int // 总发行量（最小单位）
+}
+
+// LogPosition 事件日志在链上的位置
+type LogPosition struct {
+	BlockNumber uint64 // 所在区块号
+	BlockHash   string // 所在区块哈希
+	TxHash      string // 交易哈希
+	TxIndex     uint64 // 交易在区块中的索引
+	LogIndex    uint64 // 日志在区块中的索引
+}
+
+// TokenTransfer ERC-20 代币的一次转账，来自 Transfer 事件日志
+type TokenTransfer struct {
+	LogPosition
+	Token string   // 代币合约地址
+	From  string   // 转出地址，铸造时为零地址
+	To    string   // 转入地址，销毁时通常为零地址
+	Value *big.Int // 转账数量（最小单位）
+}
+
+// TokenBalance 查询地址持有的 ERC-20 代币余额
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - token: string 代币合约地址
+//   - holder: string 持有者地址
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - *big.Int: 代币余额（最小单位），可以通过 FormatUnits 按代币的小数位数转换
+//   - error: 可能的错误：
+//   - 无效的地址或区块号格式
+//   - 地址上没有合约或合约未实现 balanceOf
+//   - 节点连接错误
+func (c *Client) TokenBalance(ctx context.Context, token, holder string, numberOrTag string) (*big.Int, error) {
+	data, err := encodeAddressCall(erc20BalanceOf, holder)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.CallWithMsg(ctx, CallMsg{To: token, Data: data}, numberOrTag)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint256(result, "balanceOf")
+}
+
+// TokenMetadata 查询 ERC-20 代币的名称、符号、小数位数和总发行量，具有以下特性：
+//   - 四个查询在同一个批量请求中发送
+//   - name、symbol 和 decimals 在 ERC-20 中是可选的，合约未实现或调用回滚时对应字段为零值
+//   - 兼容 name 和 symbol 返回 bytes32 的早期代币（如 MKR）
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - token: string 代币合约地址
+//
+// Returns:
+//   - *TokenMetadata: 代币信息
+//   - error: 可能的错误：
+//   - 无效的地址格式
+//   - 地址上没有合约或合约未实现 totalSupply
+//   - 节点连接错误
+func (c *Client) TokenMetadata(ctx context.Context, token string) (*TokenMetadata, error) {
+	if _, err := eth.NewAddress(token); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, token)
+	}
+
+	batch := c.Batch(ctx)
+	name := batch.Call(CallMsg{To: token, Data: erc20Name}, "latest")
+	symbol := batch.Call(CallMsg{To: token, Data: erc20Symbol}, "latest")
+	decimals := batch.Call(CallMsg{To: token, Data: erc20Decimals}, "latest")
+	totalSupply := batch.Call(CallMsg{To: token, Data: erc20TotalSupply}, "latest")
+	if err := batch.Send(); err != nil {
+		return nil, err
+	}
+
+	meta := &TokenMetadata{Address: token}
+	result, err := totalSupply.Result()
+	if err != nil {
+		return nil, err
+	}
+	if meta.TotalSupply, err = decodeUint256(result, "totalSupply"); err != nil {
+		return nil, err
+	}
+
+	if result, ok, err := optionalCallResult(name); err != nil {
+		return nil, err
+	} else if ok {
+		meta.Name = decodeTokenString(result)
+	}
+	if result, ok, err := optionalCallResult(symbol); err != nil {
+		return nil, err
+	} else if ok {
+		meta.Symbol = decodeTokenString(result)
+	}
+	if result, ok, err := optionalCallResult(decimals); err != nil {
+		return nil, err
+	} else if ok {
+		// 部分代币将 decimals 声明为 uint256，超出 uint8 范围的值视为无效
+		if d, err := decodeUint256(result, "decimals"); err == nil && d.IsUint64() && d.Uint64() <= 255 {
+			meta.Decimals = uint8(d.Uint64())
+		}
+	}
+	return meta, nil
+}
+
+// TokenTransfers 查询 ERC-20 代币的转账记录，具有以下特性：
+//   - 通过 Transfer 事件日志查询，大区块范围按 GetLogsRange 分段
+//   - 指定 holder 时分别查询转出和转入，合并后按区块和日志顺序排列
+//   - 忽略 topic 数量不符合 ERC-20 的 Transfer 日志（如 ERC-721 的 Transfer，tokenId 为 indexed 参数）
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - token: string 代币合约地址，为空时查询 holder 所有代币的转账
+//   - holder: string 转出或转入地址，为空时查询 token 的所有转账
+//   - fromBlock: string 起始区块号或标签，为空时使用 "earliest"
+//   - toBlock: string 结束区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - []*TokenTransfer: 按区块和日志顺序排列的转账记录
+//   - error: 可能的错误：
+//   - token 和 holder 都为空
+//   - 无效的地址或区块号格式
+//   - 节点连接错误
+func (c *Client) TokenTransfers(ctx context.Context, token, holder string, fromBlock, toBlock string) ([]*TokenTransfer, error) {
+	filter, err := transferFilter(token, holder, fromBlock, toBlock, TransferTopic)
+	if err != nil {
+		return nil, err
+	}
+
+	// eth_getLogs 不支持不同 topic 位置之间的或关系，转出和转入分别查询
+	filters := []eth.LogFilter{filter}
+	if holder != "" {
+		topic, err := addressTopic(holder)
+		if err != nil {
+			return nil, err
+		}
+		filters = []eth.LogFilter{withTopic(filter, 1, topic), withTopic(filter, 2, topic)}
+	}
+
+	logs, err := c.transferLogs(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	var transfers []*TokenTransfer
+	for _, l := range logs {
+		if transfer, ok := parseTokenTransfer(l); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, nil
+}
+
+// transferFilter 构造转账事件的日志过滤器
+//
+// Parameters:
+//   - token: string 合约地址，为空时不限制
+//   - holder: string 转出或转入地址，只用于校验 token 和 holder 不能同时为空
+//   - fromBlock: string 起始区块号或标签，为空时使用 "earliest"
+//   - toBlock: string 结束区块号或标签，为空时使用 "latest"
+//   - topics: ...string 可以匹配的 topic0
+func transferFilter(token, holder, fromBlock, toBlock string, topics ...string) (eth.LogFilter, error) {
+	if token == "" && holder == "" {
+		return eth.LogFilter{}, fmt.Errorf("token or holder address is required")
+	}
+	if fromBlock == "" {
+		fromBlock = string(eth.TagEarliest)
+	}
+	from, err := eth.NewBlockNumberOrTag(fromBlock)
+	if err != nil {
+		return eth.LogFilter{}, fmt.Errorf("%w: %s", ErrInvalidBlockTag, fromBlock)
+	}
+	to, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(toBlock))
+	if err != nil {
+		return eth.LogFilter{}, fmt.Errorf("%w: %s", ErrInvalidBlockTag, toBlock)
+	}
+
+	filter := eth.LogFilter{FromBlock: from, ToBlock: to, Topics: [][]eth.Topic{{}}}
+	for _, topic := range topics {
+		filter.Topics[0] = append(filter.Topics[0], eth.Topic(topic))
+	}
+	if token != "" {
+		addr, err := eth.NewAddress(token)
+		if err != nil {
+			return eth.LogFilter{}, fmt.Errorf("%w: %s", ErrInvalidAddress, token)
+		}
+		filter.Address = []eth.Address{*addr}
+	}
+	return filter, nil
+}
+
+// withTopic 返回在指定位置匹配 topic 的过滤器副本
+func withTopic(filter eth.LogFilter, position int, topic eth.Topic) eth.LogFilter {
+	topics := make([][]eth.Topic, max(len(filter.Topics), position+1))
+	copy(topics, filter.Topics)
+	topics[position] = []eth.Topic{topic}
+	filter.Topics = topics
+	return filter
+}
+
+// transferLogs 分段查询多个过滤器的日志，去除重复的日志（如转给自己的转账在转出和转入查询中都会出现），
+// 忽略因重组被移除的日志，结果按区块和日志顺序排列
+func (c *Client) transferLogs(ctx context.Context, filters []eth.LogFilter) ([]*eth.Log, error) {
+	var logs []*eth.Log
+	seen := make(map[LogPosition]bool)
+	for _, f := range filters {
+		result, err := c.GetLogsRange(ctx, f, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range result {
+			pos := logPosition(l)
+			if l.Removed || seen[pos] {
+				continue
+			}
+			seen[pos] = true
+			logs = append(logs, l)
+		}
+	}
+
+	slices.SortStableFunc(logs, func(a, b *eth.Log) int {
+		pa, pb := logPosition(a), logPosition(b)
+		return cmp.Or(cmp.Compare(pa.BlockNumber, pb.BlockNumber), cmp.Compare(pa.LogIndex, pb.LogIndex))
+	})
+	return logs, nil
+}
+
+// logPosition 读取日志的位置信息
+func logPosition(l *eth.Log) LogPosition {
+	var pos LogPosition
+	if l.BlockNumber != nil {
+		pos.BlockNumber = l.BlockNumber.UInt64()
+	}
+	if l.BlockHash != nil {
+		pos.BlockHash = l.BlockHash.String()
+	}
+	if l.TxHash != nil {
+		pos.TxHash = l.TxHash.String()
+	}
+	if l.TxIndex != nil {
+		pos.TxIndex = l.TxIndex.UInt64()
+	}
+	if l.LogIndex != nil {
+		pos.LogIndex = l.LogIndex.UInt64()
+	}
+	return pos
+}
+
+// parseTokenTransfer 将 ERC-20 Transfer 日志转换为转账记录，日志格式不符合时返回 false
+func parseTokenTransfer(l *eth.Log) (*TokenTransfer, bool) {
+	if len(l.Topics) != 3 || !strings.EqualFold(l.Topics[0].String(), TransferTopic) {
+		return nil, false
+	}
+	data := l.Data.Bytes()
+	if len(data) != 32 {
+		return nil, false
+	}
+	return &TokenTransfer{
+		LogPosition: logPosition(l),
+		Token:       strings.ToLower(l.Address.String()),
+		From:        topicAddress(l.Topics[1]),
+		To:          topicAddress(l.Topics[2]),
+		Value:       new(big.Int).SetBytes(data),
+	}, true
+}
+
+// topicAddress 取 indexed 地址参数的低20字节
+func topicAddress(topic eth.Topic) string {
+	s := strings.ToLower(topic.String())
+	return "0x" + s[len(s)-40:]
+}
+
+// addressTopic 将地址编码为 indexed 参数的 topic
+func addressTopic(address string) (eth.Topic, error) {
+	addr, err := eth.NewAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+	}
+	return eth.Topic("0x" + strings.Repeat("0", 24) + strings.ToLower(addr.String()[2:])), nil
+}
+
+// encodeAddressCall 编码只有一个地址参数的方法调用
+func encodeAddressCall(selector, address string) (string, error) {
+	topic, err := addressTopic(address)
+	if err != nil {
+		return "", err
+	}
+	return selector + topic.String()[2:], nil
+}
+
+// decodeUint256 解码返回值中的第一个 uint256
+func decodeUint256(result, method string) (*big.Int, error) {
+	data, err := decodeHexResult(result)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s result %q: %v", method, result, err)
+	}
+	if len(data) < 32 {
+		return nil, fmt.Errorf("invalid %s result %q: contract returned %d bytes", method, result, len(data))
+	}
+	return new(big.Int).SetBytes(data[:32]), nil
+}
+
+// decodeHexResult 将 eth_call 的十六进制返回值解码为字节
+func decodeHexResult(result string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(result, "0x"))
+}
+
+// optionalCallResult 读取可选方法的调用结果，合约回滚或没有返回数据时返回 false
+func optionalCallResult(r *BatchResult[string]) (string, bool, error) {
+	result, err := r.Result()
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if result == "" || result == "0x" {
+		return "", false, nil
+	}
+	return result, true, nil
+}
+
+// decodeTokenString 解码 name 或 symbol 的返回值，兼容 ABI 编码的 string 和早期代币使用的 bytes32
+func decodeTokenString(result string) string {
+	data, err := decodeHexResult(result)
+	if err != nil {
+		return ""
+	}
+
+	// bytes32：右侧补零的定长字符串
+	if len(data) == 32 {
+		return validTokenString(bytes.TrimRight(data, "\x00"))
+	}
+
+	// string：偏移量、长度和内容
+	if len(data) < 64 {
+		return ""
+	}
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return ""
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[start-32 : start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(data))-start {
+		return ""
+	}
+	return validTokenString(data[start : start+length.Uint64()])
+}
+
+// validTokenString 去除字符串中的空字符，不是有效 UTF-8 时返回空字符串
+func validTokenString(b []byte) string {
+	b = bytes.ReplaceAll(b, []byte{0}, nil)
+	if !utf8.Valid(b) {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,202 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testToken  = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	testHolder = "0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae"
+	testOther  = "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
+)
+
+// word 将数值编码为32字节的十六进制字
+func word(v uint64) string {
+	return fmt.Sprintf("%064x", v)
+}
+
+// abiString 将字符串编码为 ABI 的 string 返回值
+func abiString(s string) string {
+	data := fmt.Sprintf("%x", s)
+	return "0x" + word(32) + word(uint64(len(s))) + data + strings.Repeat("0", (64-len(data)%64)%64)
+}
+
+// topicOf 将地址编码为 indexed 参数的 topic
+func topicOf(address string) string {
+	return "0x" + strings.Repeat("0", 24) + address[2:]
+}
+
+// tokenCallRequester 按方法选择器返回预设结果的模拟节点，calls 中不存在的选择器返回回滚错误
+func tokenCallRequester(calls map[string]string) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		var params []json.RawMessage
+		b, _ := json.Marshal(r.Params)
+		json.Unmarshal(b, &params)
+		var msg struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		if r.Method != "eth_call" || json.Unmarshal(params[0], &msg) != nil {
+			return nil, fmt.Errorf("unexpected request %s", r.Method)
+		}
+		result, ok := calls[msg.Data[:10]]
+		if !ok {
+			rpcErr := json.RawMessage(`{"code":3,"message":"execution reverted","data":"0x"}`)
+			return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"` + result + `"`)}, nil
+	}
+}
+
+// filterLogsRequester 按 topic 过滤预设日志的模拟节点，最新区块号为 latest，filters 记录每次 eth_getLogs 的过滤器
+func filterLogsRequester(logs []map[string]any, latest string, filters *[]map[string]any) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		if r.Method == "eth_blockNumber" {
+			return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"` + latest + `"`)}, nil
+		}
+		var params []map[string]any
+		b, _ := json.Marshal(r.Params)
+		json.Unmarshal(b, &params)
+		*filters = append(*filters, params[0])
+
+		topics, _ := params[0]["topics"].([]any)
+		var matched []map[string]any
+		for _, l := range logs {
+			logTopics := l["topics"].([]string)
+			ok := true
+			for i, topic := range topics {
+				values, _ := topic.([]any)
+				if len(values) > 0 && (i >= len(logTopics) || !slices.Contains(values, any(logTopics[i]))) {
+					ok = false
+				}
+			}
+			if ok {
+				matched = append(matched, l)
+			}
+		}
+		result, _ := json.Marshal(matched)
+		return &jsonrpc.RawResponse{ID: r.ID, Result: result}, nil
+	}
+}
+
+func TestTokenBalance(t *testing.T) {
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": tokenCallRequester(map[string]string{erc20BalanceOf: "0x" + word(1500000)}),
+	}, Endpoint{URL: "ws://node"})
+
+	balance, err := c.TokenBalance(context.Background(), testToken, testHolder, "0x10")
+	assert.NoError(t, err)
+	assert.Equal(t, "1500000", balance.String())
+
+	_, err = c.TokenBalance(context.Background(), testToken, "0x123", "latest")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = c.TokenBalance(context.Background(), testToken, testHolder, "recent")
+	assert.ErrorIs(t, err, ErrInvalidBlockTag)
+
+	data, err := encodeAddressCall(erc20BalanceOf, "0xDE0B295669A9FD93D5F28D9EC85E40F4CB697BAE")
+	assert.NoError(t, err)
+	assert.Equal(t, erc20BalanceOf+topicOf(testHolder)[2:], data)
+}
+
+func TestTokenMetadata(t *testing.T) {
+	// 标准代币：name 和 symbol 为 ABI 编码的 string
+	c := newTestClient(t, map[string]requesterFunc{
+		"ws://node": tokenCallRequester(map[string]string{
+			erc20Name:        abiString("Tether USD"),
+			erc20Symbol:      abiString("USDT"),
+			erc20Decimals:    "0x" + word(6),
+			erc20TotalSupply: "0x" + word(1000000000000),
+		}),
+	}, Endpoint{URL: "ws://node"})
+	meta, err := c.TokenMetadata(context.Background(), testToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "Tether USD", meta.Name)
+	assert.Equal(t, "USDT", meta.Symbol)
+	assert.Equal(t, uint8(6), meta.Decimals)
+	assert.Equal(t, "1000000000000", meta.TotalSupply.String())
+
+	// 早期代币：name 和 symbol 为 bytes32，未实现 decimals
+	c = newTestClient(t, map[string]requesterFunc{
+		"ws://node": tokenCallRequester(map[string]string{
+			erc20Name:        "0x" + fmt.Sprintf("%x", "Maker") + strings.Repeat("0", 54),
+			erc20Symbol:      "0x" + fmt.Sprintf("%x", "MKR") + strings.Repeat("0", 58),
+			erc20TotalSupply: "0x" + word(1000),
+		}),
+	}, Endpoint{URL: "ws://node"})
+	meta, err = c.TokenMetadata(context.Background(), testToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "Maker", meta.Name)
+	assert.Equal(t, "MKR", meta.Symbol)
+	assert.Equal(t, uint8(0), meta.Decimals)
+
+	// 不是代币合约
+	c = newTestClient(t, map[string]requesterFunc{
+		"ws://node": tokenCallRequester(map[string]string{erc20TotalSupply: "0x"}),
+	}, Endpoint{URL: "ws://node"})
+	_, err = c.TokenMetadata(context.Background(), testToken)
+	assert.Error(t, err)
+	_, err = c.TokenMetadata(context.Background(), "0x123")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+func TestDecodeTokenString(t *testing.T) {
+	assert.Equal(t, "", decodeTokenString("0x"))
+	assert.Equal(t, "", decodeTokenString("0xzz"))
+	// 偏移量越界
+	assert.Equal(t, "", decodeTokenString("0x"+word(1000)+word(4)))
+	// 长度越界
+	assert.Equal(t, "", decodeTokenString("0x"+word(32)+word(1000)))
+	// 无效的 UTF-8
+	assert.Equal(t, "", decodeTokenString("0x"+"ff"+strings.Repeat("0", 62)))
+}
+
+func TestTokenTransfers(t *testing.T) {
+	// 模拟节点：块1 holder 转出，块2 holder 转入，块3 holder 转给自己，另有一条 ERC-721 Transfer
+	logs := []map[string]any{
+		{"blockNumber": "0x2", "blockHash": "0x" + word(2), "logIndex": "0x0", "transactionHash": "0x" + word(20), "transactionIndex": "0x1",
+			"address": testToken, "topics": []string{TransferTopic, topicOf(testOther), topicOf(testHolder)}, "data": "0x" + word(200)},
+		{"blockNumber": "0x1", "blockHash": "0x" + word(1), "logIndex": "0x3", "transactionHash": "0x" + word(10), "transactionIndex": "0x0",
+			"address": testToken, "topics": []string{TransferTopic, topicOf(testHolder), topicOf(testOther)}, "data": "0x" + word(100)},
+		{"blockNumber": "0x3", "blockHash": "0x" + word(3), "logIndex": "0x0", "transactionHash": "0x" + word(30), "transactionIndex": "0x0",
+			"address": testToken, "topics": []string{TransferTopic, topicOf(testHolder), topicOf(testHolder)}, "data": "0x" + word(300)},
+		{"blockNumber": "0x3", "blockHash": "0x" + word(3), "logIndex": "0x1", "transactionHash": "0x" + word(30), "transactionIndex": "0x0",
+			"address": testToken, "topics": []string{TransferTopic, topicOf(testHolder), topicOf(testOther), "0x" + word(7)}, "data": "0x"},
+	}
+	var filters []map[string]any
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": filterLogsRequester(logs, "0x3", &filters)}, Endpoint{URL: "ws://node"})
+
+	transfers, err := c.TokenTransfers(context.Background(), testToken, testHolder, "", "")
+	assert.NoError(t, err)
+	assert.Len(t, filters, 2)
+	assert.Equal(t, []any{testToken}, filters[0]["address"])
+	assert.Equal(t, "0x0", filters[0]["fromBlock"])
+	assert.Equal(t, "0x3", filters[0]["toBlock"])
+
+	// 自转账去重，ERC-721 日志被忽略，结果按区块排序
+	assert.Len(t, transfers, 3)
+	assert.Equal(t, &TokenTransfer{
+		LogPosition: LogPosition{BlockNumber: 1, BlockHash: "0x" + word(1), TxHash: "0x" + word(10), TxIndex: 0, LogIndex: 3},
+		Token:       testToken,
+		From:        testHolder,
+		To:          testOther,
+		Value:       transfers[0].Value,
+	}, transfers[0])
+	assert.Equal(t, "100", transfers[0].Value.String())
+	assert.Equal(t, testOther, transfers[1].From)
+	assert.Equal(t, uint64(3), transfers[2].BlockNumber)
+
+	_, err = c.TokenTransfers(context.Background(), "", "", "", "")
+	assert.Error(t, err)
+	_, err = c.TokenTransfers(context.Background(), testToken, "0x123", "", "")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = c.TokenTransfers(context.Background(), testToken, "", "first", "")
+	assert.ErrorIs(t, err, ErrInvalidBlockTag)
+}
@@ -0,0 +1,591 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// 内部转账的调用类型，与 callTracer 的 type 字段一致
+const (
+	TraceCall         = "CALL"
+	TraceCreate       = "CREATE"
+	TraceCreate2      = "CREATE2"
+	TraceSelfDestruct = "SELFDESTRUCT"
+)
+
+// TraceOptions debug_traceTransaction 和 debug_traceBlockByNumber 的追踪选项
+type TraceOptions struct {
+	Timeout time.Duration // 单笔交易的追踪超时时间，为0时使用节点的默认值（geth 为5秒）
+}
+
+// config 返回指定追踪器的 TraceConfig 参数
+func (o *TraceOptions) config(tracer string, tracerConfig map[string]any) map[string]any {
+	config := map[string]any{"tracer": tracer}
+	if tracerConfig != nil {
+		config["tracerConfig"] = tracerConfig
+	}
+	if o != nil && o.Timeout > 0 {
+		config["timeout"] = o.Timeout.String()
+	}
+	return config
+}
+
+// CallFrame callTracer 返回的调用树中的一次调用
+type CallFrame struct {
+	Type         string       // 调用类型，如 CALL、DELEGATECALL、STATICCALL、CREATE、CREATE2、SELFDESTRUCT
+	From         string       // 调用方地址
+	To           string       // 被调用的地址，CREATE 时为新合约地址
+	Value        *big.Int     // 转账金额（wei），没有转账时为0
+	Gas          uint64       // 提供的 gas
+	GasUsed      uint64       // 使用的 gas
+	Input        string       // 调用数据
+	Output       string       // 返回数据
+	Error        string       // 调用失败的原因，成功时为空
+	RevertReason string       // 解码后的 revert 原因
+	Calls        []*CallFrame // 子调用
+}
+
+// callFrameJSON callTracer 返回的原始调用帧
+type callFrameJSON struct {
+	Type         string          `json:"type"`
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	Value        *eth.Quantity   `json:"value"`
+	Gas          *eth.Quantity   `json:"gas"`
+	GasUsed      *eth.Quantity   `json:"gasUsed"`
+	Input        string          `json:"input"`
+	Output       string          `json:"output"`
+	Error        string          `json:"error"`
+	RevertReason string          `json:"revertReason"`
+	Calls        []callFrameJSON `json:"calls"`
+}
+
+// frame 转换为 CallFrame
+func (f *callFrameJSON) frame() *CallFrame {
+	frame := &CallFrame{
+		Type:         strings.ToUpper(f.Type),
+		From:         strings.ToLower(f.From),
+		To:           strings.ToLower(f.To),
+		Value:        quantityOrZero(f.Value),
+		Input:        f.Input,
+		Output:       f.Output,
+		Error:        f.Error,
+		RevertReason: f.RevertReason,
+	}
+	if f.Gas != nil {
+		frame.Gas = f.Gas.UInt64()
+	}
+	if f.GasUsed != nil {
+		frame.GasUsed = f.GasUsed.UInt64()
+	}
+	for i := range f.Calls {
+		frame.Calls = append(frame.Calls, f.Calls[i].frame())
+	}
+	return frame
+}
+
+// ParityTrace trace_filter 和 trace_block 返回的一条追踪记录（Parity/OpenEthereum 格式，Erigon、Nethermind 等节点支持）
+type ParityTrace struct {
+	Type         string   // 追踪类型：call、create、suicide、reward
+	CallType     string   // call 的调用类型：call、delegatecall、staticcall、callcode
+	From         string   // 调用方地址，suicide 时为自毁的合约地址，reward 时为获得奖励的地址
+	To           string   // 被调用的地址，create 时为新合约地址，suicide 时为接收余额的地址
+	Value        *big.Int // 转账金额（wei），suicide 时为合约的余额
+	Gas          uint64   // 提供的 gas
+	GasUsed      uint64   // 使用的 gas，失败时为0
+	Input        string   // 调用数据，create 时为合约的初始化代码
+	Output       string   // 返回数据，create 时为合约代码
+	Error        string   // 调用失败的原因，成功时为空
+	TraceAddress []int    // 调用在调用树中的位置，顶层调用为空
+	Subtraces    uint64   // 子调用数量
+	BlockNumber  uint64   // 所在区块号
+	BlockHash    string   // 所在区块哈希
+	TxHash       string   // 交易哈希，区块奖励为空
+	TxIndex      uint64   // 交易在区块中的索引
+}
+
+// parityTraceJSON trace_filter 和 trace_block 返回的原始追踪记录
+type parityTraceJSON struct {
+	Type   string `json:"type"`
+	Action struct {
+		CallType      string        `json:"callType"`
+		From          string        `json:"from"`
+		To            string        `json:"to"`
+		Value         *eth.Quantity `json:"value"`
+		Gas           *eth.Quantity `json:"gas"`
+		Input         string        `json:"input"`
+		Init          string        `json:"init"`
+		Address       string        `json:"address"`
+		RefundAddress string        `json:"refundAddress"`
+		Balance       *eth.Quantity `json:"balance"`
+		Author        string        `json:"author"`
+	} `json:"action"`
+	Result *struct {
+		GasUsed *eth.Quantity `json:"gasUsed"`
+		Output  string        `json:"output"`
+		Address string        `json:"address"`
+		Code    string        `json:"code"`
+	} `json:"result"`
+	Error               string  `json:"error"`
+	TraceAddress        []int   `json:"traceAddress"`
+	Subtraces           uint64  `json:"subtraces"`
+	BlockNumber         uint64  `json:"blockNumber"`
+	BlockHash           string  `json:"blockHash"`
+	TransactionHash     *string `json:"transactionHash"`
+	TransactionPosition *uint64 `json:"transactionPosition"`
+}
+
+// trace 转换为 ParityTrace，统一不同追踪类型的字段
+func (t *parityTraceJSON) trace() *ParityTrace {
+	a := &t.Action
+	trace := &ParityTrace{
+		Type:         t.Type,
+		CallType:     a.CallType,
+		From:         strings.ToLower(a.From),
+		To:           strings.ToLower(a.To),
+		Value:        quantityOrZero(a.Value),
+		Input:        a.Input,
+		Error:        t.Error,
+		TraceAddress: t.TraceAddress,
+		Subtraces:    t.Subtraces,
+		BlockNumber:  t.BlockNumber,
+		BlockHash:    t.BlockHash,
+	}
+	if a.Gas != nil {
+		trace.Gas = a.Gas.UInt64()
+	}
+	if t.TransactionHash != nil {
+		trace.TxHash = *t.TransactionHash
+	}
+	if t.TransactionPosition != nil {
+		trace.TxIndex = *t.TransactionPosition
+	}
+	switch t.Type {
+	case "create":
+		trace.Input = a.Init
+	case "suicide":
+		trace.From = strings.ToLower(a.Address)
+		trace.To = strings.ToLower(a.RefundAddress)
+		trace.Value = quantityOrZero(a.Balance)
+	case "reward":
+		trace.From = strings.ToLower(a.Author)
+	}
+	if t.Result != nil {
+		if t.Result.GasUsed != nil {
+			trace.GasUsed = t.Result.GasUsed.UInt64()
+		}
+		trace.Output = t.Result.Output
+		if t.Type == "create" {
+			trace.To = strings.ToLower(t.Result.Address)
+			trace.Output = t.Result.Code
+		}
+	}
+	return trace
+}
+
+// InternalTransfer 合约执行过程中的一次 ETH 转账，即 Etherscan 的内部交易
+type InternalTransfer struct {
+	TxHash       string   // 交易哈希
+	Type         string   // 调用类型：CALL、CREATE、CREATE2、SELFDESTRUCT
+	From         string   // 转出地址
+	To           string   // 转入地址，创建失败时为空
+	Value        *big.Int // 转账金额（wei）
+	TraceAddress []int    // 调用在调用树中的位置，如 [0 1] 为顶层调用的第1个子调用的第2个子调用
+	Error        string   // 调用或其上层调用失败的原因，不为空时转账已回滚，对账时应忽略
+}
+
+// TransactionTrace 一笔交易的追踪结果
+type TransactionTrace struct {
+	TxHash    string              // 交易哈希
+	Call      *CallFrame          // debug 追踪的调用树，Parity 追踪时为 nil
+	Traces    []*ParityTrace      // Parity 追踪按执行顺序排列的记录，debug 追踪时为 nil
+	Transfers []*InternalTransfer // 按执行顺序排列的内部转账，不包括交易本身的转账
+	Error     string              // 节点追踪这笔交易失败的原因（如超时），此时没有追踪结果
+}
+
+// TraceTransaction 使用 callTracer 追踪交易的调用树，并提取内部转账
+//
+// 需要节点开启 debug 命名空间（如 geth 的 --http.api debug），归档节点才能追踪较早的交易。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - txHash: string 交易哈希
+//   - opts: *TraceOptions 追踪选项，为 nil 时使用节点的默认值
+//
+// Returns:
+//   - *TransactionTrace: 交易的调用树和内部转账
+//   - error: 可能的错误：
+//   - 无效的交易哈希格式
+//   - 节点不支持 debug_traceTransaction 或交易不存在（*RPCError）
+//   - 节点连接错误
+func (c *Client) TraceTransaction(ctx context.Context, txHash string, opts *TraceOptions) (*TransactionTrace, error) {
+	if err := validateTxHash(txHash); err != nil {
+		return nil, err
+	}
+	result, err := c.rawRequest(ctx, "debug_traceTransaction", txHash, opts.config("callTracer", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	var raw callFrameJSON
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode call trace: %v", err)
+	}
+	return newCallTrace(txHash, &raw), nil
+}
+
+// TraceBlock 使用 callTracer 追踪区块中的所有交易，并提取每笔交易的内部转账
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - numberOrHash: string 区块号、标签或区块哈希，为空时使用 "latest"
+//   - opts: *TraceOptions 追踪选项，为 nil 时使用节点的默认值
+//
+// Returns:
+//   - []*TransactionTrace: 按交易顺序排列的追踪结果，单笔交易追踪失败时 Error 不为空
+//   - error: 可能的错误：
+//   - 无效的区块号格式
+//   - 节点不支持 debug_traceBlockByNumber 或区块不存在（*RPCError）
+//   - 节点连接错误
+func (c *Client) TraceBlock(ctx context.Context, numberOrHash string, opts *TraceOptions) ([]*TransactionTrace, error) {
+	method, block, err := traceBlockParam(numberOrHash)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.rawRequest(ctx, "debug_"+method, block, opts.config("callTracer", nil))
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []struct {
+		TxHash string         `json:"txHash"`
+		Result *callFrameJSON `json:"result"`
+		Error  string         `json:"error"`
+	}
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode block trace: %v", err)
+	}
+	traces := make([]*TransactionTrace, len(raw))
+	for i, r := range raw {
+		if r.Result == nil {
+			traces[i] = &TransactionTrace{TxHash: r.TxHash, Error: r.Error}
+			continue
+		}
+		traces[i] = newCallTrace(r.TxHash, r.Result)
+	}
+	return traces, nil
+}
+
+// traceBlockParam 根据区块号或哈希选择 traceBlockByNumber 或 traceBlockByHash
+func traceBlockParam(numberOrHash string) (string, any, error) {
+	if len(numberOrHash) == 66 && strings.HasPrefix(numberOrHash, "0x") {
+		return "traceBlockByHash", numberOrHash, nil
+	}
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrHash))
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrHash)
+	}
+	return "traceBlockByNumber", numOrTag, nil
+}
+
+// newCallTrace 转换调用树并按深度优先的执行顺序提取内部转账
+func newCallTrace(txHash string, raw *callFrameJSON) *TransactionTrace {
+	trace := &TransactionTrace{TxHash: txHash, Call: raw.frame()}
+	var walk func(f *CallFrame, address []int, reverted string)
+	walk = func(f *CallFrame, address []int, reverted string) {
+		if f.Error != "" && reverted == "" {
+			reverted = f.Error
+		}
+		if len(address) > 0 {
+			trace.addTransfer(f.Type, f.From, f.To, f.Value, address, reverted)
+		}
+		for i, call := range f.Calls {
+			walk(call, append(address[:len(address):len(address)], i), reverted)
+		}
+	}
+	walk(trace.Call, []int{}, "")
+	return trace
+}
+
+// addTransfer 记录转移了 ETH 的调用，忽略不转移 ETH 的 DELEGATECALL、STATICCALL 和 CALLCODE
+func (t *TransactionTrace) addTransfer(typ, from, to string, value *big.Int, address []int, reverted string) {
+	switch typ {
+	case TraceCall, TraceCreate, TraceCreate2, TraceSelfDestruct:
+	default:
+		return
+	}
+	if value.Sign() == 0 {
+		return
+	}
+	t.Transfers = append(t.Transfers, &InternalTransfer{
+		TxHash:       t.TxHash,
+		Type:         typ,
+		From:         from,
+		To:           to,
+		Value:        value,
+		TraceAddress: address,
+		Error:        reverted,
+	})
+}
+
+// TracePrestate 使用 prestateTracer 追踪交易涉及的账户状态
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - txHash: string 交易哈希
+//   - diffMode: bool 为 true 时同时返回交易执行后的状态，只包含发生变化的账户和字段
+//   - opts: *TraceOptions 追踪选项，为 nil 时使用节点的默认值
+//
+// Returns:
+//   - *PrestateTrace: 交易执行前（和执行后）的账户状态
+//   - error: 可能的错误：
+//   - 无效的交易哈希格式
+//   - 节点不支持 debug_traceTransaction 或交易不存在（*RPCError）
+//   - 节点连接错误
+func (c *Client) TracePrestate(ctx context.Context, txHash string, diffMode bool, opts *TraceOptions) (*PrestateTrace, error) {
+	if err := validateTxHash(txHash); err != nil {
+		return nil, err
+	}
+	result, err := c.rawRequest(ctx, "debug_traceTransaction", txHash, opts.config("prestateTracer", map[string]any{"diffMode": diffMode}))
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &PrestateTrace{}
+	var decodeErr error
+	if diffMode {
+		var raw struct {
+			Pre  map[string]*accountStateJSON `json:"pre"`
+			Post map[string]*accountStateJSON `json:"post"`
+		}
+		if decodeErr = json.Unmarshal(result, &raw); decodeErr == nil {
+			trace.Pre, trace.Post = accountStates(raw.Pre), accountStates(raw.Post)
+		}
+	} else {
+		var raw map[string]*accountStateJSON
+		if decodeErr = json.Unmarshal(result, &raw); decodeErr == nil {
+			trace.Pre = accountStates(raw)
+		}
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode prestate trace: %v", decodeErr)
+	}
+	return trace, nil
+}
+
+// AccountState prestateTracer 返回的账户状态，diff 模式下执行后的状态只包含发生变化的字段
+type AccountState struct {
+	Balance *big.Int          // 余额（wei），未变化时为 nil
+	Nonce   *uint64           // nonce，未变化时为 nil
+	Code    string            // 合约代码
+	Storage map[string]string // 存储槽到值的映射
+}
+
+// accountStateJSON prestateTracer 返回的原始账户状态
+type accountStateJSON struct {
+	Balance *eth.Quantity     `json:"balance"`
+	Nonce   *uint64           `json:"nonce"`
+	Code    string            `json:"code"`
+	Storage map[string]string `json:"storage"`
+}
+
+// accountStates 转换账户状态，地址统一为小写
+func accountStates(raw map[string]*accountStateJSON) map[string]*AccountState {
+	states := make(map[string]*AccountState, len(raw))
+	for addr, s := range raw {
+		state := &AccountState{Nonce: s.Nonce, Code: s.Code, Storage: s.Storage}
+		if s.Balance != nil {
+			state.Balance = s.Balance.Big()
+		}
+		states[strings.ToLower(addr)] = state
+	}
+	return states
+}
+
+// PrestateTrace prestateTracer 的追踪结果
+type PrestateTrace struct {
+	Pre  map[string]*AccountState // 交易执行前的账户状态，以小写地址为键
+	Post map[string]*AccountState // diff 模式下交易执行后发生变化的账户状态，非 diff 模式为 nil
+}
+
+// BalanceChanges 返回 diff 模式下各账户的余额变化（wei），包括手续费、内部转账和区块奖励，
+// 没有变化的账户不包括在内，非 diff 模式返回 nil
+func (p *PrestateTrace) BalanceChanges() map[string]*big.Int {
+	if p.Post == nil {
+		return nil
+	}
+	balance := func(s *AccountState) *big.Int {
+		if s == nil || s.Balance == nil {
+			return new(big.Int)
+		}
+		return s.Balance
+	}
+	changes := make(map[string]*big.Int)
+	for addr, post := range p.Post {
+		if post.Balance == nil {
+			continue
+		}
+		if diff := new(big.Int).Sub(post.Balance, balance(p.Pre[addr])); diff.Sign() != 0 {
+			changes[addr] = diff
+		}
+	}
+	// 只出现在执行前状态中的账户已被删除（如自毁），余额全部转出
+	for addr, pre := range p.Pre {
+		if _, ok := p.Post[addr]; !ok && balance(pre).Sign() != 0 {
+			changes[addr] = new(big.Int).Neg(balance(pre))
+		}
+	}
+	return changes
+}
+
+// TraceFilterQuery trace_filter 的查询条件
+type TraceFilterQuery struct {
+	FromBlock   string   // 起始区块号或标签，为空时使用 "earliest"
+	ToBlock     string   // 结束区块号或标签，为空时使用 "latest"
+	FromAddress []string // 调用方地址，为空时不限制
+	ToAddress   []string // 被调用的地址，为空时不限制
+	After       uint64   // 跳过的记录数，用于分页
+	Count       uint64   // 返回的最大记录数，为0时不限制
+}
+
+// ParityTraceFilter 通过 trace_filter 查询区块范围内的追踪记录，并按交易提取内部转账
+//
+// 节点只返回匹配地址条件的记录，TransactionTrace 中可能只包含交易的部分调用。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - query: TraceFilterQuery 查询条件
+//
+// Returns:
+//   - []*TransactionTrace: 按交易顺序排列的追踪结果，不包括区块奖励
+//   - error: 可能的错误：
+//   - 无效的地址或区块号格式
+//   - 节点不支持 trace_filter（*RPCError）
+//   - 节点连接错误
+func (c *Client) ParityTraceFilter(ctx context.Context, query TraceFilterQuery) ([]*TransactionTrace, error) {
+	if query.FromBlock == "" {
+		query.FromBlock = string(eth.TagEarliest)
+	}
+	from, err := eth.NewBlockNumberOrTag(query.FromBlock)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, query.FromBlock)
+	}
+	to, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(query.ToBlock))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, query.ToBlock)
+	}
+	filter := map[string]any{"fromBlock": from, "toBlock": to}
+	for key, addresses := range map[string][]string{"fromAddress": query.FromAddress, "toAddress": query.ToAddress} {
+		if len(addresses) == 0 {
+			continue
+		}
+		for _, addr := range addresses {
+			if !common.IsHexAddress(addr) {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidAddress, addr)
+			}
+		}
+		filter[key] = addresses
+	}
+	if query.After > 0 {
+		filter["after"] = query.After
+	}
+	if query.Count > 0 {
+		filter["count"] = query.Count
+	}
+	return c.parityTraces(ctx, "trace_filter", filter)
+}
+
+// ParityTraceBlock 通过 trace_block 查询区块中的所有追踪记录，并按交易提取内部转账
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - numberOrTag: string 区块号或标签，为空时使用 "latest"
+//
+// Returns:
+//   - []*TransactionTrace: 按交易顺序排列的追踪结果，不包括区块奖励
+//   - error: 可能的错误：
+//   - 无效的区块号格式
+//   - 区块不存在（满足 errors.Is(err, ErrNotFound)）
+//   - 节点不支持 trace_block（*RPCError）
+//   - 节点连接错误
+func (c *Client) ParityTraceBlock(ctx context.Context, numberOrTag string) ([]*TransactionTrace, error) {
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
+	}
+	return c.parityTraces(ctx, "trace_block", numOrTag)
+}
+
+// parityTraces 发送返回 Parity 追踪记录的请求，按交易分组并提取内部转账
+func (c *Client) parityTraces(ctx context.Context, method string, param any) ([]*TransactionTrace, error) {
+	result, err := c.rawRequest(ctx, method, param)
+	if err != nil {
+		return nil, err
+	}
+	if string(result) == "null" {
+		return nil, fmt.Errorf("%w: %s returned no traces", ErrNotFound, method)
+	}
+
+	var raw []parityTraceJSON
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %v", method, err)
+	}
+	var (
+		traces   []*TransactionTrace
+		byHash   = make(map[string]*TransactionTrace)
+		reverted = make(map[string]string) // 交易哈希和失败调用的位置到失败原因的映射
+	)
+	for i := range raw {
+		p := raw[i].trace()
+		if p.TxHash == "" {
+			continue
+		}
+		trace, ok := byHash[p.TxHash]
+		if !ok {
+			trace = &TransactionTrace{TxHash: p.TxHash, Traces: []*ParityTrace{}}
+			byHash[p.TxHash] = trace
+			traces = append(traces, trace)
+		}
+		trace.Traces = append(trace.Traces, p)
+
+		// 上层调用失败时子调用的转账同样被回滚，上层调用总是先于子调用出现
+		reason := p.Error
+		for depth := 0; depth < len(p.TraceAddress) && reason == ""; depth++ {
+			reason = reverted[fmt.Sprint(p.TxHash, p.TraceAddress[:depth])]
+		}
+		if reason != "" {
+			reverted[fmt.Sprint(p.TxHash, p.TraceAddress)] = reason
+		}
+		if len(p.TraceAddress) == 0 {
+			continue
+		}
+		typ := TraceCall
+		switch {
+		case p.Type == "call" && p.CallType != "" && p.CallType != "call":
+			continue
+		case p.Type == "create":
+			typ = TraceCreate
+		case p.Type == "suicide":
+			typ = TraceSelfDestruct
+		case p.Type != "call":
+			continue
+		}
+		trace.addTransfer(typ, p.From, p.To, p.Value, p.TraceAddress, reason)
+	}
+	return traces, nil
+}
+
+// validateTxHash 校验交易哈希的格式
+func validateTxHash(txHash string) error {
+	if len(txHash) < 2 || txHash[:2] != "0x" {
+		return fmt.Errorf("%w: transaction hash must be a hex string starting with 0x", ErrInvalidHash)
+	}
+	return nil
+}
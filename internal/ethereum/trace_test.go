@@ -0,0 +1,182 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	traceTxHash   = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	traceContract = "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"
+	traceUser     = "0x0000000000000000000000000000000000000001"
+	traceOther    = "0x0000000000000000000000000000000000000002"
+)
+
+// traceRequester 按方法名返回预设的结果，并记录每个方法最近一次的参数
+func traceRequester(results map[string]string, params map[string][]any) requesterFunc {
+	return func(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+		var p []any
+		b, _ := json.Marshal(r.Params)
+		json.Unmarshal(b, &p)
+		params[r.Method] = p
+		result, ok := results[r.Method]
+		if !ok {
+			rpcErr := json.RawMessage(fmt.Sprintf(`{"code":-32601,"message":"the method %s does not exist/is not available"}`, r.Method))
+			return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+		}
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(result)}, nil
+	}
+}
+
+// callTrace 顶层调用合约转入1 ETH，合约向 traceOther 转出0.4 ETH 并委托调用自身，
+// 第二个子调用失败，其中的转账被回滚
+var callTrace = fmt.Sprintf(`{"type":"CALL","from":%[1]q,"to":%[2]q,"value":"0xde0b6b3a7640000","gas":"0x30d40","gasUsed":"0x1d4c0","input":"0x7ff36ab5","output":"0x","calls":[
+	{"type":"CALL","from":%[2]q,"to":%[3]q,"value":"0x58d15e176280000","gas":"0x8fc","gasUsed":"0x0","input":"0x"},
+	{"type":"DELEGATECALL","from":%[2]q,"to":%[2]q,"gas":"0x1000","gasUsed":"0x800","input":"0x","error":"execution reverted","calls":[
+		{"type":"CALL","from":%[2]q,"to":%[1]q,"value":"0x1","gas":"0x8fc","gasUsed":"0x0","input":"0x"},
+		{"type":"STATICCALL","from":%[2]q,"to":%[3]q,"gas":"0x8fc","gasUsed":"0x0","input":"0x"}
+	]},
+	{"type":"SELFDESTRUCT","from":"0x00000000000000000000000000000000000000AB","to":%[3]q,"value":"0x2","gas":"0x0","gasUsed":"0x0","input":"0x"}
+]}`, traceUser, traceContract, traceOther)
+
+func TestTraceTransaction(t *testing.T) {
+	params := make(map[string][]any)
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": traceRequester(map[string]string{
+		"debug_traceTransaction":   callTrace,
+		"debug_traceBlockByNumber": fmt.Sprintf(`[{"txHash":%q,"result":%s},{"txHash":"0x01","error":"execution timeout"}]`, traceTxHash, callTrace),
+		"debug_traceBlockByHash":   `[]`,
+	}, params)}, Endpoint{URL: "ws://node"})
+	ctx := context.Background()
+
+	trace, err := c.TraceTransaction(ctx, traceTxHash, &TraceOptions{Timeout: 30 * time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{traceTxHash, map[string]any{"tracer": "callTracer", "timeout": "30s"}}, params["debug_traceTransaction"])
+	assert.Equal(t, "1000000000000000000", trace.Call.Value.String())
+	assert.Equal(t, uint64(120000), trace.Call.GasUsed)
+	assert.Len(t, trace.Call.Calls, 3)
+	assert.Equal(t, "0", trace.Call.Calls[1].Value.String())
+	assert.Equal(t, "STATICCALL", trace.Call.Calls[1].Calls[1].Type)
+
+	// 顶层调用、DELEGATECALL 和 STATICCALL 不是内部转账，失败调用中的转账标记为已回滚
+	if !assert.Len(t, trace.Transfers, 3) {
+		return
+	}
+	assert.Equal(t, &InternalTransfer{TxHash: traceTxHash, Type: TraceCall, From: traceContract, To: traceOther, Value: big.NewInt(400000000000000000), TraceAddress: []int{0}}, trace.Transfers[0])
+	assert.Equal(t, []int{1, 0}, trace.Transfers[1].TraceAddress)
+	assert.Equal(t, "execution reverted", trace.Transfers[1].Error)
+	assert.Equal(t, TraceSelfDestruct, trace.Transfers[2].Type)
+	assert.Equal(t, "0x00000000000000000000000000000000000000ab", trace.Transfers[2].From)
+	assert.Empty(t, trace.Transfers[2].Error)
+
+	// 区块追踪，单笔交易追踪失败时只返回错误信息
+	traces, err := c.TraceBlock(ctx, "0x10", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"0x10", map[string]any{"tracer": "callTracer"}}, params["debug_traceBlockByNumber"])
+	assert.Len(t, traces, 2)
+	assert.Len(t, traces[0].Transfers, 3)
+	assert.Equal(t, traceTxHash, traces[0].Transfers[0].TxHash)
+	assert.Equal(t, "execution timeout", traces[1].Error)
+	assert.Nil(t, traces[1].Call)
+	_, err = c.TraceBlock(ctx, traceTxHash, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, traceTxHash, params["debug_traceBlockByHash"][0])
+
+	_, err = c.TraceTransaction(ctx, "123", nil)
+	assert.ErrorIs(t, err, ErrInvalidHash)
+	_, err = c.TraceBlock(ctx, "invalid", nil)
+	assert.ErrorIs(t, err, ErrInvalidBlockTag)
+}
+
+func TestTracePrestate(t *testing.T) {
+	params := make(map[string][]any)
+	results := map[string]string{}
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": traceRequester(results, params)}, Endpoint{URL: "ws://node"})
+	ctx := context.Background()
+
+	results["debug_traceTransaction"] = fmt.Sprintf(`{%q:{"balance":"0x64","nonce":3},%q:{"balance":"0x0","code":"0x6080","storage":{"0x01":"0x02"}}}`, traceUser, traceContract)
+	trace, err := c.TracePrestate(ctx, traceTxHash, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"tracer": "prestateTracer", "tracerConfig": map[string]any{"diffMode": false}}, params["debug_traceTransaction"][1])
+	assert.Equal(t, "100", trace.Pre[traceUser].Balance.String())
+	assert.Equal(t, uint64(3), *trace.Pre[traceUser].Nonce)
+	assert.Equal(t, "0x02", trace.Pre[traceContract].Storage["0x01"])
+	assert.Nil(t, trace.Post)
+	assert.Nil(t, trace.BalanceChanges())
+
+	// diff 模式：发送方支付 0x14，合约收到 0x0a 后转出 0x05，自毁合约的余额全部转出
+	results["debug_traceTransaction"] = fmt.Sprintf(`{"pre":{%[1]q:{"balance":"0x64","nonce":3},%[2]q:{"balance":"0x0"},"0x00000000000000000000000000000000000000Ab":{"balance":"0x7"}},
+		"post":{%[1]q:{"balance":"0x50","nonce":4},%[2]q:{"balance":"0x5"},%[3]q:{"balance":"0x5"}}}`, traceUser, traceContract, traceOther)
+	trace, err = c.TracePrestate(ctx, traceTxHash, true, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*big.Int{
+		traceUser:     big.NewInt(-20),
+		traceContract: big.NewInt(5),
+		traceOther:    big.NewInt(5),
+		"0x00000000000000000000000000000000000000ab": big.NewInt(-7),
+	}, trace.BalanceChanges())
+}
+
+func TestParityTraces(t *testing.T) {
+	params := make(map[string][]any)
+	parityTrace := func(typ, action, result, errMsg, traceAddress, txHash string) string {
+		return fmt.Sprintf(`{"type":%q,"action":%s,"result":%s,"error":%q,"traceAddress":%s,"subtraces":0,"blockNumber":16,"blockHash":"0xb1","transactionHash":%s,"transactionPosition":0}`,
+			typ, action, result, errMsg, traceAddress, txHash)
+	}
+	tx := fmt.Sprintf("%q", traceTxHash)
+	block := "[" + parityTrace("call", fmt.Sprintf(`{"callType":"call","from":%q,"to":%q,"value":"0x10","gas":"0x100","input":"0x"}`, traceUser, traceContract), `{"gasUsed":"0x50","output":"0x"}`, "", "[]", tx) +
+		"," + parityTrace("call", fmt.Sprintf(`{"callType":"call","from":%q,"to":%q,"value":"0x4","gas":"0x10","input":"0x"}`, traceContract, traceOther), `{"gasUsed":"0x0","output":"0x"}`, "", "[0]", tx) +
+		"," + parityTrace("call", fmt.Sprintf(`{"callType":"delegatecall","from":%q,"to":%q,"value":"0x4","gas":"0x10","input":"0x"}`, traceContract, traceOther), `null`, "Reverted", "[1]", tx) +
+		"," + parityTrace("create", fmt.Sprintf(`{"from":%q,"value":"0x2","gas":"0x10","init":"0x6080"}`, traceContract), `null`, "", "[1,0]", tx) +
+		"," + parityTrace("create", fmt.Sprintf(`{"from":%q,"value":"0x3","gas":"0x10","init":"0x6080"}`, traceContract), fmt.Sprintf(`{"gasUsed":"0x8","address":%q,"code":"0x60"}`, traceOther), "", "[2]", tx) +
+		"," + parityTrace("suicide", fmt.Sprintf(`{"address":%q,"refundAddress":%q,"balance":"0x1"}`, traceOther, traceUser), `null`, "", "[3]", tx) +
+		"," + parityTrace("reward", fmt.Sprintf(`{"author":%q,"value":"0x1bc16d674ec80000","rewardType":"block"}`, traceUser), `null`, "", "[]", "null") + "]"
+	results := map[string]string{"trace_block": block, "trace_filter": block}
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": traceRequester(results, params)}, Endpoint{URL: "ws://node"})
+	ctx := context.Background()
+
+	traces, err := c.ParityTraceBlock(ctx, "0x10")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"0x10"}, params["trace_block"])
+	if !assert.Len(t, traces, 1) {
+		return
+	}
+	assert.Len(t, traces[0].Traces, 6)
+	assert.Equal(t, uint64(0x50), traces[0].Traces[0].GasUsed)
+	assert.Equal(t, "0x60", traces[0].Traces[4].Output)
+
+	// 顶层调用、delegatecall 和区块奖励不是内部转账，失败调用的子调用同样被回滚
+	transfers := traces[0].Transfers
+	if !assert.Len(t, transfers, 4) {
+		return
+	}
+	assert.Equal(t, &InternalTransfer{TxHash: traceTxHash, Type: TraceCall, From: traceContract, To: traceOther, Value: big.NewInt(4), TraceAddress: []int{0}}, transfers[0])
+	assert.Equal(t, TraceCreate, transfers[1].Type)
+	assert.Equal(t, "Reverted", transfers[1].Error)
+	assert.Empty(t, transfers[1].To)
+	assert.Equal(t, traceOther, transfers[2].To)
+	assert.Empty(t, transfers[2].Error)
+	assert.Equal(t, &InternalTransfer{TxHash: traceTxHash, Type: TraceSelfDestruct, From: traceOther, To: traceUser, Value: big.NewInt(1), TraceAddress: []int{3}}, transfers[3])
+
+	_, err = c.ParityTraceFilter(ctx, TraceFilterQuery{FromBlock: "0x1", ToAddress: []string{traceContract}, Count: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"fromBlock": "0x1", "toBlock": "latest", "toAddress": []any{traceContract}, "count": float64(100)}}, params["trace_filter"])
+
+	_, err = c.ParityTraceFilter(ctx, TraceFilterQuery{FromAddress: []string{"0x123"}})
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = c.ParityTraceFilter(ctx, TraceFilterQuery{ToBlock: "invalid"})
+	assert.ErrorIs(t, err, ErrInvalidBlockTag)
+	results["trace_block"] = "null"
+	_, err = c.ParityTraceBlock(ctx, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	delete(results, "trace_block")
+	_, err = c.ParityTraceBlock(ctx, "")
+	var rpcErr *RPCError
+	assert.ErrorAs(t, err, &rpcErr)
+}
@@ -28,17 +28,17 @@ func (c *Client) GetTransactionCount(ctx context.Context, address string, number
 	// 验证并转换地址格式
 	addr, err := eth.NewAddress(address)
 	if err != nil {
-		return 0, fmt.Errorf("invalid ethereum address: %v", err)
+		return 0, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
 	}
 
 	// 验证并转换区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return 0, fmt.Errorf("invalid block number or tag: %s", numberOrTag)
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getTransactionCount", func(conn node.Client) (any, error) {
 		return conn.GetTransactionCount(ctx, *addr, *numOrTag)
 	})
 	if err != nil {
@@ -62,11 +62,11 @@ func (c *Client) GetTransactionCount(ctx context.Context, address string, number
 func (c *Client) GetTransactionByHash(ctx context.Context, txHash string) (*eth.Transaction, error) {
 	// 验证交易哈希格式
 	if len(txHash) < 2 || txHash[:2] != "0x" {
-		return nil, fmt.Errorf("invalid transaction hash format: must be hex string starting with 0x")
+		return nil, fmt.Errorf("%w: transaction hash must be a hex string starting with 0x", ErrInvalidHash)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getTransactionByHash", func(conn node.Client) (any, error) {
 		return conn.TransactionByHash(ctx, txHash)
 	})
 	if err != nil {
@@ -87,14 +87,16 @@ func (c *Client) GetTransactionByHash(ctx context.Context, txHash string) (*eth.
 //   - error: 可能的错误：
 //   - 无效的交易数据格式
 //   - 节点连接错误
+//
+// 请求失败时默认不重试，ClientOptions.Retry.RetrySendRawTransaction 为 true 时按重试策略重试。
 func (c *Client) SendRawTransaction(ctx context.Context, signedTxData string) (string, error) {
 	// 验证交易数据格式
 	if len(signedTxData) < 2 || signedTxData[:2] != "0x" {
 		return "", fmt.Errorf("invalid transaction data format: must be hex string starting with 0x")
 	}
 
-	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	// 重复广播可能被节点拒绝（如 "already known"），只在显式开启时重试
+	result, err := c.retryConnection(ctx, c.retry.RetrySendRawTransaction, []string{"eth_sendRawTransaction"}, func(conn node.Client) (any, error) {
 		return conn.SendRawTransaction(ctx, signedTxData)
 	})
 	if err != nil {
@@ -119,11 +121,11 @@ func (c *Client) SendRawTransaction(ctx context.Context, signedTxData string) (s
 func (c *Client) GetTransactionByBlockHashAndIndex(ctx context.Context, blockHash string, index uint64) (*eth.Transaction, error) {
 	// 验证区块哈希格式
 	if len(blockHash) < 2 || blockHash[:2] != "0x" {
-		return nil, fmt.Errorf("invalid block hash format: must be hex string starting with 0x")
+		return nil, fmt.Errorf("%w: block hash must be a hex string starting with 0x", ErrInvalidHash)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getTransactionByBlockHashAndIndex", func(conn node.Client) (any, error) {
 		return conn.GetTransactionByBlockHashAndIndex(ctx, blockHash, index)
 	})
 	if err != nil {
@@ -152,13 +154,13 @@ func (c *Client) GetTransactionByBlockHashAndIndex(ctx context.Context, blockHas
 //   - 交易不存在
 func (c *Client) GetTransactionByBlockNumberAndIndex(ctx context.Context, numberOrTag string, index uint64) (*eth.Transaction, error) {
 	// 处理默认值并验证区块号格式
-	numOrTag := eth.MustBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
-	if numOrTag == nil {
-		return nil, fmt.Errorf("invalid block number or tag: %s", numberOrTag)
+	numOrTag, err := eth.NewBlockNumberOrTag(getDefaultNumberOrTag(numberOrTag))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTag, numberOrTag)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getTransactionByBlockNumberAndIndex", func(conn node.Client) (any, error) {
 		return conn.GetTransactionByBlockNumberAndIndex(ctx, *numOrTag, index)
 	})
 	if err != nil {
@@ -183,11 +185,11 @@ func (c *Client) GetTransactionByBlockNumberAndIndex(ctx context.Context, number
 func (c *Client) GetTransactionReceipt(ctx context.Context, txHash string) (*eth.TransactionReceipt, error) {
 	// 验证交易哈希格式
 	if len(txHash) < 2 || txHash[:2] != "0x" {
-		return nil, fmt.Errorf("invalid transaction hash format: must be hex string starting with 0x")
+		return nil, fmt.Errorf("%w: transaction hash must be a hex string starting with 0x", ErrInvalidHash)
 	}
 
 	// 使用通用的连接池辅助函数执行操作
-	result, err := c.withConnection(ctx, func(conn node.Client) (any, error) {
+	result, err := c.withConnection(ctx, "eth_getTransactionReceipt", func(conn node.Client) (any, error) {
 		return conn.TransactionReceipt(ctx, txHash)
 	})
 	if err != nil {
@@ -0,0 +1,474 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+)
+
+const (
+	defaultBlobFeeMultiplier = 2     // 未指定 MaxFeePerBlobGas 时 blob 基础费用的倍数
+	transferGas              = 21000 // 普通转账的 gas 消耗
+)
+
+// TxType 交易类型
+type TxType int
+
+const (
+	TxTypeAuto       TxType = iota // 根据 TxRequest 的字段推断：有 Blobs 时为 blob 交易，有 GasPrice 时为传统或访问列表交易，否则为 EIP-1559 交易
+	TxTypeLegacy                   // 传统交易（EIP-155）
+	TxTypeAccessList               // EIP-2930 访问列表交易
+	TxTypeDynamicFee               // EIP-1559 交易
+	TxTypeBlob                     // EIP-4844 blob 交易
+)
+
+// String 返回交易类型的名称
+func (t TxType) String() string {
+	switch t {
+	case TxTypeLegacy:
+		return "legacy"
+	case TxTypeAccessList:
+		return "access-list"
+	case TxTypeDynamicFee:
+		return "dynamic-fee"
+	case TxTypeBlob:
+		return "blob"
+	default:
+		return "auto"
+	}
+}
+
+// TxRequest 待构建交易的参数，未设置的 nonce、gas 和费用由 TxBuilder 从节点获取
+type TxRequest struct {
+	Type                 TxType           // 交易类型，默认根据字段推断
+	To                   string           // 接收方地址，为空时部署合约（blob 交易必需）
+	Value                *big.Int         // 可选，转账金额（单位：wei）
+	Data                 string           // 可选，十六进制编码的调用数据或合约字节码
+	Nonce                *uint64          // 可选，为 nil 时使用发送方 pending 状态的交易数
+	Gas                  uint64           // 可选，gas 限制，为0时通过 eth_estimateGas 估算
+	GasPrice             *big.Int         // 可选，传统和访问列表交易的 gas 价格，为 nil 时使用 eth_gasPrice
+	MaxFeePerGas         *big.Int         // 可选，EIP-1559 和 blob 交易的最高单价，为 nil 时使用 SuggestFees 的标准档
+	MaxPriorityFeePerGas *big.Int         // 可选，EIP-1559 和 blob 交易的优先费，为 nil 时使用 SuggestFees 的标准档
+	AccessList           types.AccessList // 可选，访问列表，传统交易不支持
+	Blobs                []kzg4844.Blob   // blob 交易携带的数据，构建时计算 KZG 承诺和证明
+	MaxFeePerBlobGas     *big.Int         // 可选，blob gas 的最高单价，为 nil 时使用当前 blob 基础费用的2倍
+}
+
+// TxBuilderOptions TxBuilder 的选项，零值字段使用默认值
+type TxBuilderOptions struct {
+	ChainID       uint64            // 链ID，为0时通过 eth_chainId 查询并缓存
+	GasMultiplier float64           // 估算 gas 的倍数，用于预留余量，默认为1（不调整）
+	FeeOracle     *FeeOracleOptions // 预估 EIP-1559 费用的选项，为 nil 时使用默认值
+	Nonces        *NonceManager     // 可选，Send 通过 nonce 管理器分配 nonce，多个构建器并发发送时应共享同一个管理器
+}
+
+// withDefaults 返回补全默认值后的选项
+func (o *TxBuilderOptions) withDefaults() TxBuilderOptions {
+	opts := TxBuilderOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.GasMultiplier <= 0 {
+		opts.GasMultiplier = 1
+	}
+	return opts
+}
+
+// TxBuilder 构建、签名并发送交易，具有以下特性：
+//   - 支持传统、EIP-2930、EIP-1559 和 EIP-4844 交易
+//   - 自动填充 nonce（pending 状态的交易数，配置 NonceManager 时由其分配）、gas 限制、费用和链ID
+//   - 通过 Signer 接口签名，签名后校验发送方地址
+type TxBuilder struct {
+	c      *Client
+	signer Signer
+	opts   TxBuilderOptions
+
+	mu      sync.Mutex
+	chainID *big.Int // 缓存的链ID
+}
+
+// NewTxBuilder 创建交易构建器
+//
+// Parameters:
+//   - signer: Signer 交易签名者，其地址作为交易的发送方
+//   - opts: *TxBuilderOptions 配置选项，为 nil 时使用默认值
+//
+// Returns:
+//   - *TxBuilder: 交易构建器
+func (c *Client) NewTxBuilder(signer Signer, opts *TxBuilderOptions) *TxBuilder {
+	b := &TxBuilder{c: c, signer: signer, opts: opts.withDefaults()}
+	if b.opts.ChainID != 0 {
+		b.chainID = new(big.Int).SetUint64(b.opts.ChainID)
+	}
+	return b
+}
+
+// From 返回交易的发送方地址
+func (b *TxBuilder) From() string {
+	return b.signer.Address()
+}
+
+// ChainID 返回签名使用的链ID，未配置时查询节点并缓存
+func (b *TxBuilder) ChainID(ctx context.Context) (*big.Int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.chainID == nil {
+		id, err := b.c.ChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.chainID = new(big.Int).SetUint64(id)
+	}
+	return new(big.Int).Set(b.chainID), nil
+}
+
+// Build 根据请求构建未签名的交易
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - req: TxRequest 交易参数
+//
+// Returns:
+//   - *types.Transaction: 填充完整的未签名交易
+//   - error: 可能的错误：
+//   - 无效的地址、数据或金额
+//   - 交易类型与字段不匹配（如传统交易带访问列表、blob 交易没有接收方）
+//   - gas 估算失败（如交易会回滚）
+//   - 节点连接错误
+func (b *TxBuilder) Build(ctx context.Context, req TxRequest) (*types.Transaction, error) {
+	txType := req.inferType()
+	var to *common.Address
+	if req.To != "" {
+		if !common.IsHexAddress(req.To) {
+			return nil, fmt.Errorf("%w: to %s", ErrInvalidAddress, req.To)
+		}
+		addr := common.HexToAddress(req.To)
+		to = &addr
+	}
+	var data []byte
+	if req.Data != "" {
+		var err error
+		if data, err = hexutil.Decode(req.Data); err != nil {
+			return nil, fmt.Errorf("invalid data: %v", err)
+		}
+	}
+	value := new(big.Int)
+	if req.Value != nil {
+		if req.Value.Sign() < 0 {
+			return nil, fmt.Errorf("invalid value: must not be negative")
+		}
+		value.Set(req.Value)
+	}
+	switch {
+	case txType < TxTypeLegacy || txType > TxTypeBlob:
+		return nil, fmt.Errorf("unsupported transaction type %d", txType)
+	case txType == TxTypeLegacy && req.AccessList != nil:
+		return nil, fmt.Errorf("legacy transaction does not support access list")
+	case txType == TxTypeBlob && to == nil:
+		return nil, fmt.Errorf("blob transaction must have a recipient")
+	case txType == TxTypeBlob && len(req.Blobs) == 0:
+		return nil, fmt.Errorf("blob transaction must carry at least one blob")
+	case txType != TxTypeBlob && len(req.Blobs) > 0:
+		return nil, fmt.Errorf("%s transaction does not support blobs", txType)
+	}
+
+	chainID, err := b.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := b.nonce(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := CallMsg{From: b.From(), To: req.To, Value: value, Data: req.Data}
+	var gasPrice, feeCap, tipCap *big.Int
+	if txType == TxTypeLegacy || txType == TxTypeAccessList {
+		if gasPrice = req.GasPrice; gasPrice == nil {
+			if gasPrice, err = b.c.GasPriceBig(ctx); err != nil {
+				return nil, err
+			}
+		}
+		msg.GasPrice = gasPrice
+	} else {
+		if feeCap, tipCap, err = b.dynamicFees(ctx, req); err != nil {
+			return nil, err
+		}
+		msg.MaxFeePerGas, msg.MaxPriorityFeePerGas = feeCap, tipCap
+	}
+
+	gas := req.Gas
+	if gas == 0 {
+		estimated, err := b.c.EstimateGasWithMsg(ctx, msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		gas = uint64(float64(estimated) * b.opts.GasMultiplier)
+	}
+
+	switch txType {
+	case TxTypeLegacy:
+		return types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: gas, To: to, Value: value, Data: data}), nil
+	case TxTypeAccessList:
+		return types.NewTx(&types.AccessListTx{
+			ChainID: chainID, Nonce: nonce, GasPrice: gasPrice, Gas: gas, To: to, Value: value, Data: data, AccessList: req.AccessList,
+		}), nil
+	case TxTypeDynamicFee:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID: chainID, Nonce: nonce, GasTipCap: tipCap, GasFeeCap: feeCap, Gas: gas, To: to, Value: value, Data: data, AccessList: req.AccessList,
+		}), nil
+	}
+	return b.blobTx(ctx, req, chainID, nonce, gas, *to, value, data, feeCap, tipCap)
+}
+
+// inferType 返回请求的交易类型，TxTypeAuto 时根据字段推断
+func (r *TxRequest) inferType() TxType {
+	switch {
+	case r.Type != TxTypeAuto:
+		return r.Type
+	case len(r.Blobs) > 0:
+		return TxTypeBlob
+	case r.GasPrice != nil && r.AccessList != nil:
+		return TxTypeAccessList
+	case r.GasPrice != nil:
+		return TxTypeLegacy
+	default:
+		return TxTypeDynamicFee
+	}
+}
+
+// nonce 返回请求指定的 nonce，未指定时使用发送方 pending 状态的交易数
+func (b *TxBuilder) nonce(ctx context.Context, req TxRequest) (uint64, error) {
+	if req.Nonce != nil {
+		return *req.Nonce, nil
+	}
+	return b.c.GetTransactionCount(ctx, b.From(), "pending")
+}
+
+// dynamicFees 返回 EIP-1559 的 maxFeePerGas 和 maxPriorityFeePerGas，未指定的字段使用 SuggestFees 的标准档
+func (b *TxBuilder) dynamicFees(ctx context.Context, req TxRequest) (*big.Int, *big.Int, error) {
+	feeCap, tipCap := req.MaxFeePerGas, req.MaxPriorityFeePerGas
+	if feeCap == nil || tipCap == nil {
+		fees, err := b.c.SuggestFees(ctx, b.opts.FeeOracle)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tipCap == nil {
+			tipCap = fees.Standard.MaxPriorityFeePerGas
+		}
+		if feeCap == nil {
+			feeCap = new(big.Int).Sub(fees.Standard.MaxFeePerGas, fees.Standard.MaxPriorityFeePerGas)
+			feeCap.Add(feeCap, tipCap)
+		}
+	}
+	if feeCap.Sign() < 0 || tipCap.Sign() < 0 {
+		return nil, nil, fmt.Errorf("invalid fees: must not be negative")
+	}
+	if feeCap.Cmp(tipCap) < 0 {
+		return nil, nil, fmt.Errorf("max fee per gas %s is less than max priority fee per gas %s", feeCap, tipCap)
+	}
+	return feeCap, tipCap, nil
+}
+
+// blobTx 计算 blob 的 KZG 承诺和证明并构建 blob 交易
+func (b *TxBuilder) blobTx(ctx context.Context, req TxRequest, chainID *big.Int, nonce, gas uint64, to common.Address, value *big.Int, data []byte, feeCap, tipCap *big.Int) (*types.Transaction, error) {
+	blobFeeCap := req.MaxFeePerBlobGas
+	if blobFeeCap == nil {
+		blobBaseFee, err := b.c.BlobBaseFee(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blobFeeCap = new(big.Int).Mul(blobBaseFee, big.NewInt(defaultBlobFeeMultiplier))
+	}
+
+	sidecar := &types.BlobTxSidecar{Blobs: req.Blobs}
+	for i := range req.Blobs {
+		commitment, err := kzg4844.BlobToCommitment(&req.Blobs[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute commitment of blob %d: %v", i, err)
+		}
+		proof, err := kzg4844.ComputeBlobProof(&req.Blobs[i], commitment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute proof of blob %d: %v", i, err)
+		}
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+
+	tx := &types.BlobTx{
+		Nonce:      nonce,
+		Gas:        gas,
+		To:         to,
+		Data:       data,
+		AccessList: req.AccessList,
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	}
+	var err error
+	for _, f := range []struct {
+		name string
+		dst  **uint256.Int
+		v    *big.Int
+	}{
+		{"chain id", &tx.ChainID, chainID},
+		{"value", &tx.Value, value},
+		{"max fee per gas", &tx.GasFeeCap, feeCap},
+		{"max priority fee per gas", &tx.GasTipCap, tipCap},
+		{"max fee per blob gas", &tx.BlobFeeCap, blobFeeCap},
+	} {
+		if *f.dst, err = toUint256(f.name, f.v); err != nil {
+			return nil, err
+		}
+	}
+	return types.NewTx(tx), nil
+}
+
+// toUint256 将金额转换为 uint256，负数或超过256位时返回错误
+func toUint256(name string, v *big.Int) (*uint256.Int, error) {
+	u, overflow := uint256.FromBig(v)
+	if overflow || v.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s: %s", name, v)
+	}
+	return u, nil
+}
+
+// Sign 构建交易并使用 Signer 签名
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - req: TxRequest 交易参数
+//
+// Returns:
+//   - *types.Transaction: 已签名的交易，blob 交易包含 sidecar
+//   - error: 构建失败、签名失败或签名地址与 Signer 地址不一致时返回错误
+func (b *TxBuilder) Sign(ctx context.Context, req TxRequest) (*types.Transaction, error) {
+	tx, err := b.Build(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return b.SignTx(ctx, tx)
+}
+
+// SignTx 使用 Signer 签名已构建的交易，并校验恢复出的发送方地址
+func (b *TxBuilder) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	chainID, err := b.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := b.signer.SignTx(ctx, tx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction signature: %v", err)
+	}
+	if sender != common.HexToAddress(b.From()) {
+		return nil, fmt.Errorf("transaction signed by %s, expected %s", sender.Hex(), b.From())
+	}
+	return signed, nil
+}
+
+// Send 构建、签名并通过 eth_sendRawTransaction 发送交易
+//
+// 配置了 TxBuilderOptions.Nonces 且请求未指定 nonce 时，由 nonce 管理器分配 nonce 并处理 nonce 冲突。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - req: TxRequest 交易参数
+//
+// Returns:
+//   - *types.Transaction: 已发送的交易，可以通过 Hash() 获取交易哈希并使用 WaitForReceipt 等待打包
+//   - error: 构建、签名或发送失败时返回错误，节点拒绝交易时为 *RPCError
+func (b *TxBuilder) Send(ctx context.Context, req TxRequest) (*types.Transaction, error) {
+	if b.opts.Nonces != nil && req.Nonce == nil {
+		var signed *types.Transaction
+		_, _, err := b.opts.Nonces.Send(ctx, b.From(), b.signFunc(req, &signed))
+		if err != nil {
+			return nil, err
+		}
+		return signed, nil
+	}
+
+	signed, err := b.Sign(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.SendTx(ctx, signed); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// FillNonce 使用指定的 nonce 发送一笔发给自己的零金额交易，用于填补被丢弃的交易造成的空缺
+//
+// 配置了 TxBuilderOptions.Nonces 时 nonce 必须已由管理器分配，可以通过 NonceManager.Gaps 获取空缺的 nonce。
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//   - nonce: uint64 要填补的 nonce
+//   - req: TxRequest 可选的费用参数，To、Value、Data、Nonce 和 Gas 会被覆盖
+//
+// Returns:
+//   - *types.Transaction: 已发送的交易
+//   - error: 构建、签名或发送失败时返回错误
+func (b *TxBuilder) FillNonce(ctx context.Context, nonce uint64, req TxRequest) (*types.Transaction, error) {
+	req.To, req.Value, req.Data, req.Gas = b.From(), nil, "", transferGas
+	req.Nonce = &nonce
+	if b.opts.Nonces == nil {
+		return b.Send(ctx, req)
+	}
+
+	var signed *types.Transaction
+	if _, err := b.opts.Nonces.FillGap(ctx, b.From(), nonce, b.signFunc(req, &signed)); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// signFunc 返回使用分配的 nonce 构建并签名交易的 SignFunc，签名后的交易保存到 signed
+func (b *TxBuilder) signFunc(req TxRequest, signed **types.Transaction) SignFunc {
+	return func(ctx context.Context, nonce uint64) (string, error) {
+		req.Nonce = &nonce
+		tx, err := b.Sign(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return "", fmt.Errorf("failed to encode transaction: %v", err)
+		}
+		*signed = tx
+		return hexutil.Encode(raw), nil
+	}
+}
+
+// SendTx 发送已签名的交易
+func (b *TxBuilder) SendTx(ctx context.Context, tx *types.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %v", err)
+	}
+	_, err = b.c.SendRawTransaction(ctx, hexutil.Encode(raw))
+	return err
+}
+
+// BlobBaseFee 获取下一个区块的 blob 基础费用
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//
+// Returns:
+//   - *big.Int: 每单位 blob gas 的基础费用（单位：wei）
+//   - error: 节点不支持 EIP-4844 或连接错误时返回错误
+func (c *Client) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	return c.requestQuantity(ctx, "eth_blobBaseFee")
+}
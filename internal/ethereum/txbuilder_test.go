@@ -0,0 +1,191 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPrivateKey = "fad9c8855b740a0b7ed4c221dbad0f33a83a49cad6b3fe8d5817ac83d38b6a19"
+
+// signingNode 模拟接受签名交易的节点，记录 eth_estimateGas 的参数和广播的交易
+type signingNode struct {
+	mu        sync.Mutex
+	results   map[string]string // 方法名到 result 的映射
+	estimates []map[string]any
+	sent      []*types.Transaction
+}
+
+func newSigningNode() *signingNode {
+	return &signingNode{results: map[string]string{
+		"eth_chainId":             `"0x1"`,
+		"eth_getTransactionCount": `"0x5"`,
+		"eth_gasPrice":            `"0x3b9aca00"`,
+		"eth_estimateGas":         `"0x5208"`,
+		"eth_blobBaseFee":         `"0x64"`,
+		"eth_feeHistory":          `{"oldestBlock":"0x10","baseFeePerGas":["0x3e8","0x7d0"],"gasUsedRatio":[0.5],"reward":[["0xa","0x14","0x1e"]]}`,
+	}}
+}
+
+func (n *signingNode) requester(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var params []json.RawMessage
+	b, _ := json.Marshal(r.Params)
+	json.Unmarshal(b, &params)
+	switch r.Method {
+	case "eth_estimateGas":
+		var msg map[string]any
+		json.Unmarshal(params[0], &msg)
+		n.estimates = append(n.estimates, msg)
+	case "eth_sendRawTransaction":
+		var raw string
+		json.Unmarshal(params[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(hexutil.MustDecode(raw)); err != nil {
+			return nil, err
+		}
+		n.sent = append(n.sent, tx)
+		return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(`"` + tx.Hash().Hex() + `"`)}, nil
+	}
+	result, ok := n.results[r.Method]
+	if !ok {
+		rpcErr := json.RawMessage(`{"code":-32601,"message":"method not found"}`)
+		return &jsonrpc.RawResponse{ID: r.ID, Error: &rpcErr}, nil
+	}
+	return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(result)}, nil
+}
+
+func TestSigners(t *testing.T) {
+	signer, err := NewPrivateKeySigner("0x" + testPrivateKey)
+	assert.NoError(t, err)
+	key, _ := crypto.HexToECDSA(testPrivateKey)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	assert.Equal(t, address.Hex(), signer.Address())
+
+	_, err = NewPrivateKeySigner("invalid_private_key")
+	assert.Error(t, err)
+
+	// keystore 文件
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Address: address, PrivateKey: key}, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, keyJSON, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ksSigner, err := NewKeystoreSigner(path, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address(), ksSigner.Address())
+
+	_, err = NewKeystoreSigner(path, "wrong")
+	assert.Error(t, err)
+	_, err = NewKeystoreSigner(filepath.Join(t.TempDir(), "missing.json"), "secret")
+	assert.Error(t, err)
+}
+
+func TestTxBuilder(t *testing.T) {
+	n := newSigningNode()
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	b := c.NewTxBuilder(signer, &TxBuilderOptions{GasMultiplier: 1.5})
+	to := "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
+
+	// 默认构建 EIP-1559 交易，nonce、gas 和费用从节点获取
+	tx, err := b.Send(context.Background(), TxRequest{To: to, Value: big.NewInt(1000)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+	assert.Equal(t, uint64(5), tx.Nonce())
+	assert.Equal(t, uint64(31500), tx.Gas())
+	assert.Equal(t, "20", tx.GasTipCap().String())
+	assert.Equal(t, "4020", tx.GasFeeCap().String())
+	assert.Equal(t, "1", tx.ChainId().String())
+	assert.Equal(t, common.HexToAddress(to), *tx.To())
+	assert.Len(t, n.sent, 1)
+	assert.Equal(t, tx.Hash(), n.sent[0].Hash())
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), n.sent[0])
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address(), sender.Hex())
+	assert.Equal(t, sender, common.HexToAddress(n.estimates[0]["from"].(string)))
+	assert.Equal(t, "0xfb4", n.estimates[0]["maxFeePerGas"])
+
+	// 只指定优先费时 maxFeePerGas 随之调整
+	tx, err = b.Build(context.Background(), TxRequest{To: to, MaxPriorityFeePerGas: big.NewInt(100), Gas: 50000})
+	assert.NoError(t, err)
+	assert.Equal(t, "4100", tx.GasFeeCap().String())
+	assert.Equal(t, uint64(50000), tx.Gas())
+	assert.Len(t, n.estimates, 1)
+
+	// 指定 GasPrice 时构建传统交易，带访问列表时为 EIP-2930 交易
+	nonce := uint64(9)
+	tx, err = b.Sign(context.Background(), TxRequest{To: to, GasPrice: big.NewInt(7), Nonce: &nonce})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.LegacyTxType), tx.Type())
+	assert.Equal(t, uint64(9), tx.Nonce())
+	assert.Equal(t, "7", tx.GasPrice().String())
+	assert.True(t, tx.Protected())
+	tx, err = b.Build(context.Background(), TxRequest{To: to, Type: TxTypeAccessList, AccessList: types.AccessList{{Address: common.HexToAddress(to)}}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.AccessListTxType), tx.Type())
+	assert.Equal(t, "1000000000", tx.GasPrice().String())
+	assert.Len(t, tx.AccessList(), 1)
+
+	// 合约部署
+	tx, err = b.Build(context.Background(), TxRequest{Data: "0x6080"})
+	assert.NoError(t, err)
+	assert.Nil(t, tx.To())
+	assert.Equal(t, []byte{0x60, 0x80}, tx.Data())
+
+	for _, req := range []TxRequest{
+		{To: "0x123"},
+		{To: to, Data: "xyz"},
+		{To: to, Value: big.NewInt(-1)},
+		{To: to, Type: TxType(9)},
+		{To: to, Type: TxTypeLegacy, AccessList: types.AccessList{}},
+		{To: to, Type: TxTypeBlob},
+		{Blobs: make([]kzg4844.Blob, 1)},
+		{To: to, Type: TxTypeDynamicFee, Blobs: make([]kzg4844.Blob, 1)},
+		{To: to, MaxFeePerGas: big.NewInt(1), MaxPriorityFeePerGas: big.NewInt(2)},
+	} {
+		_, err := b.Build(context.Background(), req)
+		assert.Error(t, err, "%+v", req)
+	}
+}
+
+func TestTxBuilderBlob(t *testing.T) {
+	n := newSigningNode()
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+	signer, _ := NewPrivateKeySigner(testPrivateKey)
+	b := c.NewTxBuilder(signer, &TxBuilderOptions{ChainID: 11155111})
+
+	tx, err := b.Send(context.Background(), TxRequest{To: testToken, Blobs: make([]kzg4844.Blob, 2)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.BlobTxType), tx.Type())
+	assert.Equal(t, "11155111", tx.ChainId().String())
+	assert.Equal(t, "200", tx.BlobGasFeeCap().String())
+	assert.Len(t, tx.BlobHashes(), 2)
+
+	// 广播的交易包含 blob、承诺和证明
+	sidecar := n.sent[0].BlobTxSidecar()
+	if sidecar == nil {
+		t.Fatal("sent transaction has no sidecar")
+	}
+	assert.Len(t, sidecar.Blobs, 2)
+	assert.NoError(t, sidecar.ValidateBlobCommitmentHashes(tx.BlobHashes()))
+	assert.NoError(t, kzg4844.VerifyBlobProof(&sidecar.Blobs[0], sidecar.Commitments[0], sidecar.Proofs[0]))
+}
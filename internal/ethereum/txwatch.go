@@ -0,0 +1,415 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// 交易跟踪的默认参数
+const (
+	defaultTxPollInterval = 4 * time.Second // 查询交易状态的间隔
+	defaultTxDropTimeout  = 5 * time.Minute // 节点持续找不到交易多久后视为被丢弃
+)
+
+// TxState 被跟踪交易的状态
+type TxState int
+
+const (
+	// TxPending 交易尚未打包，或者之前的收据因链重组被撤销
+	TxPending TxState = iota
+	// TxMined 交易已打包，确认数尚未达到要求
+	TxMined
+	// TxConfirmed 交易确认数达到要求，停止跟踪
+	TxConfirmed
+	// TxDropped 节点长时间找不到交易且发送方 nonce 未被使用，交易已从交易池中丢弃，停止跟踪
+	TxDropped
+	// TxReplaced 发送方的 nonce 已被另一笔交易使用，该交易不会再被打包，停止跟踪
+	TxReplaced
+)
+
+// String 返回状态的名称
+func (s TxState) String() string {
+	switch s {
+	case TxPending:
+		return "pending"
+	case TxMined:
+		return "mined"
+	case TxConfirmed:
+		return "confirmed"
+	case TxDropped:
+		return "dropped"
+	case TxReplaced:
+		return "replaced"
+	default:
+		return fmt.Sprintf("TxState(%d)", int(s))
+	}
+}
+
+// Final 返回该状态是否为最终状态，最终状态的交易不再被跟踪
+func (s TxState) Final() bool {
+	return s == TxConfirmed || s == TxDropped || s == TxReplaced
+}
+
+// TxStatus 交易状态的变化
+type TxStatus struct {
+	Hash          string                  // 交易哈希
+	State         TxState                 // 当前状态
+	Receipt       *eth.TransactionReceipt // 交易收据，状态为 mined 或 confirmed 时非 nil
+	Confirmations uint64                  // 确认数，打包交易的区块计为1个确认
+	Reorged       bool                    // 之前的收据因链重组被撤销或移到了其他区块
+}
+
+// TxWatcherOptions 交易跟踪的配置选项，零值字段使用默认值
+type TxWatcherOptions struct {
+	Confirmations uint64        // 达到 confirmed 状态所需的确认数，为0时打包即确认
+	PollInterval  time.Duration // 查询交易状态的间隔，默认4秒
+	DropTimeout   time.Duration // 节点持续找不到交易多久后视为被丢弃，默认5分钟
+}
+
+// TxWatcher 同时跟踪多笔交易的状态，具有以下特性：
+//   - 每个轮询周期通过批量请求查询所有交易的收据
+//   - 状态或确认数变化时通过 Updates 通道发送 TxStatus
+//   - 收据消失或所在区块变化时，说明发生了链重组，重新报告 pending 或 mined 状态
+//   - 发送方 nonce 已被使用但交易没有收据时报告 replaced，节点长时间找不到交易时报告 dropped
+type TxWatcher struct {
+	c       *Client
+	ctx     context.Context
+	opts    TxWatcherOptions
+	updates chan TxStatus
+	wake    chan struct{} // 添加交易后立即触发一次轮询
+
+	mu  sync.Mutex
+	txs map[string]*watchedTx
+}
+
+// watchedTx 一笔被跟踪交易的内部状态
+type watchedTx struct {
+	hash         string
+	from         string // 发送方地址，第一次查询到交易后填充
+	nonce        uint64
+	missingSince time.Time // 节点开始找不到交易的时间，零值表示节点能找到交易
+	receipt      *eth.TransactionReceipt
+	last         *TxStatus // 最近发送的状态
+}
+
+// WatchTransactions 创建交易跟踪器
+//
+// Parameters:
+//   - ctx: context.Context 控制跟踪器的生命周期，结束后 Updates 通道被关闭
+//   - opts: *TxWatcherOptions 配置选项，为 nil 时使用默认值
+//
+// Returns:
+//   - *TxWatcher: 跟踪器，通过 Add 添加要跟踪的交易哈希
+func (c *Client) WatchTransactions(ctx context.Context, opts *TxWatcherOptions) *TxWatcher {
+	o := TxWatcherOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultTxPollInterval
+	}
+	if o.DropTimeout <= 0 {
+		o.DropTimeout = defaultTxDropTimeout
+	}
+
+	w := &TxWatcher{
+		c:       c,
+		ctx:     ctx,
+		opts:    o,
+		updates: make(chan TxStatus, subscriptionBuffer),
+		wake:    make(chan struct{}, 1),
+		txs:     make(map[string]*watchedTx),
+	}
+	go w.run()
+	return w
+}
+
+// Updates 返回交易状态变化的通道，跟踪器的上下文结束后通道被关闭
+func (w *TxWatcher) Updates() <-chan TxStatus {
+	return w.updates
+}
+
+// Add 开始跟踪一笔交易，重复添加同一哈希不产生效果
+//
+// Parameters:
+//   - hash: string 交易哈希
+//
+// Returns:
+//   - error: 交易哈希无效或跟踪器已停止时返回错误
+func (w *TxWatcher) Add(hash string) error {
+	h, err := eth.NewHash(hash)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	if w.ctx.Err() != nil {
+		return fmt.Errorf("transaction watcher is stopped: %v", w.ctx.Err())
+	}
+
+	key := strings.ToLower(h.String())
+	w.mu.Lock()
+	if _, ok := w.txs[key]; !ok {
+		w.txs[key] = &watchedTx{hash: key}
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Remove 停止跟踪一笔交易
+func (w *TxWatcher) Remove(hash string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.txs, strings.ToLower(hash))
+}
+
+// run 定期轮询所有交易的状态，直到上下文结束
+func (w *TxWatcher) run() {
+	defer close(w.updates)
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+
+		statuses, err := w.poll(w.ctx)
+		if err != nil && w.ctx.Err() == nil {
+			w.c.logWarn("failed to poll transaction status", "err", err)
+		}
+		for _, st := range statuses {
+			if !send(w.ctx, w.updates, st) {
+				return
+			}
+		}
+	}
+}
+
+// poll 查询所有被跟踪交易的状态，返回发生变化的状态
+func (w *TxWatcher) poll(ctx context.Context) ([]TxStatus, error) {
+	w.mu.Lock()
+	txs := make([]*watchedTx, 0, len(w.txs))
+	for _, tx := range w.txs {
+		txs = append(txs, tx)
+	}
+	w.mu.Unlock()
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	// 第一轮：最新区块号、收据，以及未打包交易的详情
+	batch := w.c.Batch(ctx)
+	latestResult := batch.Request("eth_blockNumber")
+	receipts := make([]*BatchResult[*eth.TransactionReceipt], len(txs))
+	lookups := make([]*BatchResult[*eth.Transaction], len(txs))
+	for i, tx := range txs {
+		receipts[i] = batch.GetTransactionReceipt(tx.hash)
+		lookups[i] = batch.GetTransactionByHash(tx.hash)
+	}
+	if err := batch.Send(); err != nil {
+		return nil, err
+	}
+	latestRaw, err := latestResult.Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block number: %v", err)
+	}
+	latest, err := decodeQuantity("eth_blockNumber")(latestRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	// 第二轮：没有收据的交易查询发送方 nonce，同时再次查询收据，避免交易恰好在两轮之间打包被误判为 replaced
+	nonceBatch := w.c.Batch(ctx)
+	nonces := make([]*BatchResult[uint64], len(txs))
+	recheck := make([]*BatchResult[*eth.TransactionReceipt], len(txs))
+	for i, tx := range txs {
+		receipt, err := receipts[i].Result()
+		if err != nil || receipt != nil {
+			continue
+		}
+		if found, err := lookups[i].Result(); err == nil {
+			tx.from, tx.nonce = found.From.String(), found.Nonce.UInt64()
+		}
+		if tx.from != "" {
+			nonces[i] = nonceBatch.GetTransactionCount(tx.from, "latest")
+			recheck[i] = nonceBatch.GetTransactionReceipt(tx.hash)
+		}
+	}
+	if nonceBatch.Len() > 0 {
+		if err := nonceBatch.Send(); err != nil {
+			return nil, err
+		}
+	}
+
+	var statuses []TxStatus
+	for i, tx := range txs {
+		receipt, err := receipts[i].Result()
+		if err != nil {
+			w.c.logWarn("failed to get receipt for transaction", "hash", tx.hash, "err", err)
+			continue
+		}
+		var nonce *uint64
+		if receipt == nil && nonces[i] != nil {
+			if r, err := recheck[i].Result(); err == nil && r != nil {
+				receipt = r
+			} else if n, err := nonces[i].Result(); err == nil {
+				nonce = &n
+			}
+		}
+		_, lookupErr := lookups[i].Result()
+		missing := errors.Is(lookupErr, ErrNotFound)
+
+		if st := w.update(tx, receipt, missing, nonce, latest.Uint64()); st != nil {
+			statuses = append(statuses, *st)
+			if st.State.Final() {
+				w.Remove(tx.hash)
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// update 根据本轮查询结果更新交易状态，状态有变化时返回新状态
+//
+// Parameters:
+//   - tx: *watchedTx 被跟踪的交易
+//   - receipt: *eth.TransactionReceipt 本轮查询到的收据，未打包时为 nil
+//   - missing: bool 节点是否找不到该交易
+//   - accountNonce: *uint64 发送方当前的 nonce，未查询时为 nil
+//   - latest: uint64 最新区块号
+func (w *TxWatcher) update(tx *watchedTx, receipt *eth.TransactionReceipt, missing bool, accountNonce *uint64, latest uint64) *TxStatus {
+	st := TxStatus{Hash: tx.hash, State: TxPending}
+
+	switch {
+	case receipt != nil:
+		st.Receipt = receipt
+		st.Reorged = tx.receipt != nil && tx.receipt.BlockHash.String() != receipt.BlockHash.String()
+		if block := receipt.BlockNumber.UInt64(); latest >= block {
+			st.Confirmations = latest - block + 1
+		} else {
+			st.Confirmations = 1
+		}
+		st.State = TxMined
+		if st.Confirmations >= max(w.opts.Confirmations, 1) {
+			st.State = TxConfirmed
+		}
+		tx.receipt = receipt
+		tx.missingSince = time.Time{}
+
+	case tx.receipt != nil:
+		// 之前查询到的收据消失，交易所在的区块被链重组撤销
+		st.Reorged = true
+		tx.receipt = nil
+
+	default:
+		if !missing {
+			tx.missingSince = time.Time{}
+		} else if tx.missingSince.IsZero() {
+			tx.missingSince = time.Now()
+		}
+
+		switch {
+		case accountNonce != nil && *accountNonce > tx.nonce:
+			st.State = TxReplaced
+		case missing && time.Since(tx.missingSince) >= w.opts.DropTimeout:
+			st.State = TxDropped
+		}
+	}
+
+	if last := tx.last; last != nil && !st.Reorged && last.State == st.State && last.Confirmations == st.Confirmations {
+		return nil
+	}
+	tx.last = &st
+	return &st
+}
+
+// WaitForReceipt 等待交易被打包并达到指定的确认数
+//
+// Parameters:
+//   - ctx: context.Context 控制等待的时间
+//   - hash: string 交易哈希
+//   - confirmations: uint64 需要的确认数，打包交易的区块计为1个确认，为0时打包即返回
+//
+// Returns:
+//   - *eth.TransactionReceipt: 达到确认数时的交易收据
+//   - error: 可能的错误：
+//   - 无效的交易哈希
+//   - 交易被丢弃或被同一 nonce 的其他交易替换
+//   - 上下文结束
+//
+// 等待期间发生链重组时继续等待交易重新打包，确认数重新计算。
+func (c *Client) WaitForReceipt(ctx context.Context, hash string, confirmations uint64) (*eth.TransactionReceipt, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := c.WatchTransactions(ctx, &TxWatcherOptions{Confirmations: confirmations})
+	if err := w.Add(hash); err != nil {
+		return nil, err
+	}
+	for st := range w.Updates() {
+		switch st.State {
+		case TxConfirmed:
+			return st.Receipt, nil
+		case TxDropped, TxReplaced:
+			return nil, fmt.Errorf("transaction %s was %s", st.Hash, st.State)
+		}
+	}
+	return nil, ctx.Err()
+}
+
+// WaitForAnyReceipt 同时等待多笔使用相同 nonce 的交易（如原交易和 SpeedUp、Cancel 发送的替换交易），
+// 返回其中被打包并达到指定确认数的交易收据
+//
+// Parameters:
+//   - ctx: context.Context 控制等待的时间
+//   - hashes: []string 交易哈希，通常为同一 nonce 的各个版本
+//   - confirmations: uint64 需要的确认数，打包交易的区块计为1个确认，为0时打包即返回
+//
+// Returns:
+//   - *eth.TransactionReceipt: 被打包的交易的收据，通过 TransactionHash 区分是哪个版本
+//   - error: 可能的错误：
+//   - 没有交易哈希或哈希无效
+//   - 所有交易都被丢弃或被其他交易替换
+//   - 上下文结束
+func (c *Client) WaitForAnyReceipt(ctx context.Context, hashes []string, confirmations uint64) (*eth.TransactionReceipt, error) {
+	if len(hashes) == 0 {
+		return nil, fmt.Errorf("no transaction to wait for")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := c.WatchTransactions(ctx, &TxWatcherOptions{Confirmations: confirmations})
+	watching := make(map[string]bool)
+	for _, hash := range hashes {
+		if err := w.Add(hash); err != nil {
+			return nil, err
+		}
+		watching[strings.ToLower(hash)] = true
+	}
+	for st := range w.Updates() {
+		switch st.State {
+		case TxConfirmed:
+			return st.Receipt, nil
+		case TxDropped, TxReplaced:
+			// 一个版本被打包后其他版本会被报告为 replaced，继续等待被打包的版本达到确认数
+			delete(watching, st.Hash)
+			if len(watching) == 0 {
+				return nil, fmt.Errorf("all %d transactions were dropped or replaced", len(hashes))
+			}
+		}
+	}
+	return nil, ctx.Err()
+}
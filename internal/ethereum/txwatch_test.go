@@ -0,0 +1,141 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	watchTxHash = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+	watchFrom   = "0x0000000000000000000000000000000000000001"
+)
+
+// txNode 模拟的节点，可以在测试中修改链头、收据、交易池和账户 nonce
+type txNode struct {
+	mu      sync.Mutex
+	latest  uint64
+	receipt string // 收据所在区块的哈希，为空表示未打包
+	block   uint64 // 收据所在的区块号
+	inPool  bool   // 节点能否找到交易
+	nonce   uint64 // 发送方当前的 nonce
+	txNonce uint64 // 被跟踪交易的 nonce
+}
+
+func (n *txNode) set(fn func(n *txNode)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	fn(n)
+}
+
+func (n *txNode) requester(ctx context.Context, r *jsonrpc.Request) (*jsonrpc.RawResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	result := "null"
+	switch r.Method {
+	case "eth_blockNumber":
+		result = fmt.Sprintf(`"0x%x"`, n.latest)
+	case "eth_getTransactionReceipt":
+		if n.receipt != "" {
+			result = fmt.Sprintf(`{"transactionHash":%q,"blockHash":%q,"blockNumber":"0x%x","status":"0x1"}`, watchTxHash, n.receipt, n.block)
+		}
+	case "eth_getTransactionByHash":
+		if n.inPool || n.receipt != "" {
+			result = fmt.Sprintf(`{"hash":%q,"from":%q,"nonce":"0x%x"}`, watchTxHash, watchFrom, n.txNonce)
+		}
+	case "eth_getTransactionCount":
+		result = fmt.Sprintf(`"0x%x"`, n.nonce)
+	default:
+		return nil, fmt.Errorf("unexpected request %s", r.Method)
+	}
+	return &jsonrpc.RawResponse{ID: r.ID, Result: json.RawMessage(result)}, nil
+}
+
+// nextStatus 从跟踪器读取下一个状态
+func nextStatus(t *testing.T, w *TxWatcher) TxStatus {
+	select {
+	case st := <-w.Updates():
+		return st
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for transaction status")
+	}
+	return TxStatus{}
+}
+
+func TestTxWatcherReorg(t *testing.T) {
+	n := &txNode{latest: 10, inPool: true, nonce: 5, txNonce: 5}
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := c.WatchTransactions(ctx, &TxWatcherOptions{Confirmations: 3, PollInterval: 10 * time.Millisecond})
+	assert.NoError(t, w.Add(watchTxHash))
+	assert.Error(t, w.Add("0x123"))
+
+	st := nextStatus(t, w)
+	assert.Equal(t, TxPending, st.State)
+
+	n.set(func(n *txNode) { n.receipt, n.block, n.latest = "0x"+fmt.Sprintf("%064x", 0xa), 10, 10 })
+	st = nextStatus(t, w)
+	assert.Equal(t, TxMined, st.State)
+	assert.Equal(t, uint64(1), st.Confirmations)
+
+	// 区块10被链重组撤销，交易回到交易池
+	n.set(func(n *txNode) { n.receipt, n.latest = "", 11 })
+	st = nextStatus(t, w)
+	assert.Equal(t, TxPending, st.State)
+	assert.True(t, st.Reorged)
+
+	// 交易重新打包到新的区块11
+	n.set(func(n *txNode) { n.receipt, n.block, n.nonce = "0x"+fmt.Sprintf("%064x", 0xb), 11, 6 })
+	st = nextStatus(t, w)
+	assert.Equal(t, TxMined, st.State)
+	assert.Equal(t, uint64(1), st.Confirmations)
+
+	n.set(func(n *txNode) { n.latest = 13 })
+	st = nextStatus(t, w)
+	assert.Equal(t, TxConfirmed, st.State)
+	assert.Equal(t, uint64(3), st.Confirmations)
+	assert.Equal(t, "0x"+fmt.Sprintf("%064x", 0xb), st.Receipt.BlockHash.String())
+}
+
+func TestTxWatcherDropped(t *testing.T) {
+	n := &txNode{latest: 10, inPool: true, nonce: 5, txNonce: 5}
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := c.WatchTransactions(ctx, &TxWatcherOptions{PollInterval: 10 * time.Millisecond, DropTimeout: 50 * time.Millisecond})
+	assert.NoError(t, w.Add(watchTxHash))
+	assert.Equal(t, TxPending, nextStatus(t, w).State)
+
+	n.set(func(n *txNode) { n.inPool = false })
+	assert.Equal(t, TxDropped, nextStatus(t, w).State)
+}
+
+func TestWaitForReceipt(t *testing.T) {
+	n := &txNode{latest: 12, receipt: "0x" + fmt.Sprintf("%064x", 0xa), block: 10}
+	c := newTestClient(t, map[string]requesterFunc{"ws://node": n.requester}, Endpoint{URL: "ws://node"})
+
+	receipt, err := c.WaitForReceipt(context.Background(), watchTxHash, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), receipt.BlockNumber.UInt64())
+
+	// 发送方 nonce 已被其他交易使用
+	n.set(func(n *txNode) { n.receipt, n.inPool, n.nonce, n.txNonce = "", true, 8, 7 })
+	_, err = c.WaitForReceipt(context.Background(), watchTxHash, 1)
+	assert.ErrorContains(t, err, "was replaced")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	n.set(func(n *txNode) { n.nonce = 7 })
+	_, err = c.WaitForReceipt(ctx, watchTxHash, 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -2,35 +2,54 @@
 package ethereum
 
 import (
-	"sync"
+	"context"
+	"log/slog"
+	"math/big"
 	"time"
-
-	"github.com/justinwongcn/go-ethlibs/node"
 )
 
+// healthCheckTimeout 单个连接健康检查的超时时间
+const healthCheckTimeout = 10 * time.Second
+
 // Client 封装以太坊客户端，提供以下功能：
 //   - 与以太坊节点的基础交互
 //   - 连接池管理
+//   - 多端点故障转移
 //   - 自动的健康检查
 //   - 并发请求处理
 type Client struct {
-	nodeClient node.Client // 底层节点客户端
-	nodeURL    string      // 节点URL，用于创建新连接
+	endpoints []*endpoint        // 按优先级排列的节点端点，每个端点拥有独立的连接池
+	rr        uint64             // 轮询计数，用于在同优先级端点间分担请求
+	ctx       context.Context    // 客户端生命周期上下文，所有连接派生自该上下文
+	cancel    context.CancelFunc // 关闭客户端及其所有连接
 	// 连接池配置
-	maxConns     int           // 最大并发连接数
+	maxConns     int           // 每个端点的最大并发连接数
 	idleTimeout  time.Duration // 空闲连接的超时时间
 	healthCheck  bool          // 是否启用连接健康检查
-	connPool     *sync.Pool    // 连接池，用于复用连接
-	connCount    int32         // 当前活跃连接数
-	maxIdleConns int           // 最大空闲连接数，超过此数量的空闲连接将被关闭
+	maxIdleConns int           // 每个端点的最大空闲连接数，超过此数量的空闲连接将被关闭
+	// 故障转移配置
+	healthCheckInterval time.Duration // 健康检查和不健康端点重新探测的间隔
+	maxFailures         int           // 连续失败多少次后将端点标记为不健康
+	chainID             uint64        // 期望的链ID，为0时不校验
+	batchSize           int           // 单个 JSON-RPC 批量请求包含的最大调用数
+	retry               RetryPolicy   // 失败请求的重试策略
+	rateLimit           RateLimit     // 端点未单独配置时使用的限流配置
+	logger              *slog.Logger  // 后台任务的日志，为 nil 时不记录
 }
 
 // ClientOptions 定义客户端的配置选项，用于在创建客户端时自定义连接池行为
 type ClientOptions struct {
-	MaxConns     int           // 最大并发连接数，控制资源使用
-	IdleTimeout  time.Duration // 空闲连接的超时时间，超时后连接将被清理
-	HealthCheck  bool          // 是否启用连接健康检查，启用后将定期检查连接状态
-	MaxIdleConns int           // 最大空闲连接数，用于限制连接池大小
+	MaxConns            int           // 每个端点的最大并发连接数，控制资源使用
+	IdleTimeout         time.Duration // 空闲连接的超时时间，超时后连接将被清理
+	HealthCheck         bool          // 是否启用连接健康检查，启用后将定期检查连接状态
+	MaxIdleConns        int           // 每个端点的最大空闲连接数，用于限制连接池大小
+	HealthCheckInterval time.Duration // 健康检查和不健康端点重新探测的间隔
+	MaxFailures         int           // 连续失败多少次后将端点标记为不健康
+	ChainID             uint64        // 期望的链ID，非0时连接端点后校验，不匹配的端点不参与路由
+	BatchSize           int           // 单个 JSON-RPC 批量请求包含的最大调用数，超出时拆分为多个批量请求
+	Retry               RetryPolicy   // 限流、超时和连接错误的重试策略，MaxAttempts 为0时不重试
+	RateLimit           RateLimit     // 每个端点的客户端限流配置，RequestsPerSecond 为0时不限流
+	Logger              *slog.Logger  // 记录后台任务（订阅重连、链头和交易轮询）中无法返回给调用方的错误，为 nil 时不记录
 }
 
 // DefaultClientOptions 返回默认的客户端配置选项
@@ -40,5 +59,26 @@ func DefaultClientOptions() *ClientOptions {
 		IdleTimeout:  time.Minute, // 默认空闲超时时间
 		HealthCheck:  true,        // 默认启用健康检查
 		MaxIdleConns: 10,          // 默认最大空闲连接数
+
+		HealthCheckInterval: 30 * time.Second, // 默认健康检查间隔
+		MaxFailures:         3,                // 默认连续失败3次后标记端点不健康
+		BatchSize:           defaultBatchSize, // 默认每个批量请求最多100个调用
+		Retry: RetryPolicy{ // 默认最多尝试3次，重试间隔从200毫秒开始翻倍，不超过5秒
+			MaxAttempts:    defaultRetryMaxAttempts,
+			InitialBackoff: defaultRetryInitialBackoff,
+			MaxBackoff:     defaultRetryMaxBackoff,
+		},
 	}
 }
+
+// CallMsg 描述一次合约调用或 gas 估算的参数，金额类字段使用 *big.Int 以避免溢出
+type CallMsg struct {
+	From                 string   // 可选，交易发送方地址
+	To                   string   // 合约调用时必需；估算合约部署的 gas 时为空
+	Gas                  uint64   // 可选，gas 限制，为0时由节点决定
+	GasPrice             *big.Int // 可选，传统交易的 gas 价格（单位：wei）
+	MaxFeePerGas         *big.Int // 可选，EIP-1559 每单位 gas 的最高费用（单位：wei）
+	MaxPriorityFeePerGas *big.Int // 可选，EIP-1559 每单位 gas 的最高小费（单位：wei）
+	Value                *big.Int // 可选，随交易发送的以太币数量（单位：wei）
+	Data                 string   // 可选，方法签名和编码参数（十六进制格式）
+}
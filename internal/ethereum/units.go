@@ -0,0 +1,151 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 以太币单位的小数位数
+const (
+	WeiDecimals   = 0  // wei，最小单位
+	GweiDecimals  = 9  // 1 gwei = 10^9 wei，常用于表示 gas 价格
+	EtherDecimals = 18 // 1 ether = 10^18 wei
+)
+
+// ParseUnits 将十进制字符串按指定小数位数转换为最小单位的整数
+//
+// Parameters:
+//   - value: string 十进制数值，如 "1.5"、"-0.01"、"100"
+//   - decimals: int 小数位数，如 ether 为18、USDT 为6
+//
+// Returns:
+//   - *big.Int: 最小单位的整数值
+//   - error: 可能的错误：
+//   - 数值格式无效
+//   - 小数位数超过 decimals（会造成精度丢失）
+func ParseUnits(value string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid decimals: %d", decimals)
+	}
+
+	s := strings.TrimSpace(value)
+	neg := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return nil, fmt.Errorf("invalid decimal value: %q", value)
+	}
+	if len(fracPart) > decimals {
+		// 超出精度的部分必须全为0，否则会丢失精度
+		if strings.Trim(fracPart[decimals:], "0") != "" {
+			return nil, fmt.Errorf("value %q has more than %d decimal places", value, decimals)
+		}
+		fracPart = fracPart[:decimals]
+	}
+
+	digits := intPart + fracPart + strings.Repeat("0", decimals-len(fracPart))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("invalid decimal value: %q", value)
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal value: %q", value)
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// FormatUnits 将最小单位的整数按指定小数位数格式化为十进制字符串，不丢失精度
+//
+// Parameters:
+//   - value: *big.Int 最小单位的整数值，nil 视为0
+//   - decimals: int 小数位数
+//
+// Returns:
+//   - string: 十进制字符串，去除末尾多余的0，如 "1.5"、"0.000000001"、"100"
+func FormatUnits(value *big.Int, decimals int) string {
+	if value == nil {
+		return "0"
+	}
+	if decimals <= 0 {
+		return value.String()
+	}
+
+	abs := new(big.Int).Abs(value).String()
+	if len(abs) <= decimals {
+		abs = strings.Repeat("0", decimals-len(abs)+1) + abs
+	}
+	intPart := abs[:len(abs)-decimals]
+	fracPart := strings.TrimRight(abs[len(abs)-decimals:], "0")
+
+	s := intPart
+	if fracPart != "" {
+		s += "." + fracPart
+	}
+	if value.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// ParseEther 将以 ether 为单位的十进制字符串转换为 wei
+//
+// Parameters:
+//   - value: string 以 ether 为单位的数值，如 "1.5"
+//
+// Returns:
+//   - *big.Int: 以 wei 为单位的数值
+//   - error: 数值格式无效或超过18位小数时返回错误
+func ParseEther(value string) (*big.Int, error) {
+	return ParseUnits(value, EtherDecimals)
+}
+
+// ParseGwei 将以 gwei 为单位的十进制字符串转换为 wei
+//
+// Parameters:
+//   - value: string 以 gwei 为单位的数值，如 "1.5"
+//
+// Returns:
+//   - *big.Int: 以 wei 为单位的数值
+//   - error: 数值格式无效或超过9位小数时返回错误
+func ParseGwei(value string) (*big.Int, error) {
+	return ParseUnits(value, GweiDecimals)
+}
+
+// FormatEther 将 wei 格式化为以 ether 为单位的十进制字符串
+func FormatEther(wei *big.Int) string {
+	return FormatUnits(wei, EtherDecimals)
+}
+
+// FormatGwei 将 wei 格式化为以 gwei 为单位的十进制字符串
+func FormatGwei(wei *big.Int) string {
+	return FormatUnits(wei, GweiDecimals)
+}
+
+// GweiToWei 将整数 gwei 转换为 wei
+func GweiToWei(gwei uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(gwei), big.NewInt(1e9))
+}
+
+// EtherToWei 将整数 ether 转换为 wei
+func EtherToWei(ether uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(ether), big.NewInt(1e18))
+}
+
+// toUint64 将 big.Int 转换为 uint64，超出范围时返回错误而不是截断
+func toUint64(value *big.Int, what string) (uint64, error) {
+	if value.Sign() < 0 || !value.IsUint64() {
+		return 0, fmt.Errorf("%s %s overflows uint64, use the big.Int variant", what, value.String())
+	}
+	return value.Uint64(), nil
+}
@@ -0,0 +1,45 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUnits(t *testing.T) {
+	wei, err := ParseEther("1.5")
+	assert.NoError(t, err)
+	assert.Equal(t, "1500000000000000000", wei.String())
+
+	wei, err = ParseGwei("0.000000001")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), wei)
+
+	// 超过 uint64 范围的余额不应溢出
+	wei, err = ParseEther("100000")
+	assert.NoError(t, err)
+	assert.Equal(t, "100000000000000000000000", wei.String())
+
+	wei, err = ParseUnits("-2.50", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(-2500000), wei)
+
+	_, err = ParseGwei("1.0000000001")
+	assert.Error(t, err, "超出精度的小数应返回错误")
+	_, err = ParseEther("1e18")
+	assert.Error(t, err)
+	_, err = ParseEther("")
+	assert.Error(t, err)
+}
+
+func TestFormatUnits(t *testing.T) {
+	wei, _ := new(big.Int).SetString("123456789000000000000000", 10)
+	assert.Equal(t, "123456.789", FormatEther(wei))
+	assert.Equal(t, "1", FormatGwei(GweiToWei(1)))
+	assert.Equal(t, "0.000000001", FormatEther(big.NewInt(1e9)))
+	assert.Equal(t, "-0.5", FormatUnits(big.NewInt(-5), 1))
+	assert.Equal(t, "42", FormatUnits(big.NewInt(42), 0))
+	assert.Equal(t, "0", FormatEther(nil))
+	assert.Equal(t, "2", FormatEther(EtherToWei(2)))
+}
@@ -0,0 +1,262 @@
+package indexer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// fileStoreName 数据文件在存储目录中的文件名
+const fileStoreName = "blocks.log"
+
+// FileStore 嵌入式的文件存储后端，具有以下特性：
+//   - 所有区块以 JSON 行的形式追加写入同一个数据文件，每次写入后同步到磁盘
+//   - 打开时扫描数据文件，在内存中重建区块号和交易哈希到文件位置的索引
+//   - 进程崩溃导致的不完整尾部记录在下次打开时被截断，检查点回到最后一条完整记录
+//   - 覆盖写入的区块追加新记录，旧记录保留在文件中但不再被索引
+//   - 回退（Rewind）追加一条回退记录，重建索引时按记录顺序重放
+type FileStore struct {
+	mu         sync.RWMutex
+	file       *os.File
+	size       int64                 // 数据文件中完整记录的总长度，新记录从此处追加
+	blocks     map[uint64]fileRecord // 区块号到最新记录的位置
+	txs        map[string]txLocation
+	checkpoint *uint64
+}
+
+// fileRecord 一条区块记录在数据文件中的位置
+type fileRecord struct {
+	offset int64
+	length int64
+	txs    []string // 区块中的交易哈希，用于覆盖或回退时删除交易索引
+}
+
+// fileEntry 数据文件中的一行，为区块记录或回退记录之一
+type fileEntry struct {
+	*BlockData
+	Rewind *uint64 `json:"rewind,omitempty"` // 回退到的区块号
+}
+
+// OpenFileStore 打开或创建目录中的文件存储
+//
+// Parameters:
+//   - dir: string 存储目录，不存在时自动创建
+//
+// Returns:
+//   - *FileStore: 文件存储实例，使用完毕后需调用 Close
+//   - error: 可能的错误：
+//   - 无法创建目录或打开数据文件
+//   - 数据文件中间存在损坏的记录
+func OpenFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %v", err)
+	}
+	file, err := os.OpenFile(filepath.Join(dir, fileStoreName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store file: %v", err)
+	}
+
+	s := &FileStore{
+		file:   file,
+		blocks: make(map[uint64]fileRecord),
+		txs:    make(map[string]txLocation),
+	}
+	if err := s.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load 扫描数据文件重建索引，并截断末尾不完整的记录
+func (s *FileStore) load() error {
+	r := bufio.NewReader(s.file)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// 没有换行符的尾部记录是写入中途崩溃留下的
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read store file: %v", err)
+		}
+
+		var entry fileEntry
+		err = json.Unmarshal(line, &entry)
+		valid := err == nil && (entry.Rewind != nil || entry.BlockData != nil && entry.Block != nil && entry.Block.Number != nil)
+		if !valid {
+			// 只有最后一条记录可能因崩溃而损坏
+			if _, peekErr := r.Peek(1); peekErr == nil {
+				return fmt.Errorf("corrupted store record at offset %d", s.size)
+			}
+			break
+		}
+		if entry.Rewind != nil {
+			s.rewind(*entry.Rewind)
+		} else {
+			s.index(entry.BlockData, s.size, int64(len(line)))
+		}
+		s.size += int64(len(line))
+	}
+
+	if err := s.file.Truncate(s.size); err != nil {
+		return fmt.Errorf("failed to truncate store file: %v", err)
+	}
+	return nil
+}
+
+// index 将一条区块记录加入内存索引，调用方需持有写锁
+func (s *FileStore) index(data *BlockData, offset, length int64) {
+	number := data.Number()
+	s.remove(number)
+
+	rec := fileRecord{offset: offset, length: length, txs: make([]string, len(data.Block.Transactions))}
+	for i, tx := range data.Block.Transactions {
+		rec.txs[i] = normalizeHash(tx.Hash.String())
+		s.txs[rec.txs[i]] = txLocation{number: number, index: i}
+	}
+	s.blocks[number] = rec
+	s.checkpoint = &number
+}
+
+// remove 从内存索引中删除一个区块及其交易，调用方需持有写锁
+func (s *FileStore) remove(number uint64) {
+	old, ok := s.blocks[number]
+	if !ok {
+		return
+	}
+	for _, hash := range old.txs {
+		if loc, ok := s.txs[hash]; ok && loc.number == number {
+			delete(s.txs, hash)
+		}
+	}
+	delete(s.blocks, number)
+}
+
+// rewind 从内存索引中删除区块号大于 number 的区块，调用方需持有写锁
+func (s *FileStore) rewind(number uint64) {
+	for n := range s.blocks {
+		if n > number {
+			s.remove(n)
+		}
+	}
+	s.checkpoint = &number
+}
+
+// read 读取并解码一条记录
+func (s *FileStore) read(rec fileRecord) (*BlockData, error) {
+	buf := make([]byte, rec.length)
+	if _, err := s.file.ReadAt(buf, rec.offset); err != nil {
+		return nil, fmt.Errorf("failed to read store record: %v", err)
+	}
+	var entry fileEntry
+	if err := json.Unmarshal(buf, &entry); err != nil || entry.BlockData == nil {
+		return nil, fmt.Errorf("failed to decode store record at offset %d: %v", rec.offset, err)
+	}
+	return entry.BlockData, nil
+}
+
+// appendEntry 追加一条记录并同步到磁盘，返回记录的位置，调用方需持有写锁
+func (s *FileStore) appendEntry(entry fileEntry) (int64, int64, error) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to encode store record: %v", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := s.file.WriteAt(line, s.size); err != nil {
+		return 0, 0, fmt.Errorf("failed to write store record: %v", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return 0, 0, fmt.Errorf("failed to sync store file: %v", err)
+	}
+	offset := s.size
+	s.size += int64(len(line))
+	return offset, int64(len(line)), nil
+}
+
+// PutBlock 追加写入一个区块及其交易和收据，并将检查点更新为该区块号
+func (s *FileStore) PutBlock(ctx context.Context, data *BlockData) error {
+	if data == nil || data.Block == nil || data.Block.Number == nil {
+		return fmt.Errorf("block data must include a block number")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset, length, err := s.appendEntry(fileEntry{BlockData: data})
+	if err != nil {
+		return fmt.Errorf("failed to store block %d: %v", data.Number(), err)
+	}
+	s.index(data, offset, length)
+	return nil
+}
+
+// Rewind 追加一条回退记录，删除区块号大于 number 的所有区块，并将检查点回退到 number，number 不能大于检查点
+func (s *FileStore) Rewind(ctx context.Context, number uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := checkRewind(s.checkpoint, number); err != nil {
+		return err
+	}
+
+	if _, _, err := s.appendEntry(fileEntry{Rewind: &number}); err != nil {
+		return fmt.Errorf("failed to rewind to block %d: %v", number, err)
+	}
+	s.rewind(number)
+	return nil
+}
+
+// Block 按区块号读取区块
+func (s *FileStore) Block(ctx context.Context, number uint64) (*BlockData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.blocks[number]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.read(rec)
+}
+
+// Transaction 按交易哈希读取交易及其收据
+func (s *FileStore) Transaction(ctx context.Context, hash string) (*eth.Transaction, *eth.TransactionReceipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	loc, ok := s.txs[normalizeHash(hash)]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	data, err := s.read(s.blocks[loc.number])
+	if err != nil {
+		return nil, nil, err
+	}
+	return lookupTx(data, loc.index)
+}
+
+// Checkpoint 返回最后写入的区块号
+func (s *FileStore) Checkpoint(ctx context.Context) (uint64, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.checkpoint == nil {
+		return 0, false, nil
+	}
+	return *s.checkpoint, true, nil
+}
+
+// Close 关闭数据文件
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
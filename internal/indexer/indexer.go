@@ -0,0 +1,255 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"golang.org/x/sync/errgroup"
+)
+
+// 索引器的默认参数
+const (
+	defaultPollInterval = 12 * time.Second // 追上链头后轮询新区块的间隔，约为一个出块周期
+	defaultConcurrency  = 8                // 同时查询的交易收据数
+	defaultReorgDepth   = 128              // 发生链重组时最多回溯的区块数
+)
+
+// Source 索引器所依赖的以太坊客户端接口，由 *ethereum.Client 实现
+type Source interface {
+	GetLatestBlockNumber(ctx context.Context) (uint64, error)
+	GetBlockByNumber(ctx context.Context, numberOrTag string, fullTx bool) (*eth.Block, error)
+	GetTransactionReceipt(ctx context.Context, txHash string) (*eth.TransactionReceipt, error)
+}
+
+// Options 索引器的配置选项，零值字段使用默认值
+type Options struct {
+	StartBlock    uint64        // 存储中没有检查点时开始索引的区块号
+	Confirmations uint64        // 只索引至少有这么多个确认的区块，即链头减去该值
+	PollInterval  time.Duration // 追上链头后轮询新区块的间隔，默认12秒
+	Concurrency   int           // 同时查询的交易收据数，默认8
+	MaxReorgDepth uint64        // 发生链重组时最多回溯的区块数，默认128
+	Logger        *slog.Logger  // 记录同步失败和链重组，为 nil 时不记录
+}
+
+// Indexer 按区块号顺序将区块、交易和收据写入存储
+type Indexer struct {
+	source Source
+	store  Store
+	opts   Options
+}
+
+// New 创建索引器
+//
+// Parameters:
+//   - source: Source 以太坊客户端
+//   - store: Store 存储后端，索引器不负责关闭
+//   - opts: *Options 配置选项，为 nil 时使用默认值
+//
+// Returns:
+//   - *Indexer: 索引器实例
+func New(source Source, store Store, opts *Options) *Indexer {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultPollInterval
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultConcurrency
+	}
+	if o.MaxReorgDepth == 0 {
+		o.MaxReorgDepth = defaultReorgDepth
+	}
+	return &Indexer{source: source, store: store, opts: o}
+}
+
+// Run 持续索引新区块，直到上下文取消
+//
+// 启动时从存储的检查点之后继续；追上链头后每隔 PollInterval 检查一次新区块。
+// 单次同步失败时通过 Options.Logger 记录并在下一个轮询周期重试。
+//
+// Parameters:
+//   - ctx: context.Context 控制索引器的生命周期
+//
+// Returns:
+//   - error: 上下文取消时返回 ctx.Err()
+func (ix *Indexer) Run(ctx context.Context) error {
+	for {
+		if _, err := ix.Sync(ctx); err != nil && ctx.Err() == nil {
+			ix.logWarn("indexer sync failed", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(ix.opts.PollInterval):
+		}
+	}
+}
+
+// logWarn 记录无法返回给调用方的错误，未配置 Options.Logger 时不记录
+func (ix *Indexer) logWarn(msg string, args ...any) {
+	if ix.opts.Logger != nil {
+		ix.opts.Logger.Warn(msg, args...)
+	}
+}
+
+// Sync 索引从检查点到当前链头（减去确认数）之间的所有区块
+//
+// Parameters:
+//   - ctx: context.Context 用于控制请求的上下文
+//
+// Returns:
+//   - uint64: 本次索引的区块数
+//   - error: 可能的错误：
+//   - 读取检查点或写入存储失败
+//   - 节点连接错误
+//
+// 每个区块写入后立即更新检查点，出错时已写入的区块不会丢失。
+// 新区块的 ParentHash 与已索引的上一个区块不一致时，说明发生了链重组：
+// 回溯到与节点一致的共同祖先，删除之后的区块后重新索引。
+func (ix *Indexer) Sync(ctx context.Context) (uint64, error) {
+	next, err := ix.nextBlock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	parentHash, err := ix.storedHash(ctx, next)
+	if err != nil {
+		return 0, err
+	}
+
+	latest, err := ix.source.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block number: %v", err)
+	}
+	if latest < ix.opts.Confirmations {
+		return 0, nil
+	}
+	target := latest - ix.opts.Confirmations
+
+	var indexed uint64
+	for number := next; number <= target; number++ {
+		data, err := ix.fetchBlock(ctx, number)
+		if err != nil {
+			return indexed, err
+		}
+		if parentHash != "" && data.Block.ParentHash.String() != parentHash {
+			ancestor, err := ix.rollback(ctx, number-1)
+			if err != nil {
+				return indexed, err
+			}
+			if parentHash, err = ix.storedHash(ctx, ancestor+1); err != nil {
+				return indexed, err
+			}
+			number = ancestor
+			continue
+		}
+		if err := ix.store.PutBlock(ctx, data); err != nil {
+			return indexed, fmt.Errorf("failed to store block %d: %v", number, err)
+		}
+		indexed++
+		parentHash = data.Block.Hash.String()
+	}
+	return indexed, nil
+}
+
+// storedHash 返回已索引的区块 number-1 的哈希，用于校验区块 number 的 ParentHash；未索引时返回空字符串
+func (ix *Indexer) storedHash(ctx context.Context, number uint64) (string, error) {
+	if number == 0 {
+		return "", nil
+	}
+	data, err := ix.store.Block(ctx, number-1)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read block %d: %v", number-1, err)
+	}
+	return data.Block.Hash.String(), nil
+}
+
+// rollback 从区块 from 开始向前查找与节点一致的共同祖先，删除之后的所有区块并返回祖先的区块号
+func (ix *Indexer) rollback(ctx context.Context, from uint64) (uint64, error) {
+	ancestor := from
+	for {
+		stored, err := ix.store.Block(ctx, ancestor)
+		if errors.Is(err, ErrNotFound) {
+			// 已回溯到索引起点之前，之后的区块全部重新索引
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read block %d: %v", ancestor, err)
+		}
+
+		canonical, err := ix.source.GetBlockByNumber(ctx, eth.QuantityFromUInt64(ancestor).String(), false)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get block %d: %v", ancestor, err)
+		}
+		if canonical != nil && canonical.Hash != nil && canonical.Hash.String() == stored.Block.Hash.String() {
+			break
+		}
+		if ancestor == 0 || from-ancestor+1 >= ix.opts.MaxReorgDepth {
+			return 0, fmt.Errorf("no common ancestor within %d blocks of block %d", ix.opts.MaxReorgDepth, from)
+		}
+		ancestor--
+	}
+
+	ix.logWarn("chain reorganization detected, reverting indexed blocks", "from", ancestor+1, "to", from)
+	if err := ix.store.Rewind(ctx, ancestor); err != nil {
+		return 0, fmt.Errorf("failed to revert blocks after %d: %v", ancestor, err)
+	}
+	return ancestor, nil
+}
+
+// nextBlock 返回下一个需要索引的区块号
+func (ix *Indexer) nextBlock(ctx context.Context) (uint64, error) {
+	checkpoint, ok, err := ix.store.Checkpoint(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	if !ok {
+		return ix.opts.StartBlock, nil
+	}
+	return checkpoint + 1, nil
+}
+
+// fetchBlock 获取区块（含完整交易）及其所有交易收据
+func (ix *Indexer) fetchBlock(ctx context.Context, number uint64) (*BlockData, error) {
+	block, err := ix.source.GetBlockByNumber(ctx, eth.QuantityFromUInt64(number).String(), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %v", number, err)
+	}
+	if block == nil || block.Hash == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+
+	if len(block.Transactions) > 0 && !block.Transactions[0].Populated {
+		return nil, fmt.Errorf("block %d does not include full transactions", number)
+	}
+
+	receipts := make([]*eth.TransactionReceipt, len(block.Transactions))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(ix.opts.Concurrency)
+	for i, tx := range block.Transactions {
+		g.Go(func() error {
+			receipt, err := ix.source.GetTransactionReceipt(gctx, tx.Hash.String())
+			if err != nil {
+				return fmt.Errorf("failed to get receipt for transaction %s: %v", tx.Hash.String(), err)
+			}
+			if receipt == nil {
+				return fmt.Errorf("receipt for transaction %s not found", tx.Hash.String())
+			}
+			receipts[i] = receipt
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return &BlockData{Block: block, Receipts: receipts}, nil
+}
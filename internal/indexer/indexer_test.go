@@ -0,0 +1,190 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSource 模拟的以太坊客户端，每个区块包含 txs 笔交易
+type fakeSource struct {
+	t      *testing.T
+	txs    int
+	mu     sync.Mutex
+	latest uint64
+	fail   map[string]bool // 查询收据时返回错误的交易哈希
+	blocks []uint64        // 按请求顺序记录查询过的区块号
+	// 区块号不小于 forkFrom 的区块位于分叉 fork 上，forkFrom 为0表示没有分叉
+	forkFrom uint64
+	fork     int
+}
+
+func (f *fakeSource) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.latest, nil
+}
+
+func (f *fakeSource) GetBlockByNumber(ctx context.Context, numberOrTag string, fullTx bool) (*eth.Block, error) {
+	number := eth.MustBlockNumberOrTag(numberOrTag)
+	q, _ := number.Quantity()
+
+	n := q.UInt64()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blocks = append(f.blocks, n)
+	switch {
+	case f.forkFrom == 0 || n < f.forkFrom:
+		return testBlock(f.t, n, f.txs), nil
+	case n == f.forkFrom:
+		return testForkBlock(f.t, n, f.txs, f.fork, 0), nil
+	default:
+		return testForkBlock(f.t, n, f.txs, f.fork, f.fork), nil
+	}
+}
+
+func (f *fakeSource) GetTransactionReceipt(ctx context.Context, txHash string) (*eth.TransactionReceipt, error) {
+	if f.fail[txHash] {
+		return nil, fmt.Errorf("connection reset")
+	}
+	var number uint64
+	fmt.Sscanf(txHash[2:64], "%x", &number)
+	return testReceipt(f.t, txHash, number), nil
+}
+
+func TestIndexerSyncAndResume(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	source := &fakeSource{t: t, txs: 3, latest: 10}
+
+	store, err := OpenFileStore(dir)
+	assert.NoError(t, err)
+	ix := New(source, store, &Options{StartBlock: 5, Confirmations: 2})
+
+	indexed, err := ix.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), indexed, "只索引有足够确认数的区块")
+	assert.Equal(t, []uint64{5, 6, 7, 8}, source.blocks)
+
+	tx, receipt, err := store.Transaction(ctx, testTxHash(7, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), tx.BlockNumber.UInt64())
+	assert.Equal(t, testTxHash(7, 2), receipt.TransactionHash.String())
+	assert.NoError(t, store.Close())
+
+	// 重启后从检查点继续，StartBlock 不再生效
+	source.blocks = nil
+	source.latest = 12
+	store, err = OpenFileStore(dir)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	indexed, err = New(source, store, &Options{StartBlock: 0, Confirmations: 2}).Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), indexed)
+	assert.Equal(t, []uint64{9, 10}, source.blocks)
+
+	checkpoint, _, err := store.Checkpoint(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), checkpoint)
+}
+
+func TestIndexerSyncError(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	source := &fakeSource{t: t, txs: 2, latest: 3, fail: map[string]bool{testTxHash(2, 1): true}}
+
+	indexed, err := New(source, store, nil).Sync(ctx)
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Equal(t, uint64(2), indexed)
+
+	// 失败区块之前的区块已写入，检查点停在最后一个完整区块
+	checkpoint, ok, err := store.Checkpoint(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), checkpoint)
+	_, err = store.Block(ctx, 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	source.fail = nil
+	indexed, err = New(source, store, nil).Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), indexed)
+}
+
+func TestIndexerRun(t *testing.T) {
+	store := NewMemoryStore()
+	source := &fakeSource{t: t, latest: 2}
+	ix := New(source, store, &Options{PollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ix.Run(ctx) }()
+
+	source.mu.Lock()
+	source.latest = 4
+	source.mu.Unlock()
+	assert.Eventually(t, func() bool {
+		checkpoint, _, _ := store.Checkpoint(context.Background())
+		return checkpoint == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestIndexerReorg(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := OpenFileStore(dir)
+	assert.NoError(t, err)
+	source := &fakeSource{t: t, txs: 1, latest: 10}
+	var logs bytes.Buffer
+	ix := New(source, store, &Options{StartBlock: 1, Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+
+	indexed, err := ix.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), indexed)
+
+	// 从区块8开始的分叉替换了已索引的区块8到10
+	source.forkFrom, source.fork, source.latest = 8, 1, 11
+	indexed, err = ix.Sync(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), indexed, "回退后重新索引区块8到11")
+	assert.Contains(t, logs.String(), `msg="chain reorganization detected, reverting indexed blocks" from=8 to=10`)
+
+	for n := uint64(7); n <= 11; n++ {
+		data, err := store.Block(ctx, n)
+		assert.NoError(t, err)
+		fork := 1
+		if n < 8 {
+			fork = 0
+		}
+		assert.Equal(t, testBlockHash(n, fork), data.Block.Hash.String())
+	}
+	assert.NoError(t, store.Close())
+
+	// 重新打开后回退记录被重放，区块保持为分叉后的版本
+	store, err = OpenFileStore(dir)
+	assert.NoError(t, err)
+	defer store.Close()
+	data, err := store.Block(ctx, 9)
+	assert.NoError(t, err)
+	assert.Equal(t, testBlockHash(9, 1), data.Block.Hash.String())
+	checkpoint, _, err := store.Checkpoint(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(11), checkpoint)
+
+	// 重组深度超过 MaxReorgDepth 时返回错误
+	source.forkFrom, source.fork, source.latest = 2, 2, 12
+	_, err = New(source, store, &Options{MaxReorgDepth: 3}).Sync(ctx)
+	assert.ErrorContains(t, err, "no common ancestor within 3 blocks of block 11")
+}
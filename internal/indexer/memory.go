@@ -0,0 +1,118 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// MemoryStore 基于内存的存储后端，进程退出后数据丢失，主要用于测试
+type MemoryStore struct {
+	mu         sync.RWMutex
+	blocks     map[uint64]*BlockData
+	txs        map[string]txLocation
+	checkpoint *uint64
+}
+
+// NewMemoryStore 创建空的内存存储
+//
+// Returns:
+//   - *MemoryStore: 内存存储实例
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		blocks: make(map[uint64]*BlockData),
+		txs:    make(map[string]txLocation),
+	}
+}
+
+// PutBlock 写入一个区块及其交易和收据，并将检查点更新为该区块号
+func (s *MemoryStore) PutBlock(ctx context.Context, data *BlockData) error {
+	if data == nil || data.Block == nil || data.Block.Number == nil {
+		return fmt.Errorf("block data must include a block number")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	number := data.Number()
+	if old, ok := s.blocks[number]; ok {
+		s.removeTxs(old)
+	}
+	s.blocks[number] = data
+	for i, tx := range data.Block.Transactions {
+		s.txs[normalizeHash(tx.Hash.String())] = txLocation{number: number, index: i}
+	}
+	s.checkpoint = &number
+	return nil
+}
+
+// removeTxs 删除被覆盖区块的交易索引，调用方需持有写锁
+func (s *MemoryStore) removeTxs(data *BlockData) {
+	for _, tx := range data.Block.Transactions {
+		hash := normalizeHash(tx.Hash.String())
+		if loc, ok := s.txs[hash]; ok && loc.number == data.Number() {
+			delete(s.txs, hash)
+		}
+	}
+}
+
+// Rewind 删除区块号大于 number 的所有区块，并将检查点回退到 number，number 不能大于检查点
+func (s *MemoryStore) Rewind(ctx context.Context, number uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := checkRewind(s.checkpoint, number); err != nil {
+		return err
+	}
+
+	for n, data := range s.blocks {
+		if n > number {
+			s.removeTxs(data)
+			delete(s.blocks, n)
+		}
+	}
+	s.checkpoint = &number
+	return nil
+}
+
+// Block 按区块号读取区块
+func (s *MemoryStore) Block(ctx context.Context, number uint64) (*BlockData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.blocks[number]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+// Transaction 按交易哈希读取交易及其收据
+func (s *MemoryStore) Transaction(ctx context.Context, hash string) (*eth.Transaction, *eth.TransactionReceipt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	loc, ok := s.txs[normalizeHash(hash)]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	return lookupTx(s.blocks[loc.number], loc.index)
+}
+
+// Checkpoint 返回最后写入的区块号
+func (s *MemoryStore) Checkpoint(ctx context.Context) (uint64, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.checkpoint == nil {
+		return 0, false, nil
+	}
+	return *s.checkpoint, true, nil
+}
+
+// Close 内存存储无需释放资源
+func (s *MemoryStore) Close() error {
+	return nil
+}
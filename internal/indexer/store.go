@@ -0,0 +1,86 @@
+// Package indexer 将链上数据持久化到本地存储，提供以下功能：
+//   - 按区块号顺序抓取区块（含完整交易）和交易收据
+//   - 可插拔的存储后端：嵌入式文件存储和用于测试的内存存储
+//   - 记录索引进度（检查点），重启后从检查点继续
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+)
+
+// ErrNotFound 存储中不存在请求的区块或交易
+var ErrNotFound = errors.New("not found")
+
+// BlockData 一个已索引区块的全部数据
+type BlockData struct {
+	Block    *eth.Block                `json:"block"`    // 区块，Transactions 包含完整的交易对象
+	Receipts []*eth.TransactionReceipt `json:"receipts"` // 交易收据，顺序与 Block.Transactions 一致
+}
+
+// Number 返回区块号
+func (d *BlockData) Number() uint64 {
+	return d.Block.Number.UInt64()
+}
+
+// Store 索引数据的存储后端，实现必须是并发安全的
+type Store interface {
+	// PutBlock 写入一个区块及其交易和收据，并将检查点更新为该区块号；
+	// 写入已存在的区块号时覆盖原有数据
+	PutBlock(ctx context.Context, data *BlockData) error
+
+	// Block 按区块号读取区块，不存在时返回 ErrNotFound
+	Block(ctx context.Context, number uint64) (*BlockData, error)
+
+	// Transaction 按交易哈希读取交易及其收据，不存在时返回 ErrNotFound
+	Transaction(ctx context.Context, hash string) (*eth.Transaction, *eth.TransactionReceipt, error)
+
+	// Rewind 删除区块号大于 number 的所有区块及其交易，并将检查点回退到 number，用于处理链重组；
+	// number 大于检查点或尚未写入任何区块时返回错误
+	Rewind(ctx context.Context, number uint64) error
+
+	// Checkpoint 返回最后写入的区块号，ok 为 false 表示尚未写入任何区块
+	Checkpoint(ctx context.Context) (number uint64, ok bool, err error)
+
+	// Close 关闭存储并释放资源
+	Close() error
+}
+
+// txLocation 交易在已索引区块中的位置
+type txLocation struct {
+	number uint64 // 区块号
+	index  int    // 在区块交易列表中的下标
+}
+
+// normalizeHash 统一哈希的大小写，用作索引键
+func normalizeHash(hash string) string {
+	return strings.ToLower(hash)
+}
+
+// checkRewind 校验回退的目标区块号不超过检查点，避免检查点越过未索引的区块
+func checkRewind(checkpoint *uint64, number uint64) error {
+	if checkpoint == nil {
+		return fmt.Errorf("cannot rewind to block %d: no blocks have been indexed", number)
+	}
+	if number > *checkpoint {
+		return fmt.Errorf("cannot rewind to block %d beyond checkpoint %d", number, *checkpoint)
+	}
+	return nil
+}
+
+// lookupTx 从区块数据中取出指定下标的交易及其收据
+func lookupTx(data *BlockData, index int) (*eth.Transaction, *eth.TransactionReceipt, error) {
+	if index >= len(data.Block.Transactions) {
+		return nil, nil, ErrNotFound
+	}
+	tx := data.Block.Transactions[index].Transaction
+	var receipt *eth.TransactionReceipt
+	if index < len(data.Receipts) {
+		receipt = data.Receipts[index]
+	}
+	return &tx, receipt, nil
+}
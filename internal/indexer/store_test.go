@@ -0,0 +1,181 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/justinwongcn/go-ethlibs/eth"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTxHash 返回区块中第 index 笔交易的哈希
+func testTxHash(number uint64, index int) string {
+	return fmt.Sprintf("0x%062x%02x", number, index)
+}
+
+// testBlockHash 返回分叉 fork 上区块 number 的哈希，fork 为0表示原始链
+func testBlockHash(number uint64, fork int) string {
+	return fmt.Sprintf("0x%032x%032x", fork, number)
+}
+
+// testBlock 构造原始链上包含 txs 笔完整交易的区块
+func testBlock(t *testing.T, number uint64, txs int) *eth.Block {
+	return testForkBlock(t, number, txs, 0, 0)
+}
+
+// testForkBlock 构造分叉 fork 上的区块，其父区块位于分叉 parentFork 上
+func testForkBlock(t *testing.T, number uint64, txs int, fork, parentFork int) *eth.Block {
+	transactions := make([]string, txs)
+	for i := range transactions {
+		transactions[i] = fmt.Sprintf(`{"hash":%q,"blockNumber":"0x%x","transactionIndex":"0x%x","from":"0x0000000000000000000000000000000000000001","to":"0x0000000000000000000000000000000000000002","nonce":"0x%x","gas":"0x5208","gasPrice":"0x1","value":"0x1","input":"0x","v":"0x1b","r":"0x1","s":"0x1"}`,
+			testTxHash(number, i), number, i, i)
+	}
+	raw := fmt.Sprintf(`{"number":"0x%x","hash":%q,"parentHash":%q,"sha3Uncles":"0x%064x","logsBloom":"0x%0512x",`+
+		`"transactionsRoot":"0x%064x","stateRoot":"0x%064x","receiptsRoot":"0x%064x","miner":"0x%040x","difficulty":"0x0",`+
+		`"totalDifficulty":"0x0","extraData":"0x","size":"0x0","gasLimit":"0x1c9c380","gasUsed":"0x0","timestamp":"0x0","transactions":[%s],"uncles":[]}`,
+		number, testBlockHash(number, fork), testBlockHash(number-1, parentFork), 0, 0, 0, 0, 0, 0, strings.Join(transactions, ","))
+
+	var block eth.Block
+	if err := json.Unmarshal([]byte(raw), &block); err != nil {
+		t.Fatal(err)
+	}
+	return &block
+}
+
+// testReceipt 构造交易收据
+func testReceipt(t *testing.T, hash string, number uint64) *eth.TransactionReceipt {
+	raw := fmt.Sprintf(`{"transactionHash":%q,"transactionIndex":"0x0","blockHash":"0x%064x","blockNumber":"0x%x",`+
+		`"from":"0x0000000000000000000000000000000000000001","to":"0x0000000000000000000000000000000000000002",`+
+		`"cumulativeGasUsed":"0x5208","gasUsed":"0x5208","contractAddress":null,"logs":[],"logsBloom":"0x%0512x","status":"0x1"}`,
+		hash, number+1, number, 0)
+
+	var receipt eth.TransactionReceipt
+	if err := json.Unmarshal([]byte(raw), &receipt); err != nil {
+		t.Fatal(err)
+	}
+	return &receipt
+}
+
+// testBlockData 构造包含收据的区块数据
+func testBlockData(t *testing.T, number uint64, txs int) *BlockData {
+	block := testBlock(t, number, txs)
+	receipts := make([]*eth.TransactionReceipt, txs)
+	for i := range receipts {
+		receipts[i] = testReceipt(t, testTxHash(number, i), number)
+	}
+	return &BlockData{Block: block, Receipts: receipts}
+}
+
+// testStore 对任意存储后端执行相同的行为测试
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+
+	_, ok, err := s.Checkpoint(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok, "空存储没有检查点")
+	assert.ErrorContains(t, s.Rewind(ctx, 5), "no blocks have been indexed")
+
+	assert.NoError(t, s.PutBlock(ctx, testBlockData(t, 10, 2)))
+	assert.NoError(t, s.PutBlock(ctx, testBlockData(t, 11, 1)))
+	assert.Error(t, s.PutBlock(ctx, &BlockData{}))
+
+	checkpoint, ok, err := s.Checkpoint(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(11), checkpoint)
+
+	data, err := s.Block(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, data.Block.Transactions, 2)
+	assert.Len(t, data.Receipts, 2)
+
+	_, err = s.Block(ctx, 12)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	tx, receipt, err := s.Transaction(ctx, testTxHash(10, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, testTxHash(10, 1), tx.Hash.String())
+	assert.Equal(t, testTxHash(10, 1), receipt.TransactionHash.String())
+
+	// 覆盖区块后旧交易不再可查
+	assert.NoError(t, s.PutBlock(ctx, testBlockData(t, 10, 1)))
+	_, _, err = s.Transaction(ctx, testTxHash(10, 1))
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, _, err = s.Transaction(ctx, testTxHash(10, 0))
+	assert.NoError(t, err)
+
+	// 回退后之后的区块和交易被删除
+	assert.NoError(t, s.Rewind(ctx, 10))
+	_, err = s.Block(ctx, 11)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, _, err = s.Transaction(ctx, testTxHash(11, 0))
+	assert.ErrorIs(t, err, ErrNotFound)
+	checkpoint, _, err = s.Checkpoint(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), checkpoint)
+
+	// 回退的目标不能超过检查点，检查点保持不变
+	assert.ErrorContains(t, s.Rewind(ctx, 20), "beyond checkpoint 10")
+	checkpoint, _, err = s.Checkpoint(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), checkpoint)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	s, err := OpenFileStore(t.TempDir())
+	assert.NoError(t, err)
+	defer s.Close()
+	testStore(t, s)
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s, err := OpenFileStore(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, s.PutBlock(ctx, testBlockData(t, 1, 1)))
+	assert.NoError(t, s.PutBlock(ctx, testBlockData(t, 2, 1)))
+	assert.NoError(t, s.Close())
+
+	// 模拟写入第3个区块时崩溃，留下不完整的记录
+	f, err := os.OpenFile(filepath.Join(dir, fileStoreName), os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"block":{"number":"0x3"`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	s, err = OpenFileStore(dir)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	checkpoint, ok, err := s.Checkpoint(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), checkpoint, "不完整的记录被截断，检查点回到最后一条完整记录")
+
+	_, _, err = s.Transaction(ctx, testTxHash(1, 0))
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.PutBlock(ctx, testBlockData(t, 3, 0)))
+	data, err := s.Block(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), data.Number())
+}
+
+func TestFileStoreCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, fileStoreName), []byte("garbage\n{}\n"), 0o644))
+
+	_, err := OpenFileStore(dir)
+	assert.ErrorContains(t, err, "corrupted store record at offset 0")
+}